@@ -0,0 +1,169 @@
+// Package adminapibench provides benchmark scenarios for the hot paths of the
+// adminapi client, using only its public API. Downstream projects can run
+// them from their own tests to compare client upgrades:
+//
+//	func BenchmarkDecodeQueryResponse(b *testing.B) {
+//		adminapibench.DecodeQueryResponse(b, 100_000)
+//	}
+package adminapibench
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+// DecodeQueryResponse measures fetching and decoding a query response holding
+// the given number of objects; 100k is the size of a full inventory dump.
+func DecodeQueryResponse(b *testing.B, objects int) {
+	b.Helper()
+
+	var sb strings.Builder
+	sb.WriteString(`{"status":"success","result":[`)
+	for i := range objects {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, `{"object_id":%d,"hostname":"web%d.example.com","num_cpu":8,"state":"online","tags":["web","prod"]}`, i+1, i)
+	}
+	sb.WriteString(`]}`)
+	payload := []byte(sb.String())
+
+	client := newClient(b, func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(payload)
+	})
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	for b.Loop() {
+		q := client.NewQuery(adminapi.Filters{"servertype": "vm"})
+		servers, err := q.All(context.Background())
+		if err != nil {
+			b.Fatalf("query failed: %v", err)
+		}
+		if len(servers) != objects {
+			b.Fatalf("expected %d objects, got %d", objects, len(servers))
+		}
+	}
+}
+
+// CommitMultiChanges measures committing a multi-attribute with the given
+// number of values of which half were replaced, which is dominated by
+// diffing the old and new values.
+func CommitMultiChanges(b *testing.B, values int) {
+	b.Helper()
+
+	oldTags := make([]string, values)
+	newTags := make([]string, values)
+	for i := range values {
+		oldTags[i] = "tag-" + strconv.Itoa(i)
+		newTags[i] = "tag-" + strconv.Itoa(i+values/2)
+	}
+	object := fmt.Sprintf(`{"status":"success","result":[{"object_id":1,"hostname":"web1","tags":["%s"]}]}`, strings.Join(oldTags, `","`))
+
+	client := newClient(b, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/commit") {
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(object))
+	})
+	q := client.NewQuery(adminapi.Filters{"hostname": "web1"})
+	obj, err := q.One(context.Background())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; b.Loop(); i++ {
+		tags := newTags
+		if i%2 == 1 {
+			tags = oldTags
+		}
+		if err := obj.Set("tags", tags); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := obj.Commit(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// SignRequests measures authenticating the given number of requests with
+// auth, e.g. one returned by adminapi.NewTokenAuthenticator or
+// adminapi.NewSSHAuthenticator.
+func SignRequests(b *testing.B, auth adminapi.Authenticator, requests int) {
+	b.Helper()
+
+	payload := []byte(`{"filters":{"hostname":{"Regexp":"web.*"}},"restrict":["hostname","object_id"]}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		for range requests {
+			req, err := http.NewRequest(http.MethodGet, "https://serveradmin.example.com/api/dataset/query", nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			req.Header.Set("X-Timestamp", timestamp)
+			if err := auth.Apply(req, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// SequentialQueries measures running small queries one after another, as
+// automation looking up hosts one by one does, which is dominated by
+// connection handling. It sends them with httpClient, or with the client's
+// own transport if nil, and reports the connections opened per query.
+func SequentialQueries(b *testing.B, httpClient *http.Client) {
+	b.Helper()
+
+	var conns atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"web1.example.com"}]}` + "\n"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	b.Cleanup(server.Close)
+
+	client, err := adminapi.NewClient(adminapi.Config{BaseURL: server.URL, Token: "bench-token", HTTPClient: httpClient})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		q := client.NewQuery(adminapi.Filters{"hostname": "web1.example.com"})
+		if _, err := q.One(context.Background()); err != nil {
+			b.Fatalf("query failed: %v", err)
+		}
+	}
+	b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+}
+
+func newClient(b *testing.B, handler http.HandlerFunc) *adminapi.Client {
+	b.Helper()
+	server := httptest.NewServer(handler)
+	b.Cleanup(server.Close)
+
+	client, err := adminapi.NewClient(adminapi.Config{BaseURL: server.URL, Token: "bench-token"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return client
+}
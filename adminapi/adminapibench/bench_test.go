@@ -0,0 +1,49 @@
+package adminapibench
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+func BenchmarkDecodeQueryResponse100k(b *testing.B) {
+	DecodeQueryResponse(b, 100_000)
+}
+
+func BenchmarkCommitMultiChanges10k(b *testing.B) {
+	CommitMultiChanges(b, 10_000)
+}
+
+func BenchmarkSequentialQueries(b *testing.B) {
+	b.Run("client-transport", func(b *testing.B) {
+		SequentialQueries(b, nil)
+	})
+
+	b.Run("no-keep-alive", func(b *testing.B) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DisableKeepAlives = true
+		SequentialQueries(b, &http.Client{Transport: transport})
+	})
+}
+
+func BenchmarkSignRequests10k(b *testing.B) {
+	b.Run("token", func(b *testing.B) {
+		SignRequests(b, adminapi.NewTokenAuthenticator(adminapi.StaticToken("1234567898"), adminapi.TokenAlgorithmAuto), 10_000)
+	})
+
+	b.Run("ssh", func(b *testing.B) {
+		keyBytes, err := os.ReadFile("../testdata/test.key")
+		if err != nil {
+			b.Fatal(err)
+		}
+		signer, err := ssh.ParsePrivateKey(keyBytes)
+		if err != nil {
+			b.Fatal(err)
+		}
+		SignRequests(b, adminapi.NewSSHAuthenticator(signer), 10_000)
+	})
+}
@@ -0,0 +1,150 @@
+package adminapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+// commitRequest accepts both commit protocols: deleted entries are either
+// object_ids or full objects.
+type commitRequest struct {
+	Created []adminapi.Attributes `json:"created"`
+	Changed []adminapi.Attributes `json:"changed"`
+	Deleted []any                 `json:"deleted"`
+}
+
+// commit applies a commit atomically: either all changes are stored or none.
+// Repeated commits with the same Idempotency-Key return the first result.
+func (s *Server) commit(r *http.Request) (any, error) {
+	var req commitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid commit: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.Header.Get("Idempotency-Key")
+	if result, ok := s.commits[key]; ok && key != "" {
+		return result, nil
+	}
+
+	objects := make(map[int]adminapi.Attributes, len(s.objects))
+	for id, obj := range s.objects {
+		objects[id] = maps.Clone(obj)
+	}
+	staged := &Server{objects: objects, nextID: s.nextID}
+
+	if err := staged.apply(req); err != nil {
+		return map[string]any{"status": "error", "message": err.Error()}, nil
+	}
+
+	s.objects = staged.objects
+	s.nextID = staged.nextID
+	s.commitID++
+	result := map[string]any{"status": "success", "commit_id": s.commitID}
+	if key != "" {
+		s.commits[key] = result
+	}
+	return result, nil
+}
+
+func (s *Server) apply(req commitRequest) error {
+	for _, entry := range req.Deleted {
+		id, ok := toInt(entry)
+		if obj, isObject := entry.(map[string]any); isObject {
+			id, ok = toInt(obj["object_id"])
+		}
+		if !ok {
+			return fmt.Errorf("invalid deleted entry %v", entry)
+		}
+		if _, exists := s.objects[id]; !exists {
+			return fmt.Errorf("object %d does not exist", id)
+		}
+		delete(s.objects, id)
+	}
+
+	for _, changes := range req.Changed {
+		if err := s.change(changes); err != nil {
+			return err
+		}
+	}
+
+	for _, attrs := range req.Created {
+		hostname, _ := attrs["hostname"].(string)
+		if hostname == "" {
+			return fmt.Errorf("hostname is required")
+		}
+		if _, ok := s.idByHostname(hostname); ok {
+			return fmt.Errorf("hostname %q is already taken", hostname)
+		}
+		attrs = maps.Clone(attrs)
+		delete(attrs, "object_id")
+		s.add(attrs)
+	}
+
+	return nil
+}
+
+// change applies the change delta built by adminapi.ServerObject.
+func (s *Server) change(changes adminapi.Attributes) error {
+	id, ok := toInt(changes["object_id"])
+	obj, exists := s.objects[id]
+	if !ok || !exists {
+		return fmt.Errorf("object %v does not exist", changes["object_id"])
+	}
+
+	for attr, change := range changes {
+		if attr == "object_id" {
+			continue
+		}
+		delta, ok := change.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid change of %q", attr)
+		}
+
+		switch delta["action"] {
+		case "update":
+			if !jsonEqual(obj[attr], delta["old"]) {
+				return fmt.Errorf("object %d: %q was changed concurrently", id, attr)
+			}
+			if attr == "hostname" {
+				if other, ok := s.idByHostname(fmt.Sprint(delta["new"])); ok && other != id {
+					return fmt.Errorf("hostname %q is already taken", delta["new"])
+				}
+			}
+			obj[attr] = delta["new"]
+		case "multi":
+			values, _ := obj[attr].([]any)
+			for _, v := range asSlice(delta["remove"]) {
+				values = slices.DeleteFunc(values, func(e any) bool { return jsonEqual(e, v) })
+			}
+			for _, v := range asSlice(delta["add"]) {
+				if !slices.ContainsFunc(values, func(e any) bool { return jsonEqual(e, v) }) {
+					values = append(values, v)
+				}
+			}
+			obj[attr] = values
+		default:
+			return fmt.Errorf("unknown action %v for %q", delta["action"], attr)
+		}
+	}
+
+	return nil
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func jsonEqual(a, b any) bool {
+	aj, _ := json.Marshal(a)
+	bj, _ := json.Marshal(b)
+	return string(aj) == string(bj)
+}
@@ -0,0 +1,159 @@
+package adminapitest
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+// matchFilters reports whether obj matches all attribute filters of a query.
+func matchFilters(obj adminapi.Attributes, filters map[string]any) (bool, error) {
+	for attr, filter := range filters {
+		matched, err := match(obj[attr], filter)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// match evaluates a serialized filter against an attribute value. Values of
+// multi attributes match if any of their elements matches.
+func match(value, filter any) (bool, error) {
+	fn, arg, isFunc := filterFunc(filter)
+	if !isFunc {
+		return anyElement(value, func(v any) bool { return jsonEqual(v, filter) }), nil
+	}
+
+	switch fn {
+	case "Any", "All":
+		subfilters, _ := arg.([]any)
+		for _, sub := range subfilters {
+			matched, err := match(value, sub)
+			if err != nil {
+				return false, err
+			}
+			if matched == (fn == "Any") {
+				return matched, nil
+			}
+		}
+		return fn == "All", nil
+	case "Not":
+		matched, err := match(value, arg)
+		return !matched, err
+	case "Empty":
+		return isEmpty(value), nil
+	case "Regexp":
+		re, err := regexp.Compile(fmt.Sprint(arg))
+		if err != nil {
+			return false, fmt.Errorf("invalid regexp %q: %w", arg, err)
+		}
+		return anyElement(value, func(v any) bool { return re.MatchString(fmt.Sprint(v)) }), nil
+	case "StartsWith":
+		return anyElement(value, func(v any) bool { return strings.HasPrefix(fmt.Sprint(v), fmt.Sprint(arg)) }), nil
+	case "Contains":
+		return anyElement(value, func(v any) bool { return strings.Contains(fmt.Sprint(v), fmt.Sprint(arg)) }), nil
+	case "GreaterThan", "GreaterThanOrEquals", "LessThan", "LessThanOrEquals":
+		return anyElement(value, func(v any) bool { return compare(fn, v, arg) }), nil
+	case "ContainedBy", "ContainedOnlyBy", "Overlaps":
+		return anyElement(value, func(v any) bool { return networkMatch(fn, v, arg) }), nil
+	}
+
+	return false, fmt.Errorf("unsupported filter %s", fn)
+}
+
+// filterFunc unpacks a filter function serialized as {"Name": argument}.
+func filterFunc(filter any) (string, any, bool) {
+	m, ok := filter.(map[string]any)
+	if !ok || len(m) != 1 {
+		return "", nil, false
+	}
+	for name, arg := range m {
+		return name, arg, true
+	}
+	return "", nil, false
+}
+
+func anyElement(value any, fn func(any) bool) bool {
+	if values, ok := value.([]any); ok {
+		for _, v := range values {
+			if fn(v) {
+				return true
+			}
+		}
+		return false
+	}
+	return value != nil && fn(value)
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func compare(fn string, value, arg any) bool {
+	var c int
+	a, aErr := strconv.ParseFloat(fmt.Sprint(value), 64)
+	b, bErr := strconv.ParseFloat(fmt.Sprint(arg), 64)
+	if aErr == nil && bErr == nil {
+		switch {
+		case a < b:
+			c = -1
+		case a > b:
+			c = 1
+		}
+	} else {
+		c = strings.Compare(fmt.Sprint(value), fmt.Sprint(arg))
+	}
+
+	switch fn {
+	case "GreaterThan":
+		return c > 0
+	case "GreaterThanOrEquals":
+		return c >= 0
+	case "LessThan":
+		return c < 0
+	default:
+		return c <= 0
+	}
+}
+
+func networkMatch(fn string, value, arg any) bool {
+	v, err := parsePrefix(fmt.Sprint(value))
+	if err != nil {
+		return false
+	}
+	network, err := parsePrefix(fmt.Sprint(arg))
+	if err != nil {
+		return false
+	}
+
+	if fn == "Overlaps" {
+		return v.Overlaps(network)
+	}
+	return v.Bits() >= network.Bits() && network.Contains(v.Addr())
+}
+
+// parsePrefix accepts networks as well as single addresses.
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		return p.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
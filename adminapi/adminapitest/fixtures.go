@@ -0,0 +1,42 @@
+package adminapitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+// Fixtures is the JSON layout accepted by LoadFixtures:
+//
+//	{
+//	  "attributes": [{"attribute_id": "state", "type": "string"}],
+//	  "servertypes": {"vm": {"hostname": "", "state": "online"}},
+//	  "objects": [{"hostname": "web1", "servertype": "vm", "state": "online"}]
+//	}
+type Fixtures struct {
+	Attributes  []adminapi.Attribute           `json:"attributes"`
+	Servertypes map[string]adminapi.Attributes `json:"servertypes"`
+	Objects     []adminapi.Attributes          `json:"objects"`
+}
+
+// LoadFixtures seeds the server with the attributes, servertypes and objects
+// read from r.
+func (s *Server) LoadFixtures(r io.Reader) error {
+	var fixtures Fixtures
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return fmt.Errorf("invalid fixtures: %w", err)
+	}
+
+	for _, attr := range fixtures.Attributes {
+		s.AddAttribute(attr)
+	}
+	for name, defaults := range fixtures.Servertypes {
+		s.AddServertype(name, defaults)
+	}
+	for _, obj := range fixtures.Objects {
+		s.Add(obj)
+	}
+	return nil
+}
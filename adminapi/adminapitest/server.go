@@ -0,0 +1,400 @@
+// Package adminapitest provides an in-memory Serveradmin backend serving the
+// dataset API over HTTP, for integration tests of tools built on adminapi.
+//
+//	backend := adminapitest.NewServer()
+//	backend.AddServertype("vm", adminapi.Attributes{"hostname": "", "state": "online"})
+//	backend.Add(adminapi.Attributes{"hostname": "web1", "servertype": "vm", "state": "online"})
+//
+//	srv := httptest.NewServer(backend)
+//	client, _ := adminapi.NewClient(adminapi.Config{BaseURL: srv.URL, Token: "any"})
+//
+// Requests are not authenticated. Queries support the filter functions of
+// the adminapi package, ordering, limit, offset and related attributes;
+// commits check for concurrent modifications and duplicate hostnames like the
+// real server, and are applied once per Idempotency-Key.
+package adminapitest
+
+import (
+	"cmp"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+// CallFunc implements an API function served on /call.
+type CallFunc func(kwargs map[string]any) (any, error)
+
+// Server is an in-memory Serveradmin backend. It implements http.Handler and
+// is safe for concurrent use.
+type Server struct {
+	mu          sync.Mutex
+	objects     map[int]adminapi.Attributes
+	nextID      int
+	commitID    int
+	commits     map[string]any // responses by Idempotency-Key
+	servertypes map[string]adminapi.Attributes
+	attributes  []adminapi.Attribute
+	functions   map[string]CallFunc
+}
+
+// NewServer returns an empty backend.
+func NewServer() *Server {
+	return &Server{
+		objects:     map[int]adminapi.Attributes{},
+		nextID:      1,
+		servertypes: map[string]adminapi.Attributes{},
+		commits:     map[string]any{},
+		functions:   map[string]CallFunc{},
+	}
+}
+
+// AddServertype registers a servertype with the default attributes returned by
+// new_object. Only attributes present in defaults can be set on new objects.
+func (s *Server) AddServertype(name string, defaults adminapi.Attributes) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defaults = maps.Clone(defaults)
+	defaults["servertype"] = name
+	if _, ok := defaults["hostname"]; !ok {
+		defaults["hostname"] = ""
+	}
+	s.servertypes[name] = defaults
+}
+
+// AddAttribute registers an attribute definition returned by the attributes
+// endpoint.
+func (s *Server) AddAttribute(attr adminapi.Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes = append(s.attributes, attr)
+}
+
+// AddFunction registers an API function served on /call.
+func (s *Server) AddFunction(group, name string, fn CallFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.functions[group+"."+name] = fn
+}
+
+// Add stores an object and returns its object_id. A missing object_id is
+// assigned automatically.
+func (s *Server) Add(attrs adminapi.Attributes) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(attrs)
+}
+
+func (s *Server) add(attrs adminapi.Attributes) int {
+	attrs = normalize(attrs)
+	id, ok := toInt(attrs["object_id"])
+	if !ok || id <= 0 {
+		id = s.nextID
+	}
+	s.nextID = max(s.nextID, id+1)
+	attrs["object_id"] = id
+	s.objects[id] = attrs
+	return id
+}
+
+// Objects returns copies of all stored objects ordered by object_id.
+func (s *Server) Objects() []adminapi.Attributes {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	objects := make([]adminapi.Attributes, 0, len(s.objects))
+	for _, id := range slices.Sorted(maps.Keys(s.objects)) {
+		objects = append(objects, maps.Clone(s.objects[id]))
+	}
+	return objects
+}
+
+// Get returns a copy of the object with the given hostname, or nil.
+func (s *Server) Get(hostname string) adminapi.Attributes {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.idByHostname(hostname); ok {
+		return maps.Clone(s.objects[id])
+	}
+	return nil
+}
+
+func (s *Server) idByHostname(hostname string) (int, bool) {
+	for id, obj := range s.objects {
+		if obj["hostname"] == hostname {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// ServeHTTP serves the dataset API. Gzip-encoded request bodies are accepted
+// and query results carry an ETag for conditional requests.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		body, err := gzip.NewReader(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		defer body.Close()
+		r.Body = body
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch r.URL.Path {
+	case "/api/dataset/query":
+		result, err = s.query(r)
+	case "/api/dataset/new_object":
+		result, err = s.newObject(r)
+	case "/api/dataset/commit":
+		result, err = s.commit(r)
+	case "/api/dataset/attributes":
+		result, err = s.listAttributes()
+	case "/call":
+		result, err = s.call(r)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown endpoint %s", r.URL.Path))
+		return
+	}
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if r.URL.Path == "/api/dataset/query" {
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"error": map[string]any{"message": err.Error()}})
+}
+
+func (s *Server) query(r *http.Request) (any, error) {
+	var req struct {
+		Filters  map[string]any `json:"filters"`
+		Restrict []any          `json:"restrict"`
+		OrderBy  any            `json:"order_by"`
+		Limit    int            `json:"limit"`
+		Offset   int            `json:"offset"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matches := []adminapi.Attributes{}
+	for _, id := range slices.Sorted(maps.Keys(s.objects)) {
+		obj := s.objects[id]
+		matched, err := matchFilters(obj, req.Filters)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, obj)
+		}
+	}
+	if keys := sortKeys(req.OrderBy); len(keys) > 0 {
+		slices.SortStableFunc(matches, func(a, b adminapi.Attributes) int {
+			for _, key := range keys {
+				attribute, desc := strings.CutPrefix(key, "-")
+				order := compareValues(a[attribute], b[attribute])
+				if desc {
+					order = -order
+				}
+				if order != 0 {
+					return order
+				}
+			}
+			return 0
+		})
+	}
+	matches = matches[min(max(req.Offset, 0), len(matches)):]
+	if req.Limit > 0 {
+		matches = matches[:min(req.Limit, len(matches))]
+	}
+
+	result := make([]adminapi.Attributes, len(matches))
+	for i, obj := range matches {
+		result[i] = s.restrict(obj, req.Restrict)
+	}
+
+	return map[string]any{"status": "success", "result": result}, nil
+}
+
+// sortKeys returns the attributes of an order_by value, which is a single
+// attribute or a list of them, descending ones prefixed by "-".
+func sortKeys(orderBy any) []string {
+	switch orderBy := orderBy.(type) {
+	case string:
+		if orderBy != "" {
+			return []string{orderBy}
+		}
+	case []any:
+		keys := make([]string, 0, len(orderBy))
+		for _, key := range orderBy {
+			keys = append(keys, fmt.Sprint(key))
+		}
+		return keys
+	}
+	return nil
+}
+
+// compareValues orders numbers numerically and everything else by its string
+// form.
+func compareValues(a, b any) int {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	}
+	return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// restrict returns a copy of obj with only the given attributes and
+// object_id. Nested restrictions like {"hypervisor": ["hostname", "os"]}
+// replace the hostnames of a relation attribute with the restricted related
+// objects.
+func (s *Server) restrict(obj adminapi.Attributes, attributes []any) adminapi.Attributes {
+	if attributes == nil {
+		return maps.Clone(obj)
+	}
+	restricted := adminapi.Attributes{"object_id": obj["object_id"]}
+	for _, attr := range attributes {
+		nested, ok := attr.(map[string]any)
+		if !ok {
+			restricted[fmt.Sprint(attr)] = obj[fmt.Sprint(attr)]
+			continue
+		}
+		for relation, related := range nested {
+			relatedAttributes, _ := related.([]any)
+			restricted[relation] = s.restrictRelated(obj[relation], relatedAttributes)
+		}
+	}
+	return restricted
+}
+
+// restrictRelated resolves the hostname or hostnames of a relation attribute
+// to the restricted related objects.
+func (s *Server) restrictRelated(value any, attributes []any) any {
+	resolve := func(hostname any) any {
+		if id, ok := s.idByHostname(fmt.Sprint(hostname)); ok {
+			return s.restrict(s.objects[id], attributes)
+		}
+		return hostname
+	}
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		objects := make([]any, len(value))
+		for i, hostname := range value {
+			objects[i] = resolve(hostname)
+		}
+		return objects
+	default:
+		return resolve(value)
+	}
+}
+
+func (s *Server) newObject(r *http.Request) (any, error) {
+	servertype := r.URL.Query().Get("servertype")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	defaults, ok := s.servertypes[servertype]
+	if !ok {
+		return nil, fmt.Errorf("unknown servertype %q", servertype)
+	}
+	result := maps.Clone(defaults)
+	result["object_id"] = nil
+	return map[string]any{"status": "success", "result": result}, nil
+}
+
+func (s *Server) listAttributes() (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]any{"status": "success", "result": slices.Clone(s.attributes)}, nil
+}
+
+func (s *Server) call(r *http.Request) (any, error) {
+	var req struct {
+		Group  string         `json:"group"`
+		Name   string         `json:"name"`
+		Kwargs map[string]any `json:"kwargs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid call: %w", err)
+	}
+
+	s.mu.Lock()
+	fn, ok := s.functions[req.Group+"."+req.Name]
+	s.mu.Unlock()
+	if !ok {
+		return map[string]any{"status": "error", "message": fmt.Sprintf("unknown function %s.%s", req.Group, req.Name)}, nil
+	}
+
+	retval, err := fn(req.Kwargs)
+	if err != nil {
+		return map[string]any{"status": "error", "message": err.Error()}, nil
+	}
+	return map[string]any{"status": "success", "retval": retval}, nil
+}
+
+// normalize returns a copy of attrs with JSON-compatible values, so stored and
+// committed values compare equal.
+func normalize(attrs adminapi.Attributes) adminapi.Attributes {
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return maps.Clone(attrs)
+	}
+	normalized := adminapi.Attributes{}
+	_ = json.Unmarshal(data, &normalized)
+	return normalized
+}
+
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	}
+	return 0, false
+}
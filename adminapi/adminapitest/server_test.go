@@ -0,0 +1,254 @@
+package adminapitest
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+func newTestClient(t *testing.T, backend *Server) *adminapi.Client {
+	t.Helper()
+	srv := httptest.NewServer(backend)
+	t.Cleanup(srv.Close)
+
+	client, err := adminapi.NewClient(adminapi.Config{BaseURL: srv.URL, Token: "test-token"})
+	require.NoError(t, err)
+	return client
+}
+
+func seededServer(t *testing.T) *Server {
+	t.Helper()
+	backend := NewServer()
+	require.NoError(t, backend.LoadFixtures(strings.NewReader(`{
+		"attributes": [{"attribute_id": "state", "type": "string"}],
+		"servertypes": {"vm": {"state": "", "memory": 0, "tags": [], "intern_ip": null}},
+		"objects": [
+			{"hostname": "web1", "servertype": "vm", "state": "online", "memory": 4096, "tags": ["web"], "intern_ip": "10.0.0.1"},
+			{"hostname": "web2", "servertype": "vm", "state": "maintenance", "memory": 8192, "tags": ["web", "canary"], "intern_ip": "10.0.1.1"},
+			{"hostname": "db1", "servertype": "vm", "state": "online", "memory": 16384, "tags": [], "intern_ip": "10.1.0.1"}
+		]
+	}`)))
+	return backend
+}
+
+func TestQuery(t *testing.T) {
+	client := newTestClient(t, seededServer(t))
+
+	tests := []struct {
+		name    string
+		filters adminapi.Filters
+		want    []string
+	}{
+		{"exact", adminapi.Filters{"state": "online"}, []string{"web1", "db1"}},
+		{"regexp", adminapi.Filters{"hostname": adminapi.Regexp("^web")}, []string{"web1", "web2"}},
+		{"not", adminapi.Filters{"state": adminapi.Not("online")}, []string{"web2"}},
+		{"any", adminapi.Filters{"hostname": adminapi.Any("web1", "db1")}, []string{"web1", "db1"}},
+		{"multi", adminapi.Filters{"tags": "canary"}, []string{"web2"}},
+		{"empty", adminapi.Filters{"tags": adminapi.Empty()}, []string{"db1"}},
+		{"greater than", adminapi.Filters{"memory": adminapi.GreaterThan(4096)}, []string{"web2", "db1"}},
+		{"contained by", adminapi.Filters{"intern_ip": adminapi.ContainedBy("10.0.0.0/16")}, []string{"web1", "web2"}},
+		{"contained by prefix", adminapi.Filters{"intern_ip": adminapi.ContainedBy(netip.MustParsePrefix("10.0.0.0/16"))}, []string{"web1", "web2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := client.NewQuery(tt.filters)
+			objects, err := q.All(t.Context())
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, objects.Hostnames())
+		})
+	}
+}
+
+func TestQueryRestrictAndOrder(t *testing.T) {
+	client := newTestClient(t, seededServer(t))
+
+	q := client.NewQuery(adminapi.Filters{})
+	q.SetAttributes("hostname")
+	q.OrderBy("hostname")
+	objects, err := q.All(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"db1", "web1", "web2"}, objects.Hostnames())
+	assert.Nil(t, objects[0].Get("state"))
+	assert.NotZero(t, objects[0].ObjectID())
+}
+
+func TestQueryOrderByMultipleKeys(t *testing.T) {
+	backend := NewServer()
+	backend.Add(adminapi.Attributes{"hostname": "a", "num_cpu": 4})
+	backend.Add(adminapi.Attributes{"hostname": "b", "num_cpu": 16})
+	backend.Add(adminapi.Attributes{"hostname": "c", "num_cpu": 4})
+	backend.Add(adminapi.Attributes{"hostname": "d", "num_cpu": 8})
+	client := newTestClient(t, backend)
+
+	q := client.NewQuery(adminapi.Filters{})
+	q.OrderBy("num_cpu", adminapi.Desc)
+	q.ThenBy("hostname", adminapi.Desc)
+	objects, err := q.All(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "d", "c", "a"}, objects.Hostnames())
+}
+
+func TestQueryLimitAndOffset(t *testing.T) {
+	client := newTestClient(t, seededServer(t))
+
+	page := func(limit, offset int) []string {
+		q := client.NewQuery(adminapi.Filters{})
+		q.OrderBy("hostname")
+		q.Limit(limit)
+		q.Offset(offset)
+		objects, err := q.All(t.Context())
+		require.NoError(t, err)
+		count, err := q.Count(t.Context())
+		require.NoError(t, err)
+		assert.Len(t, objects, count, "Count counts the page")
+		return objects.Hostnames()
+	}
+
+	assert.Equal(t, []string{"db1", "web1"}, page(2, 0))
+	assert.Equal(t, []string{"web2"}, page(2, 2))
+	assert.Empty(t, page(2, 4))
+	assert.Equal(t, []string{"web1", "web2"}, page(0, 1))
+}
+
+func TestQueryRelatedAttributes(t *testing.T) {
+	backend := NewServer()
+	backend.Add(adminapi.Attributes{"hostname": "hv1", "servertype": "hypervisor", "os": "trixie"})
+	backend.Add(adminapi.Attributes{"hostname": "lb1", "servertype": "loadbalancer", "os": "bookworm"})
+	backend.Add(adminapi.Attributes{"hostname": "vm1", "servertype": "vm", "hypervisor": "hv1", "loadbalancers": []any{"lb1"}})
+	client := newTestClient(t, backend)
+
+	q := client.NewQuery(adminapi.Filters{"servertype": "vm"})
+	q.AddAttributes("hypervisor", "loadbalancers")
+	q.AddRelatedAttributes("hypervisor", "os")
+	q.AddRelatedAttributes("loadbalancers", "os")
+	vm, err := q.One(t.Context())
+	require.NoError(t, err)
+
+	assert.Equal(t, "hv1", vm.GetString("hypervisor"), "the relation keeps holding the hostname")
+	hv := vm.GetRelated("hypervisor")
+	require.NotNil(t, hv)
+	assert.Equal(t, "hv1", hv.GetString("hostname"))
+	assert.Equal(t, "trixie", hv.GetString("os"))
+	assert.NotZero(t, hv.ObjectID())
+
+	assert.Equal(t, adminapi.MultiAttr{"lb1"}, vm.GetMulti("loadbalancers"))
+	lbs := vm.GetRelatedMulti("loadbalancers")
+	require.Len(t, lbs, 1)
+	assert.Equal(t, "bookworm", lbs[0].GetString("os"))
+	assert.Equal(t, adminapi.StateConsistent, vm.CommitState())
+}
+
+func TestCommit(t *testing.T) {
+	backend := seededServer(t)
+	client := newTestClient(t, backend)
+
+	q := client.NewQuery(adminapi.Filters{"hostname": "web2"})
+	q.SetAttributes("state", "tags")
+	obj, err := q.One(t.Context())
+	require.NoError(t, err)
+
+	require.NoError(t, obj.Set("state", "online"))
+	tags := obj.GetMulti("tags")
+	tags.Delete("canary")
+	require.NoError(t, obj.Set("tags", tags))
+	_, err = obj.Commit(t.Context())
+	require.NoError(t, err)
+
+	stored := backend.Get("web2")
+	assert.Equal(t, "online", stored["state"])
+	assert.Equal(t, []any{"web"}, stored["tags"])
+}
+
+func TestCommitConflict(t *testing.T) {
+	backend := seededServer(t)
+	client := newTestClient(t, backend)
+
+	q := client.NewQuery(adminapi.Filters{"hostname": "web1"})
+	q.SetAttributes("state")
+	obj, err := q.One(t.Context())
+	require.NoError(t, err)
+
+	id := backend.Get("web1")["object_id"]
+	backend.Add(adminapi.Attributes{"object_id": id, "hostname": "web1", "servertype": "vm", "state": "retired"})
+
+	require.NoError(t, obj.Set("state", "maintenance"))
+	_, err = obj.Commit(t.Context())
+	require.Error(t, err)
+	assert.Equal(t, "retired", backend.Get("web1")["state"])
+}
+
+func TestNewObjectAndDelete(t *testing.T) {
+	backend := seededServer(t)
+	client := newTestClient(t, backend)
+
+	obj, err := client.NewObject(t.Context(), "vm", adminapi.Attributes{"hostname": "web3", "state": "online"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, obj.ObjectID())
+	assert.Equal(t, "vm", backend.Get("web3")["servertype"])
+
+	_, err = client.NewObject(t.Context(), "vm", adminapi.Attributes{"hostname": "web3"})
+	require.ErrorContains(t, err, "already taken")
+
+	_, err = client.NewObject(t.Context(), "unknown", adminapi.Attributes{"hostname": "x"})
+	require.Error(t, err)
+
+	obj.Delete()
+	_, err = obj.Commit(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, backend.Get("web3"))
+	assert.Len(t, backend.Objects(), 3)
+}
+
+func TestAttributesAndCall(t *testing.T) {
+	backend := seededServer(t)
+	backend.AddFunction("ip", "free", func(kwargs map[string]any) (any, error) {
+		return "10.0.0.2", nil
+	})
+	client := newTestClient(t, backend)
+
+	attributes, err := client.FetchAttributes(t.Context())
+	require.NoError(t, err)
+	require.Len(t, attributes, 1)
+	assert.Equal(t, "state", attributes[0].AttributeID)
+
+	result, err := client.CallAPI(t.Context(), "ip", "free", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", result)
+
+	_, err = client.CallAPI(t.Context(), "ip", "missing", nil)
+	require.Error(t, err)
+}
+
+func TestCommitIdempotencyKey(t *testing.T) {
+	backend := seededServer(t)
+	client := newTestClient(t, backend)
+	ctx := adminapi.WithIdempotencyKey(t.Context(), "create-web3")
+
+	_, err := client.NewObject(ctx, "vm", adminapi.Attributes{"hostname": "web3"})
+	require.NoError(t, err)
+	_, err = client.NewObject(ctx, "vm", adminapi.Attributes{"hostname": "web3"})
+	require.NoError(t, err, "the repeated commit is answered with the first result")
+	assert.Len(t, backend.Objects(), 4)
+}
+
+func TestConditionalQueries(t *testing.T) {
+	srv := httptest.NewServer(seededServer(t))
+	t.Cleanup(srv.Close)
+	client, err := adminapi.NewClient(adminapi.Config{BaseURL: srv.URL, Token: "test-token", ConditionalQueries: true})
+	require.NoError(t, err)
+
+	for range 2 {
+		q := client.NewQuery(adminapi.Filters{"state": "online"})
+		objects, err := q.All(t.Context())
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"web1", "db1"}, objects.Hostnames())
+	}
+}
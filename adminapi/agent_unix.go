@@ -0,0 +1,21 @@
+//go:build !windows
+
+package adminapi
+
+import (
+	"context"
+	"io"
+	"net"
+)
+
+// dialAgent connects to the SSH agent listening on the unix socket at path.
+func dialAgent(path string) (io.ReadWriteCloser, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(context.Background(), "unix", path)
+}
+
+// defaultAgentPath returns the well-known SSH agent address of the platform,
+// used when SSH_AUTH_SOCK is not set. Unix systems have none.
+func defaultAgentPath() string {
+	return ""
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package adminapi
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// openSSHAgentPipe is the named pipe served by the Windows OpenSSH agent service.
+const openSSHAgentPipe = `\\.\pipe\openssh-ssh-agent`
+
+// dialAgent connects to the SSH agent at path. Named pipes (\\.\pipe\...) are
+// opened as files, which is all the agent protocol needs; anything else is
+// treated as an AF_UNIX socket, as used by e.g. Git for Windows or WSL bridges.
+func dialAgent(path string) (io.ReadWriteCloser, error) {
+	if strings.HasPrefix(path, `\\.\pipe\`) {
+		return os.OpenFile(path, os.O_RDWR, 0)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(context.Background(), "unix", path)
+}
+
+// defaultAgentPath returns the well-known SSH agent address of the platform,
+// used when SSH_AUTH_SOCK is not set.
+func defaultAgentPath() string {
+	return openSSHAgentPipe
+}
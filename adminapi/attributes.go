@@ -55,14 +55,16 @@ type attributesResponse struct {
 // server using this client. The result includes the special attributes (e.g.
 // hostname, servertype) that are not stored in the attribute table but remain
 // queryable, and is suitable for auto-completion or attribute selection.
-func (c *Client) FetchAttributes(ctx context.Context) ([]Attribute, error) {
+func (c *Client) FetchAttributes(ctx context.Context) (_ []Attribute, err error) {
+	defer recoverPanic(c, &err)
+
 	// The endpoint takes no input; send an empty JSON object so the request
 	// body is valid for the API's signature verification.
 	resp, err := c.sendRequest(ctx, apiEndpointAttributes, struct{}{})
 	if err != nil {
 		return nil, fmt.Errorf("fetching %s: %w", apiEndpointAttributes, err)
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
 	var result attributesResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -0,0 +1,87 @@
+package adminapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Authenticator adds authentication to an outgoing request. Apply is called
+// for every request after the standard headers, including X-Timestamp, are
+// set; body is the exact request body before any Content-Encoding is applied
+// (see Config.CompressRequests). Implementations must be safe for
+// concurrent use. The built-in schemes are available via NewTokenAuthenticator
+// and NewSSHAuthenticator; custom ones (e.g. gateway-issued JWTs) can be set
+// with Config.Authenticator.
+type Authenticator interface {
+	Apply(req *http.Request, body []byte) error
+}
+
+// responseObserver is implemented by authenticators that negotiate protocol
+// details from the server's responses.
+type responseObserver interface {
+	observeResponse(resp *http.Response)
+}
+
+// SSHAuthenticator signs requests with one or more SSH keys.
+type SSHAuthenticator struct {
+	signers []ssh.Signer
+}
+
+// NewSSHAuthenticator returns an Authenticator signing every request with each
+// of signers. Serveradmin accepts the request if it knows any of the keys.
+// Signers that fail are skipped, and security keys are only used when no
+// other signer succeeds.
+func NewSSHAuthenticator(signers ...ssh.Signer) *SSHAuthenticator {
+	return &SSHAuthenticator{signers: signers}
+}
+
+// Apply sets the X-PublicKeys and X-Signatures headers.
+func (a *SSHAuthenticator) Apply(req *http.Request, body []byte) error {
+	timestamp, err := requestTimestamp(req)
+	if err != nil {
+		return err
+	}
+	publicKeys, signatures, err := signMessage(req.Context(), a.signers, calcMessage(timestamp, body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-PublicKeys", publicKeys)
+	req.Header.Set("X-Signatures", signatures)
+	return nil
+}
+
+// requestTimestamp returns the X-Timestamp header of req, which is part of
+// the signed message.
+func requestTimestamp(req *http.Request) (int64, error) {
+	timestamp, err := strconv.ParseInt(req.Header.Get("X-Timestamp"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid X-Timestamp header: %w", err)
+	}
+	return timestamp, nil
+}
+
+var (
+	envAuthenticatorsMu sync.Mutex
+	envAuthenticators   = map[string]func() (Authenticator, error){}
+)
+
+// RegisterEnvAuthenticator makes SERVERADMIN_AUTH=name select the
+// Authenticator returned by newAuth in NewClientFromEnv. Optional schemes
+// register themselves when their package is imported, like database/sql
+// drivers, so the core package does not depend on them.
+func RegisterEnvAuthenticator(name string, newAuth func() (Authenticator, error)) {
+	envAuthenticatorsMu.Lock()
+	defer envAuthenticatorsMu.Unlock()
+	envAuthenticators[name] = newAuth
+}
+
+func envAuthenticator(name string) (func() (Authenticator, error), bool) {
+	envAuthenticatorsMu.Lock()
+	defer envAuthenticatorsMu.Unlock()
+	newAuth, ok := envAuthenticators[name]
+	return newAuth, ok
+}
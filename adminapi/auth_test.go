@@ -0,0 +1,60 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bearerAuthenticator is a custom scheme as used behind a JWT-issuing gateway.
+type bearerAuthenticator struct {
+	token    string
+	gotBody  []byte
+	gotStamp string
+}
+
+func (a *bearerAuthenticator) Apply(req *http.Request, body []byte) error {
+	a.gotBody = body
+	a.gotStamp = req.Header.Get("X-Timestamp")
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func TestCustomAuthenticator(t *testing.T) {
+	var gotAuthorization, gotSecurityToken string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthorization = r.Header.Get("Authorization")
+		gotSecurityToken = r.Header.Get("X-SecurityToken")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	auth := &bearerAuthenticator{token: "jwt"}
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "ignored", Authenticator: auth})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{"hostname": "a.local"})
+	_, err = q.All(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer jwt", gotAuthorization)
+	assert.Empty(t, gotSecurityToken, "built-in token auth must not run")
+	assert.Contains(t, string(auth.gotBody), `"hostname":"a.local"`)
+	assert.NotEmpty(t, auth.gotStamp, "X-Timestamp is set before Apply")
+}
+
+func TestRequestTimestamp(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, err := requestTimestamp(req)
+	require.Error(t, err)
+
+	req.Header.Set("X-Timestamp", "123456789")
+	timestamp, err := requestTimestamp(req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(123456789), timestamp)
+}
@@ -0,0 +1,109 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker configures a circuit breaker that stops sending requests to
+// a Serveradmin instance after consecutive failures. While open, requests fail
+// immediately with ErrCircuitOpen instead of waiting for timeouts. After
+// OpenTimeout a single trial request is let through: if it succeeds the
+// circuit closes again, otherwise it stays open for another OpenTimeout.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive failures (5xx responses,
+	// timeouts, refused and reset connections) that opens the circuit.
+	// Defaults to 5.
+	FailureThreshold int
+
+	// OpenTimeout is how long the circuit stays open before a trial request.
+	// Defaults to 30s.
+	OpenTimeout time.Duration
+}
+
+const (
+	defaultBreakerThreshold   = 5
+	defaultBreakerOpenTimeout = 30 * time.Second
+)
+
+// breaker is the state of a Client's circuit breaker.
+type breaker struct {
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time // zero while closed
+	trial    bool      // a trial request is in flight
+}
+
+func newBreaker(cfg CircuitBreaker) *breaker {
+	b := &breaker{
+		threshold:   cfg.FailureThreshold,
+		openTimeout: cfg.OpenTimeout,
+		now:         time.Now,
+	}
+	if b.threshold <= 0 {
+		b.threshold = defaultBreakerThreshold
+	}
+	if b.openTimeout <= 0 {
+		b.openTimeout = defaultBreakerOpenTimeout
+	}
+	return b
+}
+
+// allow reports whether a request may be sent, returning ErrCircuitOpen if not.
+// It also reports whether the request is the trial of a half-open circuit,
+// which has to be passed to record with its outcome.
+func (b *breaker) allow() (trial bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return false, nil
+	}
+	if b.trial || b.now().Sub(b.openedAt) < b.openTimeout {
+		return false, ErrCircuitOpen
+	}
+	b.trial = true
+	return true, nil
+}
+
+// record updates the breaker with the outcome of a request. Only 5xx responses
+// and timeouts or refused and reset connections count as failures. Errors that
+// say nothing about the server's health, like a canceled context, TLS errors or
+// a failure to sign the request, leave the failure count unchanged. While the
+// circuit is open, only the trial's outcome closes or reopens it; requests
+// started before it opened are ignored.
+func (b *breaker) record(trial bool, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		b.trial = false
+	} else if !b.openedAt.IsZero() {
+		return
+	}
+
+	var apiErr *APIError
+	switch {
+	case err == nil, errors.As(err, &apiErr) && apiErr.StatusCode < 500:
+		// the server responded
+		b.failures = 0
+		b.openedAt = time.Time{}
+	case apiErr != nil, isTransient(err) && !errors.Is(err, context.Canceled):
+		b.failures++
+		if trial || b.failures >= b.threshold {
+			b.openedAt = b.now()
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package adminapi
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls int
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		BaseURL:        server.URL,
+		Token:          "tok",
+		CircuitBreaker: &CircuitBreaker{FailureThreshold: 2, OpenTimeout: time.Minute},
+	})
+	require.NoError(t, err)
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	query := func() error {
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err := q.All(context.Background())
+		return err
+	}
+
+	// two failures open the circuit
+	require.Error(t, query())
+	require.Error(t, query())
+	require.ErrorIs(t, query(), ErrCircuitOpen)
+	assert.Equal(t, 2, calls, "open circuit sends no requests")
+
+	// a failing trial after the timeout keeps it open
+	now = now.Add(time.Minute)
+	require.Error(t, query())
+	assert.Equal(t, 3, calls)
+	require.ErrorIs(t, query(), ErrCircuitOpen)
+
+	// a successful trial closes it
+	now = now.Add(time.Minute)
+	status = http.StatusOK
+	require.NoError(t, query())
+	require.NoError(t, query())
+	assert.Equal(t, 5, calls)
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	b := newBreaker(CircuitBreaker{FailureThreshold: 1})
+
+	b.record(false, &APIError{StatusCode: http.StatusBadRequest})
+	b.record(false, context.Canceled)
+	_, err := b.allow()
+	require.NoError(t, err)
+
+	b.record(false, &APIError{StatusCode: http.StatusBadGateway})
+	_, err = b.allow()
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreakerIgnoresRequestsStartedBeforeOpening(t *testing.T) {
+	b := newBreaker(CircuitBreaker{FailureThreshold: 1, OpenTimeout: time.Minute})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	// a slow request starts while the circuit is closed, another one opens it
+	stale, err := b.allow()
+	require.NoError(t, err)
+	b.record(false, &APIError{StatusCode: http.StatusBadGateway})
+
+	now = now.Add(time.Minute)
+	trial, err := b.allow()
+	require.NoError(t, err)
+	require.True(t, trial)
+
+	// the slow request succeeds during the trial: no second trial starts and
+	// the circuit stays open
+	b.record(stale, nil)
+	_, err = b.allow()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// only the trial's outcome counts
+	b.record(trial, &APIError{StatusCode: http.StatusBadGateway})
+	_, err = b.allow()
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	trial, err = b.allow()
+	require.NoError(t, err)
+	b.record(trial, nil)
+	trial, err = b.allow()
+	require.NoError(t, err)
+	assert.False(t, trial, "the circuit is closed")
+}
+
+func TestCircuitBreakerIgnoresTLSErrors(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // expected handshake errors
+	server.StartTLS()
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		BaseURL:        server.URL,
+		Token:          "tok",
+		CircuitBreaker: &CircuitBreaker{FailureThreshold: 2},
+	})
+	require.NoError(t, err)
+
+	for range 3 {
+		q := client.NewQuery(Filters{})
+		_, err := q.All(context.Background())
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen, "the certificate error is reported")
+		assert.Contains(t, err.Error(), "certificate")
+	}
+}
+
+func TestCircuitBreakerOpeningDuringRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		BaseURL:        server.URL,
+		Token:          "tok",
+		Retry:          RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond},
+		CircuitBreaker: &CircuitBreaker{FailureThreshold: 2},
+	})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{})
+	_, err = q.All(context.Background())
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr, "the call reports the failure that opened the circuit")
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+
+	q = client.NewQuery(Filters{})
+	_, err = q.All(context.Background())
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
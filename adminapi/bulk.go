@@ -0,0 +1,92 @@
+package adminapi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Update sets the given attributes on all matching SA objects and commits the
+// changes in a single commit. It returns the commit ID and the number of
+// objects that actually changed; if no object needed a change, nothing is
+// committed and the commit ID is 0. Only the updated attributes are fetched,
+// so objects lacking one of them fail with ErrUnknownAttribute before anything
+// is committed. The query's loaded results, if any, are dropped as they are
+// stale afterwards.
+func (q *Query) Update(ctx context.Context, changes Attributes) (commitID, changed int, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	keys := slices.Sorted(maps.Keys(changes))
+	sub := q.derive()
+	sub.restrictedAttributes = keys
+	sub.relatedAttributes = nil
+	sub.orderBy = nil
+	objects, err := sub.All(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for _, key := range keys {
+		if err := objects.Set(key, changes[key]); err != nil {
+			return 0, 0, err
+		}
+	}
+	for _, obj := range objects {
+		if obj.CommitState() == StateChanged {
+			changed++
+		}
+	}
+	if changed == 0 {
+		return 0, 0, nil
+	}
+
+	commitID, err = objects.Commit(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	q.Invalidate()
+	return commitID, changed, nil
+}
+
+// DeleteAll deletes all matching SA objects in a single commit and returns the
+// commit ID and the number of deleted objects. Only the object_id of the
+// matches is fetched, unless the client uses CommitProtocolDeletedObjects,
+// which sends the deleted objects with the query's attributes. If confirm is
+// not nil, it is called with the number of matches before anything is deleted,
+// and DeleteAll returns ErrNotConfirmed unless it returns true, guarding
+// scripts against accidentally deleting thousands of objects. Nothing is
+// committed and confirm is not called if nothing matches.
+func (q *Query) DeleteAll(ctx context.Context, confirm func(n int) bool) (commitID, deleted int, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	client, err := q.resolveClient(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sub := q.derive()
+	if client.commitProtocol != CommitProtocolDeletedObjects {
+		sub.restrictedAttributes = []string{"object_id"}
+		sub.relatedAttributes = nil
+	}
+	sub.orderBy = nil
+	objects, err := sub.All(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(objects) == 0 {
+		return 0, 0, nil
+	}
+	if confirm != nil && !confirm(len(objects)) {
+		return 0, 0, fmt.Errorf("%d objects: %w", len(objects), ErrNotConfirmed)
+	}
+
+	objects.Delete()
+	commitID, err = objects.Commit(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	q.Invalidate()
+	return commitID, len(objects), nil
+}
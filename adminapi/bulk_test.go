@@ -0,0 +1,150 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryUpdate(t *testing.T) {
+	var queries []queryRequest
+	var commits []commitRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case apiEndpointQuery:
+			var req queryRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			queries = append(queries, req)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status":"success","result":[
+				{"object_id":1,"state":"online"},
+				{"object_id":2,"state":"maintenance"}
+			]}`))
+		case apiEndpointCommit:
+			var req commitRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			commits = append(commits, req)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":42}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"servertype": "vm"})
+	q.SetAttributes("hostname")
+
+	commitID, changed, err := q.Update(ctx, Attributes{"state": "maintenance"})
+	require.NoError(t, err)
+	assert.Equal(t, 42, commitID)
+	assert.Equal(t, 1, changed, "objects already in the state are not changed")
+	require.Len(t, queries, 1)
+	assert.Equal(t, []any{"state", "object_id"}, queries[0].Restricted)
+	assert.Equal(t, []string{"hostname"}, q.restrictedAttributes, "the query itself is unchanged")
+	require.Len(t, commits, 1)
+	require.Len(t, commits[0].Changed, 1)
+	assert.InDelta(t, 1, commits[0].Changed[0]["object_id"], 0)
+
+	commits = nil
+	commitID, changed, err = q.Update(ctx, Attributes{"state": "online"})
+	require.NoError(t, err)
+	assert.Equal(t, 42, commitID)
+	assert.Equal(t, 1, changed)
+
+	commits = nil
+	_, _, err = q.Update(ctx, Attributes{"nope": 1})
+	require.ErrorIs(t, err, ErrUnknownAttribute)
+	assert.Empty(t, commits, "nothing is committed if an attribute is missing")
+}
+
+func TestQueryUpdateNothingChanged(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == apiEndpointCommit {
+			t.Fatal("unexpected commit")
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"state":"online"}]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"servertype": "vm"})
+	commitID, changed, err := q.Update(context.Background(), Attributes{"state": "online"})
+	require.NoError(t, err)
+	assert.Zero(t, commitID)
+	assert.Zero(t, changed)
+}
+
+func TestQueryDeleteAll(t *testing.T) {
+	var queries []queryRequest
+	var commits []commitRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case apiEndpointQuery:
+			var req queryRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			queries = append(queries, req)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1},{"object_id":2}]}`))
+		case apiEndpointCommit:
+			var req commitRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			commits = append(commits, req)
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":7}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"hostname": StartsWith("tmp-")})
+
+	var asked int
+	_, _, err := q.DeleteAll(ctx, func(n int) bool {
+		asked = n
+		return false
+	})
+	require.ErrorIs(t, err, ErrNotConfirmed)
+	assert.Equal(t, 2, asked)
+	assert.Empty(t, commits, "nothing is deleted without confirmation")
+	require.Len(t, queries, 1)
+	assert.Equal(t, []any{"object_id"}, queries[0].Restricted)
+
+	commitID, deleted, err := q.DeleteAll(ctx, func(int) bool { return true })
+	require.NoError(t, err)
+	assert.Equal(t, 7, commitID)
+	assert.Equal(t, 2, deleted)
+	require.Len(t, commits, 1)
+	assert.Equal(t, []int{1, 2}, commits[0].Deleted)
+
+	_, deleted, err = q.DeleteAll(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted, "no confirmation is needed without callback")
+}
+
+func TestQueryDeleteAllNoMatches(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == apiEndpointCommit {
+			t.Fatal("unexpected commit")
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"hostname": "gone"})
+	_, deleted, err := q.DeleteAll(context.Background(), func(int) bool {
+		t.Fatal("confirm called without matches")
+		return false
+	})
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+}
@@ -23,7 +23,9 @@ type callResponse struct {
 
 // CallAPI calls a remote API function on the Serveradmin server using this client.
 // It takes a function group, function name, and keyword arguments as a map.
-func (c *Client) CallAPI(ctx context.Context, group, function string, args map[string]any) (any, error) {
+func (c *Client) CallAPI(ctx context.Context, group, function string, args map[string]any) (_ any, err error) {
+	defer recoverPanic(c, &err)
+
 	req := callRequest{
 		Group:  group,
 		Name:   function,
@@ -32,10 +34,11 @@ func (c *Client) CallAPI(ctx context.Context, group, function string, args map[s
 	}
 
 	resp, err := c.sendRequest(ctx, apiEndpointCall, req)
+	c.queryCache.clear() // API functions may change objects
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
 	var result callResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -43,7 +46,7 @@ func (c *Client) CallAPI(ctx context.Context, group, function string, args map[s
 	}
 
 	if result.Status == "error" {
-		return nil, fmt.Errorf("API call %s.%s failed: %s", group, function, result.Message)
+		return nil, fmt.Errorf("API call %s.%s failed (request id %s): %s", group, function, responseRequestID(resp), result.Message)
 	}
 
 	return result.RetVal, nil
@@ -0,0 +1,132 @@
+package adminapi
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// AllChunked returns all matching SA objects like All, but fetches them in
+// requests of at most chunkSize objects each, so that queries over huge
+// datasets don't run into server-side timeouts. The chunks are selected by
+// object_id: every request asks for the next chunkSize objects ordered by
+// object_id with an object_id greater than the last one received, so objects
+// are neither skipped nor repeated when others are created or deleted in
+// between. Queries for a list of object IDs, like ByIDs, ask for the next
+// chunkSize IDs instead, and union queries are chunked per alternative. The
+// result is ordered by object_id; the query's own ordering, limit and offset
+// are ignored, and the query itself is not modified.
+func (q *Query) AllChunked(ctx context.Context, chunkSize int) (_ ServerObjects, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	if chunkSize <= 0 {
+		return nil, errors.New("chunk size must be positive")
+	}
+
+	q.warnings = nil
+	if len(q.alternatives) == 0 {
+		return q.allChunked(ctx, q.filters, chunkSize)
+	}
+
+	// the object_ids of one alternative's chunk say nothing about the others'
+	result := ServerObjects{}
+	seen := make(map[int]bool)
+	for _, alternative := range q.alternatives {
+		filters := maps.Clone(alternative)
+		for attribute, value := range q.filters {
+			if existing, ok := filters[attribute]; ok && attribute == "object_id" {
+				value = createFilter("All", []any{existing, value})
+			}
+			filters[attribute] = value
+		}
+		objects, err := q.allChunked(ctx, filters, chunkSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			if !seen[obj.ObjectID()] {
+				seen[obj.ObjectID()] = true
+				result = append(result, obj)
+			}
+		}
+	}
+	slices.SortStableFunc(result, func(a, b *ServerObject) int { return cmp.Compare(a.ObjectID(), b.ObjectID()) })
+	return result, nil
+}
+
+// allChunked fetches the objects matching filters in chunks, see AllChunked.
+func (q *Query) allChunked(ctx context.Context, filters Filters, chunkSize int) (ServerObjects, error) {
+	if ids, ok := objectIDList(filters); ok {
+		return q.allIDsChunked(ctx, filters, ids, chunkSize)
+	}
+
+	result := ServerObjects{}
+	lastID := 0
+	for {
+		sub := q.chunkQuery(filters)
+		sub.limit = chunkSize
+		after := GreaterThan(lastID)
+		if existing, ok := filters["object_id"]; ok {
+			sub.filters["object_id"] = createFilter("All", []any{existing, after})
+		} else {
+			sub.filters["object_id"] = after
+		}
+
+		chunk, err := sub.All(ctx)
+		q.warnings = append(q.warnings, sub.warnings...)
+		if err != nil {
+			return nil, err
+		}
+		previousID := lastID
+		for _, obj := range chunk {
+			// servers ignoring the limit return all objects at once
+			if id := obj.ObjectID(); id > lastID {
+				lastID = id
+			}
+		}
+		result = append(result, chunk...)
+		if len(chunk) < chunkSize {
+			return result, nil
+		}
+		if lastID == previousID {
+			return nil, fmt.Errorf("chunked query made no progress after object_id %d", lastID)
+		}
+	}
+}
+
+// allIDsChunked fetches the objects with the given object IDs matching
+// filters, chunkSize IDs per request. Chunks with more IDs than fit in one
+// request are split further by the query itself.
+func (q *Query) allIDsChunked(ctx context.Context, filters Filters, ids []int, chunkSize int) (ServerObjects, error) {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	result := ServerObjects{}
+	for chunk := range slices.Chunk(ids, chunkSize) {
+		sub := q.chunkQuery(filters)
+		sub.filters["object_id"] = Any(chunk...)
+		objects, err := sub.All(ctx)
+		q.warnings = append(q.warnings, sub.warnings...)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, objects...)
+	}
+	return result, nil
+}
+
+// chunkQuery returns a query for one chunk of objects matching filters,
+// ordered by object_id.
+func (q *Query) chunkQuery(filters Filters) Query {
+	sub := q.derive()
+	sub.filters = maps.Clone(filters)
+	sub.alternatives = nil
+	sub.orderBy = []sortKey{{attribute: "object_id"}}
+	sub.limit = 0
+	sub.offset = 0
+	return sub
+}
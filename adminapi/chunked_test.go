@@ -0,0 +1,141 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllChunked(t *testing.T) {
+	var requests []queryRequest
+	ignoreLimit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+
+		// objects 1 to 7, honoring object_id > n and the limit
+		after := 0
+		if filter, ok := req.Filters["object_id"].(map[string]any); ok {
+			after = int(filter["GreaterThan"].(float64))
+		}
+		var objects []string
+		for id := after + 1; id <= 7 && (ignoreLimit || len(objects) < req.Limit); id++ {
+			objects = append(objects, fmt.Sprintf(`{"object_id":%d}`, id))
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[` + strings.Join(objects, ",") + `]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	q := mustClient(t, server.URL).NewQuery(Filters{"servertype": "vm"})
+	q.OrderBy("hostname")
+
+	objects, err := q.AllChunked(ctx, 3)
+	require.NoError(t, err)
+	assert.Len(t, objects, 7)
+	assert.Equal(t, 7, objects[6].ObjectID())
+	require.Len(t, requests, 3)
+	assert.Equal(t, 3, requests[0].Limit)
+	assert.Equal(t, "object_id", requests[0].OrderBy)
+	assert.Equal(t, map[string]any{"GreaterThan": float64(6)}, requests[2].Filters["object_id"])
+	assert.Equal(t, Filters{"servertype": "vm"}, q.filters, "the query itself is not modified")
+
+	t.Run("server ignoring the limit", func(t *testing.T) {
+		requests, ignoreLimit = nil, true
+		objects, err := q.AllChunked(ctx, 3)
+		require.NoError(t, err)
+		assert.Len(t, objects, 7)
+		assert.Len(t, requests, 2)
+	})
+
+	t.Run("invalid chunk size", func(t *testing.T) {
+		_, err := q.AllChunked(ctx, 0)
+		require.Error(t, err)
+	})
+}
+
+func TestAllChunkedUnion(t *testing.T) {
+	var idFilters []any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		idFilters = append(idFilters, req.Filters["object_id"])
+
+		// the first alternative matches objects 1 to 3, the second even ones
+		// up to 8, both honoring object_id > n and the limit
+		filter := req.Filters["object_id"].(map[string]any)
+		if all, ok := filter["All"].([]any); ok {
+			filter = all[len(all)-1].(map[string]any)
+		}
+		after := int(filter["GreaterThan"].(float64))
+		var objects []string
+		for id := after + 1; id <= 8 && len(objects) < req.Limit; id++ {
+			if req.Filters["servertype"] == nil && id <= 3 || req.Filters["servertype"] != nil && id%2 == 0 {
+				objects = append(objects, fmt.Sprintf(`{"object_id":%d}`, id))
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[` + strings.Join(objects, ",") + `]}`))
+	}))
+	defer server.Close()
+
+	q := mustClient(t, server.URL).NewUnionQuery(
+		Filters{"object_id": LessThan(4)},
+		Filters{"servertype": "hypervisor"},
+	)
+	objects, err := q.AllChunked(context.Background(), 2)
+	require.NoError(t, err)
+
+	var ids []int
+	for _, obj := range objects {
+		ids = append(ids, obj.ObjectID())
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 6, 8}, ids, "merged and ordered by object_id")
+	assert.Equal(t, map[string]any{"All": []any{
+		map[string]any{"LessThan": float64(4)},
+		map[string]any{"GreaterThan": float64(0)},
+	}}, idFilters[0], "the alternative's object_id filter is kept")
+}
+
+func TestAllChunkedByIDs(t *testing.T) {
+	var idLists [][]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Max-Query-Values", "2")
+		var req struct {
+			Filters map[string]map[string][]any `json:"filters"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		ids := req.Filters["object_id"]["Any"]
+		idLists = append(idLists, ids)
+
+		var objects []string
+		for _, id := range ids {
+			objects = append(objects, fmt.Sprintf(`{"object_id":%v}`, id))
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[` + strings.Join(objects, ",") + `]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	ctx := context.Background()
+	q := client.ByIDs(1)
+	_, err := q.All(ctx) // learn the query value limit
+	require.NoError(t, err)
+
+	idLists = nil
+	q = client.ByIDs(5, 1, 3, 2, 4)
+	objects, err := q.AllChunked(ctx, 3)
+	require.NoError(t, err)
+	assert.Len(t, objects, 5)
+	assert.Equal(t, [][]any{{1.0, 2.0}, {3.0}, {4.0, 5.0}}, idLists, "chunks of IDs, split to the query value limit")
+}
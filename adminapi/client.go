@@ -1,9 +1,14 @@
 package adminapi
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -14,40 +19,169 @@ import (
 // Config holds the explicit, per-instance configuration for a Client.
 //
 // Authentication is selected explicitly from the fields below, in this order:
-// SSHSigner, then KeyPath, then Token. No environment variables are consulted,
+// Authenticator, then SSHSigner/SSHSigners, then KeyPath, then TokenProvider,
+// then Token. No environment variables are consulted,
 // so an ambient SSH_AUTH_SOCK can never override an explicitly configured token.
 type Config struct {
 	// BaseURL is the Serveradmin base URL (required). A trailing "/api" is trimmed.
+	// A "unix:///path/to/socket" URL makes the client dial the given unix domain
+	// socket while still speaking HTTP, e.g. for a local socket proxy.
 	BaseURL string
 
-	// Token enables security-token authentication (HMAC-SHA1).
+	// Endpoints are further base URLs of the same Serveradmin, e.g. replicas,
+	// that requests fail over to when BaseURL cannot be reached (refused
+	// connections, DNS failures). An unreachable endpoint is tried again only
+	// after all others for 30 seconds. Unix socket URLs are not supported.
+	Endpoints []string
+
+	// Authenticator replaces the built-in authentication schemes with a custom
+	// one, e.g. a gateway-issued JWT. It takes precedence over all other
+	// authentication fields.
+	Authenticator Authenticator
+
+	// Token enables security-token authentication (HMAC-SHA1 or HMAC-SHA256,
+	// see TokenAlgorithm).
 	Token string
 
+	// TokenProvider enables security-token authentication with a token that is
+	// obtained for every request, e.g. a short-lived one from a secrets
+	// manager. It takes precedence over Token.
+	TokenProvider TokenProvider
+
+	// TokenAlgorithm selects the HMAC of the security token. The zero value,
+	// TokenAlgorithmAuto, switches from SHA-1 to SHA-256 once the server
+	// advertises support for it.
+	TokenAlgorithm TokenAlgorithm
+
 	// SSHSigner enables SSH-signature authentication using a pre-built signer.
 	// This takes precedence over KeyPath and Token.
 	SSHSigner ssh.Signer
 
+	// SSHSigners signs every request with each of the given signers in addition
+	// to SSHSigner. Serveradmin accepts the request as soon as any of the public
+	// keys is known to it, so all keys of an agent can be offered at once.
+	SSHSigners []ssh.Signer
+
 	// KeyPath is the path to a private key file used for SSH-signature
 	// authentication. Used only when SSHSigner is nil.
 	KeyPath string
 
+	// KeyPassphrase decrypts a passphrase-protected key at KeyPath.
+	KeyPassphrase string
+
+	// KeyPassphraseFunc is called to obtain the passphrase of an encrypted key
+	// at KeyPath when KeyPassphrase is empty, e.g. to read it from a secrets
+	// store or prompt for it. It is never called for unencrypted keys.
+	KeyPassphraseFunc func() ([]byte, error)
+
 	// HTTPClient is the HTTP client used for all requests. If nil, a dedicated
-	// client is created using Timeout.
+	// client is created using Timeout. It cannot be combined with a unix socket
+	// BaseURL, as the socket dialer lives in the generated client's transport.
 	HTTPClient *http.Client
 
 	// Timeout is applied to the generated HTTP client. Ignored when HTTPClient
 	// is provided. A zero value means no timeout.
 	Timeout time.Duration
+
+	// MaxConnsPerHost limits the number of connections the generated HTTP
+	// client opens to the server, including idle ones, so that many
+	// concurrent requests queue instead of flooding it. Ignored when
+	// HTTPClient is provided. A zero value means no limit.
+	MaxConnsPerHost int
+
+	// CommitProtocol selects the commit payload layout. The zero value,
+	// CommitProtocolCurrent, matches current Serveradmin releases.
+	CommitProtocol CommitProtocol
+
+	// RecoverPanics makes the public entry points (queries, commits, object
+	// creation, API calls) convert unexpected panics into a *PanicError with a
+	// stack trace, so long-running daemons never crash on a library edge case.
+	RecoverPanics bool
+
+	// SoftDelete enables support for an instance's "retired" convention:
+	// queries exclude retired objects by default and ServerObject.Retire and
+	// Unretire become available. Nil disables it.
+	SoftDelete *SoftDelete
+
+	// Provenance is attached to every commit to trace changes back to the
+	// automation run that made them; see ProvenanceFromBuild. Nil sends none.
+	Provenance *Provenance
+
+	// NewObjectDefaultsTTL caches the servertype defaults fetched by NewObject
+	// for this long, saving a request per object in bulk provisioning. Expired
+	// defaults are still used for up to 15 minutes while the server is
+	// unavailable (5xx responses, network failures), but not on other errors.
+	// Zero disables caching.
+	NewObjectDefaultsTTL time.Duration
+
+	// QueryCacheTTL caches query results in memory for this long, keyed by the
+	// query's filters, attributes, ordering and paging, for tools evaluating
+	// many overlapping queries per run. Every commit or API call through the
+	// client drops all cached results, as it may change which objects match.
+	// Zero disables caching.
+	QueryCacheTTL time.Duration
+
+	// Retry retries idempotent requests on transient failures such as 502,
+	// 503 and 504 responses or network errors. The zero value disables it.
+	Retry RetryPolicy
+
+	// CircuitBreaker makes requests fail fast with ErrCircuitOpen after
+	// consecutive failures, instead of each waiting for a down API to time
+	// out. Nil disables it.
+	CircuitBreaker *CircuitBreaker
+
+	// CompressRequests gzip-compresses request bodies of 1 KiB and more,
+	// sending them with Content-Encoding: gzip. This shrinks large commits
+	// considerably; the server must accept compressed requests.
+	CompressRequests bool
+
+	// ConditionalQueries keeps the last result of each query in memory and
+	// repeats the query with If-None-Match, so an unchanged result set is not
+	// transferred again when the server answers 304 Not Modified. Useful for
+	// pollers re-running the same query. Up to 64 MiB of results are kept,
+	// evicting the least recently used ones; larger results are not cached.
+	ConditionalQueries bool
+
+	// DebugWriter receives a dump of every request and response, with
+	// credentials and signatures redacted, e.g. to diagnose rejected commits.
+	// Nil disables it.
+	DebugWriter io.Writer
+
+	// Logger receives a structured event for every request with its endpoint,
+	// duration, status, payload size and retry count. Nil disables logging.
+	Logger *slog.Logger
+
+	// OnQueryWarning is called with every warning in a query response, e.g.
+	// about deprecated attributes or truncated results, and the query it came
+	// from. The warnings of a query's last run are also available with
+	// Query.Warnings. Nil ignores them.
+	OnQueryWarning func(query string, warning string)
 }
 
 // Client is a per-instance Serveradmin API client. It carries its own
 // configuration and *http.Client and is safe for concurrent use: all fields are
-// set once at construction and never mutated afterwards.
+// set once at construction and never mutated afterwards; state learned from
+// responses, such as Limits, is synchronized internally.
 type Client struct {
-	baseURL    string
-	authToken  []byte
-	sshSigner  ssh.Signer
-	httpClient *http.Client
+	baseURL          string
+	endpoints        *endpoints // nil without Config.Endpoints
+	auth             Authenticator
+	httpClient       *http.Client
+	commitProtocol   CommitProtocol
+	recoverPanics    bool
+	softDelete       *SoftDelete
+	provenance       *Provenance
+	compressRequests bool
+	defaults         *defaultsCache // nil when caching is disabled
+	queryCache       *queryCache    // nil when caching is disabled
+	retry            RetryPolicy
+	limits           *limitsState
+	clock            *clock
+	breaker          *breaker     // nil when disabled
+	etags            *etagCache   // nil when disabled
+	debug            *debugWriter // nil when disabled
+	logger           *slog.Logger // nil when disabled
+	onQueryWarning   func(query, warning string)
 }
 
 // NewClient builds a Client from an explicit Config. It performs no environment
@@ -58,34 +192,180 @@ func NewClient(cfg Config) (*Client, error) {
 		return nil, errors.New("config: BaseURL is required")
 	}
 
+	baseURL, socketPath, err := parseBaseURL(cfg.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var failover *endpoints
+	if len(cfg.Endpoints) > 0 {
+		if socketPath != "" {
+			return nil, errors.New("config: Endpoints cannot be combined with a unix socket BaseURL")
+		}
+		if failover, err = newEndpoints(baseURL, cfg.Endpoints); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.SoftDelete != nil && (cfg.SoftDelete.Attribute == "" || cfg.SoftDelete.RetiredValue == "") {
+		return nil, errors.New("config: SoftDelete requires Attribute and RetiredValue")
+	}
+
 	c := &Client{
-		baseURL: strings.TrimSuffix(cfg.BaseURL, "/api"),
+		baseURL:          baseURL,
+		endpoints:        failover,
+		commitProtocol:   cfg.CommitProtocol,
+		recoverPanics:    cfg.RecoverPanics,
+		softDelete:       cfg.SoftDelete,
+		provenance:       cfg.Provenance,
+		compressRequests: cfg.CompressRequests,
+		retry:            cfg.Retry,
+		limits:           &limitsState{now: time.Now},
+		clock:            &clock{now: time.Now},
+		logger:           cfg.Logger,
+		onQueryWarning:   cfg.OnQueryWarning,
 	}
 
 	switch {
-	case cfg.SSHSigner != nil:
-		c.sshSigner = cfg.SSHSigner
+	case cfg.Authenticator != nil:
+		c.auth = cfg.Authenticator
+	case cfg.SSHSigner != nil || len(cfg.SSHSigners) > 0:
+		var signers []ssh.Signer
+		if cfg.SSHSigner != nil {
+			signers = append(signers, cfg.SSHSigner)
+		}
+		c.auth = NewSSHAuthenticator(append(signers, cfg.SSHSigners...)...)
 	case cfg.KeyPath != "":
-		keyBytes, err := os.ReadFile(cfg.KeyPath)
+		signer, err := loadPrivateKey(cfg)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read private key from %s: %w", cfg.KeyPath, err)
+			return nil, err
 		}
-		signer, err := ssh.ParsePrivateKey(keyBytes)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse private key: %w", err)
-		}
-		c.sshSigner = signer
+		c.auth = NewSSHAuthenticator(signer)
+	case cfg.TokenProvider != nil:
+		c.auth = NewTokenAuthenticator(cfg.TokenProvider, cfg.TokenAlgorithm)
 	case cfg.Token != "":
-		c.authToken = []byte(cfg.Token)
+		c.auth = NewTokenAuthenticator(StaticToken(cfg.Token), cfg.TokenAlgorithm)
 	default:
-		return nil, errors.New("config: no authentication method configured: set Token, SSHSigner or KeyPath")
+		return nil, errors.New("config: no authentication method configured: set Token, TokenProvider, SSHSigner(s), KeyPath or Authenticator")
 	}
 
-	if cfg.HTTPClient != nil {
+	switch {
+	case cfg.HTTPClient != nil && socketPath != "":
+		return nil, errors.New("config: HTTPClient cannot be combined with a unix socket BaseURL")
+	case cfg.HTTPClient != nil:
 		c.httpClient = cfg.HTTPClient
-	} else {
-		c.httpClient = &http.Client{Timeout: cfg.Timeout}
+	default:
+		transport := newTransport(cfg.MaxConnsPerHost)
+		if socketPath != "" {
+			dialUnixSocket(transport, socketPath)
+		}
+		c.httpClient = &http.Client{Timeout: cfg.Timeout, Transport: transport}
+	}
+
+	if cfg.CircuitBreaker != nil {
+		c.breaker = newBreaker(*cfg.CircuitBreaker)
+	}
+	if cfg.ConditionalQueries {
+		c.etags = newETagCache()
+	}
+	if cfg.DebugWriter != nil {
+		c.debug = &debugWriter{w: cfg.DebugWriter}
+	}
+	if cfg.NewObjectDefaultsTTL > 0 {
+		c.defaults = newDefaultsCache(cfg.NewObjectDefaultsTTL)
+	}
+	if cfg.QueryCacheTTL > 0 {
+		c.queryCache = newQueryCache(cfg.QueryCacheTTL)
 	}
 
 	return c, nil
 }
+
+// loadPrivateKey reads and parses the private key at cfg.KeyPath, decrypting it
+// with KeyPassphrase or the result of KeyPassphraseFunc if it is encrypted.
+func loadPrivateKey(cfg Config) (ssh.Signer, error) {
+	keyBytes, err := os.ReadFile(cfg.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key from %s: %w", cfg.KeyPath, err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	var missingErr *ssh.PassphraseMissingError
+	if !errors.As(err, &missingErr) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		return signer, nil
+	}
+
+	passphrase := []byte(cfg.KeyPassphrase)
+	if len(passphrase) == 0 && cfg.KeyPassphraseFunc != nil {
+		passphrase, err = cfg.KeyPassphraseFunc()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get passphrase for private key %s: %w", cfg.KeyPath, err)
+		}
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("private key %s is encrypted, set a passphrase: %w", cfg.KeyPath, missingErr)
+	}
+
+	signer, err = ssh.ParsePrivateKeyWithPassphrase(keyBytes, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt private key %s: %w", cfg.KeyPath, err)
+	}
+	return signer, nil
+}
+
+// unixSocketBaseURL is the placeholder base URL used for requests sent over a
+// unix domain socket. The host part is never resolved: the transport dials the
+// socket for every connection.
+const unixSocketBaseURL = "http://unix"
+
+// parseBaseURL returns the base URL requests are sent to and, for
+// "unix://" URLs, the path of the socket to dial.
+func parseBaseURL(rawURL string) (baseURL, socketPath string, err error) {
+	if !strings.HasPrefix(rawURL, "unix:") {
+		return strings.TrimSuffix(rawURL, "/api"), "", nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", fmt.Errorf("config: invalid BaseURL %q: %w", rawURL, err)
+	}
+	socketPath = u.Path
+	if socketPath == "" {
+		socketPath = u.Opaque
+	}
+	if socketPath == "" {
+		return "", "", fmt.Errorf("config: BaseURL %q has no socket path", rawURL)
+	}
+
+	return unixSocketBaseURL, socketPath, nil
+}
+
+// maxIdleConnsPerHost is the number of idle connections kept open to the
+// server, up from net/http's default of 2, so that bursts of concurrent
+// requests reuse connections instead of reopening them.
+const maxIdleConnsPerHost = 16
+
+// newTransport returns the HTTP transport of a client without a custom
+// HTTPClient: a dedicated copy of http.DefaultTransport, so that its
+// connection pool is not shared with unrelated code, that negotiates HTTP/2
+// with TLS servers supporting it and keeps more idle connections.
+func newTransport(maxConnsPerHost int) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ForceAttemptHTTP2 = true
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.MaxConnsPerHost = maxConnsPerHost
+	return transport
+}
+
+// dialUnixSocket makes transport dial socketPath for every connection,
+// regardless of the request's host.
+func dialUnixSocket(transport *http.Transport, socketPath string) {
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", socketPath)
+	}
+}
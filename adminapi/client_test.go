@@ -2,10 +2,18 @@ package adminapi
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -23,6 +31,23 @@ func mustClient(t *testing.T, baseURL string) *Client {
 	return c
 }
 
+// sshSignersOf returns the signers of a client using SSH authentication.
+func sshSignersOf(c *Client) []ssh.Signer {
+	if auth, ok := c.auth.(*SSHAuthenticator); ok {
+		return auth.signers
+	}
+	return nil
+}
+
+// tokenProviderOf returns the token provider of a client using security token
+// authentication.
+func tokenProviderOf(c *Client) TokenProvider {
+	if auth, ok := c.auth.(*TokenAuthenticator); ok {
+		return auth.provider
+	}
+	return nil
+}
+
 func TestNewClientValidation(t *testing.T) {
 	t.Run("missing BaseURL", func(t *testing.T) {
 		_, err := NewClient(Config{Token: "tok"})
@@ -39,15 +64,62 @@ func TestNewClientValidation(t *testing.T) {
 	t.Run("token auth", func(t *testing.T) {
 		c, err := NewClient(Config{BaseURL: "https://example.com", Token: "tok"})
 		require.NoError(t, err)
-		assert.Equal(t, "tok", string(c.authToken))
-		assert.Nil(t, c.sshSigner)
+		assert.Equal(t, StaticToken("tok"), tokenProviderOf(c))
+		assert.Empty(t, sshSignersOf(c))
 	})
 
 	t.Run("key path auth", func(t *testing.T) {
 		c, err := NewClient(Config{BaseURL: "https://example.com", KeyPath: "testdata/test.key"})
 		require.NoError(t, err)
-		assert.NotNil(t, c.sshSigner)
-		assert.Empty(t, c.authToken)
+		assert.Len(t, sshSignersOf(c), 1)
+		assert.Nil(t, tokenProviderOf(c))
+	})
+
+	t.Run("encrypted key with passphrase", func(t *testing.T) {
+		c, err := NewClient(Config{BaseURL: "https://example.com", KeyPath: "testdata/test_encrypted.key", KeyPassphrase: "test-passphrase"})
+		require.NoError(t, err)
+		assert.Len(t, sshSignersOf(c), 1)
+	})
+
+	t.Run("encrypted key with passphrase func", func(t *testing.T) {
+		calls := 0
+		c, err := NewClient(Config{
+			BaseURL: "https://example.com",
+			KeyPath: "testdata/test_encrypted.key",
+			KeyPassphraseFunc: func() ([]byte, error) {
+				calls++
+				return []byte("test-passphrase"), nil
+			},
+		})
+		require.NoError(t, err)
+		assert.Len(t, sshSignersOf(c), 1)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("passphrase func not called for unencrypted key", func(t *testing.T) {
+		_, err := NewClient(Config{
+			BaseURL: "https://example.com",
+			KeyPath: "testdata/test.key",
+			KeyPassphraseFunc: func() ([]byte, error) {
+				t.Fatal("passphrase func must not be called")
+				return nil, nil
+			},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("encrypted key without passphrase", func(t *testing.T) {
+		_, err := NewClient(Config{BaseURL: "https://example.com", KeyPath: "testdata/test_encrypted.key"})
+		require.Error(t, err)
+		var missingErr *ssh.PassphraseMissingError
+		require.ErrorAs(t, err, &missingErr)
+		assert.Contains(t, err.Error(), "is encrypted")
+	})
+
+	t.Run("encrypted key with wrong passphrase", func(t *testing.T) {
+		_, err := NewClient(Config{BaseURL: "https://example.com", KeyPath: "testdata/test_encrypted.key", KeyPassphrase: "nope"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to decrypt private key")
 	})
 
 	t.Run("explicit signer auth", func(t *testing.T) {
@@ -58,7 +130,19 @@ func TestNewClientValidation(t *testing.T) {
 
 		c, err := NewClient(Config{BaseURL: "https://example.com", SSHSigner: signer})
 		require.NoError(t, err)
-		assert.Equal(t, signer, c.sshSigner)
+		assert.Equal(t, []ssh.Signer{signer}, sshSignersOf(c))
+	})
+
+	t.Run("multiple signers", func(t *testing.T) {
+		keyBytes, err := os.ReadFile("testdata/test.key")
+		require.NoError(t, err)
+		signer, err := ssh.ParsePrivateKey(keyBytes)
+		require.NoError(t, err)
+		other := newTestSigner(t)
+
+		c, err := NewClient(Config{BaseURL: "https://example.com", SSHSigner: signer, SSHSigners: []ssh.Signer{other}})
+		require.NoError(t, err)
+		assert.Equal(t, []ssh.Signer{signer, other}, sshSignersOf(c))
 	})
 
 	t.Run("signer takes precedence over token", func(t *testing.T) {
@@ -69,8 +153,8 @@ func TestNewClientValidation(t *testing.T) {
 
 		c, err := NewClient(Config{BaseURL: "https://example.com", SSHSigner: signer, Token: "tok"})
 		require.NoError(t, err)
-		assert.NotNil(t, c.sshSigner)
-		assert.Empty(t, c.authToken, "token must be ignored when a signer is set")
+		assert.NotEmpty(t, sshSignersOf(c))
+		assert.Nil(t, tokenProviderOf(c), "token must be ignored when a signer is set")
 	})
 
 	t.Run("trims /api suffix", func(t *testing.T) {
@@ -86,11 +170,76 @@ func TestNewClientValidation(t *testing.T) {
 		assert.Same(t, custom, c.httpClient)
 	})
 
+	t.Run("unix socket base URL", func(t *testing.T) {
+		c, err := NewClient(Config{BaseURL: "unix:///run/serveradmin.sock", Token: "tok"})
+		require.NoError(t, err)
+		assert.Equal(t, unixSocketBaseURL, c.baseURL)
+		assert.NotNil(t, c.httpClient.Transport)
+	})
+
+	t.Run("unix socket without path", func(t *testing.T) {
+		_, err := NewClient(Config{BaseURL: "unix://", Token: "tok"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no socket path")
+	})
+
+	t.Run("unix socket with custom http client", func(t *testing.T) {
+		_, err := NewClient(Config{BaseURL: "unix:///run/serveradmin.sock", Token: "tok", HTTPClient: &http.Client{}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot be combined")
+	})
+
 	t.Run("timeout applied to generated client", func(t *testing.T) {
 		c, err := NewClient(Config{BaseURL: "https://example.com", Token: "tok", Timeout: 3 * time.Second})
 		require.NoError(t, err)
 		assert.Equal(t, 3*time.Second, c.httpClient.Timeout)
 	})
+
+	t.Run("dedicated transport", func(t *testing.T) {
+		c, err := NewClient(Config{BaseURL: "https://example.com", Token: "tok", MaxConnsPerHost: 4})
+		require.NoError(t, err)
+		transport, ok := c.httpClient.Transport.(*http.Transport)
+		require.True(t, ok)
+		assert.NotSame(t, http.DefaultTransport, transport)
+		assert.True(t, transport.ForceAttemptHTTP2)
+		assert.Equal(t, maxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 4, transport.MaxConnsPerHost)
+	})
+}
+
+// TestClientReusesConnections verifies that sequential requests share one
+// connection, which requires every response body to be read to the end, even
+// past trailing whitespace the JSON decoder stops before.
+func TestClientReusesConnections(t *testing.T) {
+	padding := strings.Repeat(" ", 8<<10)
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == apiEndpointCommit {
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}` + padding))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a"}]}` + padding))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	ctx := context.Background()
+	for range 5 {
+		q := client.NewQuery(Filters{"hostname": "a"})
+		obj, err := q.One(ctx)
+		require.NoError(t, err)
+		require.NoError(t, obj.Set("hostname", "b"))
+		_, err = obj.Commit(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), conns.Load())
 }
 
 // TestClientSendsOwnAuthHeaders verifies a token client signs requests with its
@@ -121,6 +270,89 @@ func TestClientSendsOwnAuthHeaders(t *testing.T) {
 	assert.NotEmpty(t, gotTimestamp)
 }
 
+// TestClientSignsWithAllKeys verifies every configured key signs the request
+// and the headers carry comma-separated keys and signatures in the same order.
+func TestClientSignsWithAllKeys(t *testing.T) {
+	signers := []ssh.Signer{newTestSigner(t), newTestSigner(t)}
+
+	var gotKeys, gotSignatures, gotTimestamp string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotKeys = r.Header.Get("X-PublicKeys")
+		gotSignatures = r.Header.Get("X-Signatures")
+		gotTimestamp = r.Header.Get("X-Timestamp")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{BaseURL: server.URL, SSHSigners: signers})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{"hostname": "a.local"})
+	_, err = q.All(context.Background())
+	require.NoError(t, err)
+
+	keys := strings.Split(gotKeys, ",")
+	signatures := strings.Split(gotSignatures, ",")
+	require.Len(t, keys, 2)
+	require.Len(t, signatures, 2)
+
+	message := []byte(gotTimestamp + ":" + string(gotBody))
+	for i, signer := range signers {
+		assert.Equal(t, base64.StdEncoding.EncodeToString(signer.PublicKey().Marshal()), keys[i])
+
+		sigBytes, err := base64.StdEncoding.DecodeString(signatures[i])
+		require.NoError(t, err)
+		var signature ssh.Signature
+		require.NoError(t, ssh.Unmarshal(sigBytes, &signature))
+		assert.NoError(t, signer.PublicKey().Verify(message, &signature))
+	}
+}
+
+// newTestSigner returns a signer for a freshly generated ed25519 key.
+func newTestSigner(t *testing.T) ssh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return signer
+}
+
+// TestClientUnixSocket verifies that a unix:// BaseURL dials the socket and
+// still sends signed HTTP requests to the regular API paths.
+func TestClientUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "serveradmin.sock")
+	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+
+	var gotPath, gotAppID, gotToken string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAppID = r.Header.Get("X-Application")
+		gotToken = r.Header.Get("X-SecurityToken")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a.local"}]}`))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client, err := NewClient(Config{BaseURL: "unix://" + socketPath, Token: "secret-token"})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{"hostname": "a.local"})
+	servers, err := q.All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+
+	assert.Equal(t, apiEndpointQuery, gotPath)
+	assert.Equal(t, calcAppID([]byte("secret-token")), gotAppID)
+	assert.NotEmpty(t, gotToken)
+}
+
 // TestTwoClientsParallel is the acceptance test: a single process holds two
 // clients with different BaseURL/Token and queries both concurrently. Each
 // server must only ever see its own token's application id and return its own
@@ -0,0 +1,69 @@
+package adminapi
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// clockSkewTolerance is the difference between the server's and the local
+// clock up to which a rejected request is not blamed on clock skew.
+const clockSkewTolerance = 30 * time.Second
+
+// clock produces the X-Timestamp of requests. Serveradmin rejects requests
+// whose timestamp is too far off its own time, so once it does so while its
+// Date header shows a skewed local clock, timestamps are shifted by the
+// measured offset.
+type clock struct {
+	now func() time.Time
+
+	measured atomic.Int64 // server minus local time at the last response
+	offset   atomic.Int64 // applied to timestamps
+}
+
+// timestamp returns the Unix time to send as X-Timestamp.
+func (c *clock) timestamp() int64 {
+	return c.now().Add(time.Duration(c.offset.Load())).Unix()
+}
+
+// observe measures the skew from the Date header of a response.
+func (c *clock) observe(header http.Header) {
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return
+	}
+	c.measured.Store(int64(date.Sub(c.now())))
+}
+
+// adjust reports whether a request that failed with err was rejected because
+// of clock skew and should be repeated, in which case it has adjusted the
+// offset for further timestamps to the skew measured from the rejection.
+func (c *clock) adjust(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+	default:
+		return false
+	}
+
+	measured := time.Duration(c.measured.Load())
+	if (measured - time.Duration(c.offset.Load())).Abs() <= clockSkewTolerance {
+		// the timestamp was close enough, the request failed for another reason
+		return false
+	}
+	c.offset.Store(int64(measured))
+	return true
+}
+
+// ClockSkew returns how far the server's clock is ahead of the local one (or
+// behind it, if negative), as measured from the Date header of the most
+// recent response. It is zero before the first response. Requests are sent
+// with timestamps corrected by this offset once the server rejected one for
+// being off, so monitoring it helps to spot hosts with drifting clocks.
+func (c *Client) ClockSkew() time.Duration {
+	return time.Duration(c.clock.measured.Load())
+}
@@ -0,0 +1,62 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockSkew(t *testing.T) {
+	serverTime := time.Now().Add(time.Hour)
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+		timestamp, _ := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+		if (time.Duration(serverTime.Unix()-timestamp) * time.Second).Abs() > 5*time.Minute {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"message":"Request expired"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	assert.Zero(t, client.ClockSkew())
+	query := func() error {
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err := q.All(context.Background())
+		return err
+	}
+
+	require.NoError(t, query())
+	assert.Equal(t, 2, calls, "the rejected request is repeated with an adjusted timestamp")
+	assert.InDelta(t, time.Hour, client.ClockSkew(), float64(5*time.Second))
+
+	calls = 0
+	require.NoError(t, query())
+	assert.Equal(t, 1, calls, "later requests use the adjusted timestamp right away")
+}
+
+func TestClockSkewOtherRejections(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"hostname": "a.local"})
+	_, err := q.All(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 1, calls, "rejections without clock skew are not repeated")
+}
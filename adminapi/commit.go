@@ -7,11 +7,46 @@ import (
 	"fmt"
 )
 
+// CommitProtocol selects the layout of the payload sent to /api/dataset/commit,
+// so the client can talk to Serveradmin deployments of different generations.
+type CommitProtocol int
+
+const (
+	// CommitProtocolCurrent sends deleted objects as a list of object ids. This
+	// is the default and matches current Serveradmin releases.
+	CommitProtocolCurrent CommitProtocol = iota
+	// CommitProtocolDeletedObjects sends deleted objects as their full attribute
+	// maps, as expected by older Serveradmin deployments.
+	CommitProtocolDeletedObjects
+)
+
 // commitRequest is the payload sent to /api/dataset/commit
 type commitRequest struct {
 	Created []Attributes `json:"created"`
 	Changed []Attributes `json:"changed"`
 	Deleted []int        `json:"deleted"` // the object-ids
+
+	deletedObjects []Attributes // full objects, for CommitProtocolDeletedObjects
+}
+
+// deletedObjectsCommitRequest is the commitRequest layout of
+// CommitProtocolDeletedObjects.
+type deletedObjectsCommitRequest struct {
+	Created []Attributes `json:"created"`
+	Changed []Attributes `json:"changed"`
+	Deleted []Attributes `json:"deleted"` // the full objects
+}
+
+// payload returns the request body for the given protocol.
+func (c commitRequest) payload(protocol CommitProtocol) any {
+	if protocol == CommitProtocolDeletedObjects {
+		return deletedObjectsCommitRequest{
+			Created: c.Created,
+			Changed: c.Changed,
+			Deleted: c.deletedObjects,
+		}
+	}
+	return c
 }
 
 type commitResponse struct {
@@ -22,13 +57,17 @@ type commitResponse struct {
 }
 
 // Commit commits all changed, created, and deleted objects in a single API call.
-func (s ServerObjects) Commit(ctx context.Context) (int, error) {
-	client, err := resolveObjectsClient(s)
+func (s ServerObjects) Commit(ctx context.Context) (_ int, err error) {
+	client, err := resolveObjectsClient(ctx, s)
+	defer recoverPanic(client, &err)
 	if err != nil {
 		return 0, err
 	}
 
 	commit := buildCommit(s)
+	if limit := client.Limits().MaxCommitObjects; limit > 0 && commit.size() > limit {
+		return 0, fmt.Errorf("commit of %d objects exceeds the server's limit of %d: %w", commit.size(), limit, ErrCommitTooLarge)
+	}
 
 	commitID, err := client.sendCommit(ctx, commit)
 	if err != nil {
@@ -53,7 +92,10 @@ func (s ServerObjects) Rollback() {
 // If any Set operation fails, all errors are collected and returned
 // as a joined error. This allows identifying all problematic objects
 // in a single call rather than failing on the first error.
-func (s ServerObjects) Set(key string, value any) error {
+func (s ServerObjects) Set(key string, value any) (err error) {
+	client, _ := resolveObjectsClient(context.Background(), s)
+	defer recoverPanic(client, &err)
+
 	var errs []error
 	for i, obj := range s {
 		if err := obj.Set(key, value); err != nil {
@@ -72,8 +114,10 @@ func (s ServerObjects) Delete() {
 }
 
 // Commit commits this single object's changes to the server.
-func (s *ServerObject) Commit(ctx context.Context) (int, error) {
-	client, err := s.resolveClient()
+func (s *ServerObject) Commit(ctx context.Context) (_ int, err error) {
+	defer recoverPanic(clientOrContext(ctx, s.boundClient()), &err)
+
+	client, err := s.resolveClient(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -88,23 +132,34 @@ func (s *ServerObject) Commit(ctx context.Context) (int, error) {
 	return commitID, nil
 }
 
-// resolveClient returns the object's bound client.
-func (s *ServerObject) resolveClient() (*Client, error) {
-	if s.client == nil {
-		return nil, errors.New("object is not bound to a client; obtain it via a Client query or Client.NewObject")
+// resolveClient returns the object's bound client, falling back to the client
+// attached to ctx with WithClient.
+func (s *ServerObject) resolveClient(ctx context.Context) (*Client, error) {
+	if s == nil {
+		return nil, ErrNilObject
+	}
+	if s.client != nil {
+		return s.client, nil
 	}
-	return s.client, nil
+	if client, ok := FromContext(ctx); ok {
+		return client, nil
+	}
+	return nil, errors.New("object is not bound to a client; obtain it via a Client query or Client.NewObject, or use WithClient")
 }
 
-// resolveObjectsClient returns the client bound to the objects. All objects in a
-// set are expected to originate from the same client.
-func resolveObjectsClient(objects ServerObjects) (*Client, error) {
+// resolveObjectsClient returns the client bound to the objects, falling back to
+// the client attached to ctx with WithClient. All objects in a set are expected
+// to originate from the same client; nil objects are skipped.
+func resolveObjectsClient(ctx context.Context, objects ServerObjects) (*Client, error) {
 	for _, obj := range objects {
-		if obj.client != nil {
+		if obj != nil && obj.client != nil {
 			return obj.client, nil
 		}
 	}
-	return nil, errors.New("no object is bound to a client; obtain them via a Client query")
+	if client, ok := FromContext(ctx); ok {
+		return client, nil
+	}
+	return nil, errors.New("no object is bound to a client; obtain them via a Client query, or use WithClient")
 }
 
 func buildCommit(objects ServerObjects) commitRequest {
@@ -112,6 +167,8 @@ func buildCommit(objects ServerObjects) commitRequest {
 		Created: []Attributes{},
 		Changed: []Attributes{},
 		Deleted: []int{}, // the object-ids
+
+		deletedObjects: []Attributes{},
 	}
 
 	for _, obj := range objects {
@@ -122,6 +179,7 @@ func buildCommit(objects ServerObjects) commitRequest {
 			commit.Changed = append(commit.Changed, obj.serializeChanges())
 		case StateDeleted:
 			commit.Deleted = append(commit.Deleted, obj.ObjectID())
+			commit.deletedObjects = append(commit.deletedObjects, obj.attributes)
 		case StateConsistent:
 			// No changes to commit
 		}
@@ -130,12 +188,18 @@ func buildCommit(objects ServerObjects) commitRequest {
 	return commit
 }
 
+// size returns the number of objects in the commit.
+func (c commitRequest) size() int {
+	return len(c.Created) + len(c.Changed) + len(c.Deleted)
+}
+
 func (c *Client) sendCommit(ctx context.Context, commit commitRequest) (int, error) {
-	resp, err := c.sendRequest(ctx, apiEndpointCommit, commit)
+	resp, err := c.sendRequest(ctx, apiEndpointCommit, commit.payload(c.commitProtocol))
+	c.queryCache.clear() // even a failed commit may have been applied
 	if err != nil {
 		return 0, err
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
 	var result commitResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
@@ -143,7 +207,7 @@ func (c *Client) sendCommit(ctx context.Context, commit commitRequest) (int, err
 	}
 
 	if result.Status == "error" {
-		return 0, fmt.Errorf("commit failed: %s", result.Message)
+		return 0, fmt.Errorf("commit failed (request id %s): %s", responseRequestID(resp), result.Message)
 	}
 
 	return result.CommitID, nil
@@ -89,6 +89,56 @@ func TestCommitResultSet(t *testing.T) {
 	assert.Empty(t, receivedBody.Created)
 }
 
+func TestCommitProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol CommitProtocol
+		want     string
+	}{
+		{
+			name:     "current protocol sends object ids",
+			protocol: CommitProtocolCurrent,
+			want:     `[3]`,
+		},
+		{
+			name:     "deleted objects protocol sends full objects",
+			protocol: CommitProtocolDeletedObjects,
+			want:     `[{"hostname":"deleted.local","object_id":3}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var receivedBody map[string]json.RawMessage
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				json.Unmarshal(body, &receivedBody)
+
+				w.WriteHeader(200)
+				w.Write([]byte(`{"status": "success", "commit_id": 1}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Config{BaseURL: server.URL, Token: "test-token", CommitProtocol: tt.protocol})
+			require.NoError(t, err)
+
+			obj := &ServerObject{
+				client:     client,
+				attributes: Attributes{"hostname": "deleted.local", "object_id": float64(3)},
+				oldValues:  Attributes{},
+				deleted:    true,
+			}
+
+			_, err = obj.Commit(context.Background())
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tt.want, string(receivedBody["deleted"]))
+			assert.JSONEq(t, `[]`, string(receivedBody["created"]))
+			assert.JSONEq(t, `[]`, string(receivedBody["changed"]))
+		})
+	}
+}
+
 func TestServerObjectsSetSuccess(t *testing.T) {
 	objects := ServerObjects{
 		{
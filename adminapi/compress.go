@@ -0,0 +1,28 @@
+package adminapi
+
+import (
+	"bytes"
+	"compress/gzip"
+)
+
+// minCompressSize is the smallest request body compressed when
+// Config.CompressRequests is set; smaller bodies do not benefit.
+const minCompressSize = 1024
+
+// compressBody returns the gzip-compressed body to send instead of postStr, or
+// nil if the request should be sent uncompressed.
+func (c *Client) compressBody(postStr []byte) ([]byte, error) {
+	if !c.compressRequests || len(postStr) < minCompressSize {
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(postStr); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
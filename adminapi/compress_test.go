@@ -0,0 +1,51 @@
+package adminapi
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressRequests(t *testing.T) {
+	var encoding string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		reader := io.Reader(r.Body)
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			reader = zr
+		}
+		body, _ = io.ReadAll(reader)
+
+		timestamp, _ := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+		assert.Equal(t, calcSecurityToken([]byte("tok"), timestamp, body), r.Header.Get("X-SecurityToken"),
+			"the token signs the uncompressed body")
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", CompressRequests: true})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{"hostname": "web1"})
+	_, err = q.All(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, encoding, "small bodies are sent uncompressed")
+
+	q = client.NewQuery(Filters{"hostname": Regexp(strings.Repeat("web|", 500))})
+	_, err = q.All(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "gzip", encoding)
+	assert.Contains(t, string(body), `"filters":{"hostname":{"Regexp":"web|web|`)
+}
@@ -1,15 +1,18 @@
 package adminapi
 
 import (
-	"context"
 	"crypto/rand"
 	"errors"
 	"fmt"
-	"net"
+	"io"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"golang.org/x/crypto/ssh"
 	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/term"
 )
 
 const (
@@ -17,11 +20,25 @@ const (
 	userAgent = "Adminapi Go Client " + version
 )
 
+// defaultIdentityFiles are the private keys looked up in ~/.ssh, in order, when
+// neither SERVERADMIN_KEY_PATH, an SSH agent nor SERVERADMIN_TOKEN is available.
+var defaultIdentityFiles = []string{"id_ed25519", "id_ecdsa", "id_rsa"}
+
+// envNotices receives the notices of NewClientFromEnv, like the default
+// identity file it picked.
+var envNotices io.Writer = os.Stderr
+
 // NewClientFromEnv builds a Client from the SERVERADMIN_* environment variables,
 // applying the legacy auth precedence SERVERADMIN_KEY_PATH > SSH_AUTH_SOCK >
 // SERVERADMIN_TOKEN. It is a convenience for env-configured deployments (such as
 // the CLI); prefer NewClient with an explicit Config when you control the
 // configuration, especially in multi-tenant processes.
+//
+// Without any of them, nor the platform's default SSH agent, requests are
+// signed with the first of ~/.ssh/id_ed25519, ~/.ssh/id_ecdsa and
+// ~/.ssh/id_rsa that exists. As that picks a key nobody configured, the path of
+// the key is written to stderr; set SERVERADMIN_KEY_PATH to choose the key
+// explicitly instead.
 func NewClientFromEnv() (*Client, error) {
 	cfg, err := configFromEnv()
 	if err != nil {
@@ -34,8 +51,29 @@ func NewClientFromEnv() (*Client, error) {
 //
 // This is the only place that applies the legacy ambient auth precedence:
 // SERVERADMIN_KEY_PATH > SSH_AUTH_SOCK > SERVERADMIN_TOKEN. The SSH agent
-// (SSH_AUTH_SOCK) is resolved here into a concrete ssh.Signer, as NewClient
-// itself does not consult the agent.
+// (SSH_AUTH_SOCK) is resolved here into concrete ssh.Signers, one per usable
+// agent key, as NewClient itself does not consult the agent. Requests are signed
+// with all of them, so it does not matter which key Serveradmin knows.
+//
+// Without SSH_AUTH_SOCK the platform's default agent (the OpenSSH named pipe on
+// Windows) is tried before the token, and as a last resort the first existing
+// default identity file in ~/.ssh is used, so the same binary works on
+// workstations, Windows hosts and agentless containers alike.
+//
+// SERVERADMIN_AUTH replaces all of the above with an authentication scheme
+// registered with RegisterEnvAuthenticator, e.g. "kerberos" once the
+// adminapi/kerberos package is imported.
+//
+// SERVERADMIN_VAULT_PATH takes the place of SERVERADMIN_TOKEN: the token is
+// then read from that Vault KV secret, using VAULT_ADDR and VAULT_TOKEN.
+//
+// SERVERADMIN_BASE_URL may list several comma-separated base URLs; requests
+// fail over from the first to the others when it cannot be reached.
+//
+// SERVERADMIN_DEBUG=1 dumps all requests and responses to stderr.
+//
+// Encrypted keys are decrypted with SERVERADMIN_KEY_PASSPHRASE or, when it is
+// unset and stdin is a terminal, with a passphrase prompted for interactively.
 func configFromEnv() (Config, error) {
 	cfg := Config{}
 
@@ -43,34 +81,111 @@ func configFromEnv() (Config, error) {
 	if baseURL == "" {
 		return cfg, errors.New("env var SERVERADMIN_BASE_URL not set")
 	}
-	cfg.BaseURL = baseURL
+	cfg.BaseURL, cfg.Endpoints = splitBaseURLs(baseURL)
+
+	switch protocol := os.Getenv("SERVERADMIN_COMMIT_PROTOCOL"); protocol {
+	case "", "ids":
+		cfg.CommitProtocol = CommitProtocolCurrent
+	case "objects":
+		cfg.CommitProtocol = CommitProtocolDeletedObjects
+	default:
+		return cfg, fmt.Errorf("invalid SERVERADMIN_COMMIT_PROTOCOL %q: use \"ids\" or \"objects\"", protocol)
+	}
+
+	switch algorithm := os.Getenv("SERVERADMIN_TOKEN_ALGORITHM"); algorithm {
+	case "", "auto":
+		cfg.TokenAlgorithm = TokenAlgorithmAuto
+	case "sha1":
+		cfg.TokenAlgorithm = TokenAlgorithmSHA1
+	case "sha256":
+		cfg.TokenAlgorithm = TokenAlgorithmSHA256
+	default:
+		return cfg, fmt.Errorf("invalid SERVERADMIN_TOKEN_ALGORITHM %q: use \"auto\", \"sha1\" or \"sha256\"", algorithm)
+	}
+
+	if attempts := os.Getenv("SERVERADMIN_RETRY_ATTEMPTS"); attempts != "" {
+		n, err := strconv.Atoi(attempts)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("invalid SERVERADMIN_RETRY_ATTEMPTS %q: use a positive number", attempts)
+		}
+		cfg.Retry = RetryPolicy{MaxAttempts: n, Jitter: 0.2}
+	}
+
+	if debug := os.Getenv("SERVERADMIN_DEBUG"); debug != "" && debug != "0" {
+		cfg.DebugWriter = os.Stderr
+	}
+
+	if mode := os.Getenv("SERVERADMIN_AUTH"); mode != "" {
+		newAuth, ok := envAuthenticator(mode)
+		switch {
+		case ok:
+		case mode == "kerberos":
+			return cfg, errors.New("SERVERADMIN_AUTH=kerberos requires importing github.com/innogames/serveradmin-go-client/adminapi/kerberos")
+		default:
+			return cfg, fmt.Errorf("invalid SERVERADMIN_AUTH %q: no such authentication scheme registered", mode)
+		}
+		auth, err := newAuth()
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Authenticator = auth
+		return cfg, nil
+	}
 
 	if privateKeyPath, ok := os.LookupEnv("SERVERADMIN_KEY_PATH"); ok && privateKeyPath != "" {
 		cfg.KeyPath = privateKeyPath
 	} else if authSock, ok := os.LookupEnv("SSH_AUTH_SOCK"); ok && authSock != "" {
-		signer, err := agentSigner(authSock)
+		signers, err := agentSigners(authSock)
 		if err != nil {
 			return cfg, err
 		}
-		cfg.SSHSigner = signer
+		cfg.SSHSigners = signers
+	} else if defaultSock := defaultAgentPath(); defaultSock != "" {
+		// The default agent is optional: ignore it when it is not running.
+		if signers, err := agentSigners(defaultSock); err == nil {
+			cfg.SSHSigners = signers
+		}
+	}
+
+	if cfg.KeyPath == "" && len(cfg.SSHSigners) == 0 {
+		if vaultPath := os.Getenv("SERVERADMIN_VAULT_PATH"); vaultPath != "" {
+			provider, err := NewVaultTokenProvider(VaultConfig{Path: vaultPath})
+			if err != nil {
+				return cfg, err
+			}
+			cfg.TokenProvider = provider
+		} else {
+			cfg.Token = os.Getenv("SERVERADMIN_TOKEN")
+		}
+	}
+
+	if cfg.Token == "" && cfg.TokenProvider == nil && cfg.KeyPath == "" && len(cfg.SSHSigners) == 0 {
+		cfg.KeyPath = defaultIdentityFile()
+		if cfg.KeyPath != "" {
+			fmt.Fprintf(envNotices, "serveradmin: no credentials configured, signing requests with %s\n", cfg.KeyPath)
+		}
 	}
 
-	if cfg.KeyPath == "" && cfg.SSHSigner == nil {
-		cfg.Token = os.Getenv("SERVERADMIN_TOKEN")
+	if cfg.KeyPath != "" {
+		cfg.KeyPassphrase = os.Getenv("SERVERADMIN_KEY_PASSPHRASE")
+		if cfg.KeyPassphrase == "" && term.IsTerminal(int(os.Stdin.Fd())) { //nolint:gosec // file descriptors fit into int
+			cfg.KeyPassphraseFunc = promptPassphrase(cfg.KeyPath)
+		}
 	}
 
-	if cfg.Token == "" && cfg.KeyPath == "" && cfg.SSHSigner == nil {
+	if cfg.Token == "" && cfg.TokenProvider == nil && cfg.KeyPath == "" && len(cfg.SSHSigners) == 0 {
 		return cfg, errors.New("no authentication method found: set SERVERADMIN_TOKEN/SERVERADMIN_KEY_PATH/SSH_AUTH_SOCK")
 	}
 
 	return cfg, nil
 }
 
-// agentSigner connects to the SSH agent at authSock and returns the first signer
-// that can produce a signature.
-func agentSigner(authSock string) (ssh.Signer, error) {
-	var dialer net.Dialer
-	sock, err := dialer.DialContext(context.Background(), "unix", authSock)
+// agentSigners connects to the SSH agent at authSock and returns all signers
+// that can produce a signature. Security keys are not probed, as every
+// signature would require a touch; they are returned as is and only used when
+// no other key can sign (see signMessage).
+func agentSigners(authSock string) ([]ssh.Signer, error) {
+	sock, err := dialAgent(authSock)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to SSH agent: %w", err)
 	}
@@ -78,10 +193,61 @@ func agentSigner(authSock string) (ssh.Signer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get SSH agent signers: %w", err)
 	}
+	var usable []ssh.Signer
 	for _, signer := range signers {
+		if isSecurityKey(signer.PublicKey()) {
+			usable = append(usable, signer)
+			continue
+		}
 		if _, err := signer.Sign(rand.Reader, []byte("test")); err == nil {
-			return signer, nil
+			usable = append(usable, signer)
+		}
+	}
+	if len(usable) == 0 {
+		return nil, errors.New("no usable signer found in SSH agent")
+	}
+	return usable, nil
+}
+
+// promptPassphrase returns a KeyPassphraseFunc asking for the passphrase of
+// the key at keyPath on the terminal.
+func promptPassphrase(keyPath string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		fmt.Fprintf(os.Stderr, "Enter passphrase for key %s: ", keyPath)
+		passphrase, err := term.ReadPassword(int(os.Stdin.Fd())) //nolint:gosec // file descriptors fit into int
+		fmt.Fprintln(os.Stderr)
+		return passphrase, err
+	}
+}
+
+// defaultIdentityFile returns the path of the first existing default private
+// key in ~/.ssh, or an empty string if there is none.
+func defaultIdentityFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range defaultIdentityFiles {
+		path := filepath.Join(home, ".ssh", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// splitBaseURLs splits a comma-separated SERVERADMIN_BASE_URL into the primary
+// base URL and the endpoints to fail over to.
+func splitBaseURLs(value string) (baseURL string, endpoints []string) {
+	for rawURL := range strings.SplitSeq(value, ",") {
+		if rawURL = strings.TrimSpace(rawURL); rawURL == "" {
+			continue
+		}
+		if baseURL == "" {
+			baseURL = rawURL
+		} else {
+			endpoints = append(endpoints, rawURL)
 		}
 	}
-	return nil, errors.New("no usable signer found in SSH agent")
+	return baseURL, endpoints
 }
@@ -1,11 +1,20 @@
 package adminapi
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 )
 
 func TestConfigFromEnv(t *testing.T) {
@@ -27,14 +36,14 @@ func TestConfigFromEnv(t *testing.T) {
 
 		cfg, err := configFromEnv()
 		require.NoError(t, err)
-		assert.Nil(t, cfg.SSHSigner)
+		assert.Empty(t, cfg.SSHSigners)
 		assert.Empty(t, cfg.KeyPath)
 		assert.Equal(t, "jolo", cfg.Token)
 
 		client, err := NewClient(cfg)
 		require.NoError(t, err)
-		assert.Nil(t, client.sshSigner)
-		assert.Equal(t, "jolo", string(client.authToken))
+		assert.Empty(t, sshSignersOf(client))
+		assert.Equal(t, StaticToken("jolo"), tokenProviderOf(client))
 	})
 
 	t.Run("load valid private key", func(t *testing.T) {
@@ -48,8 +57,22 @@ func TestConfigFromEnv(t *testing.T) {
 
 		client, err := NewClient(cfg)
 		require.NoError(t, err)
-		assert.NotNil(t, client.sshSigner)
-		assert.Empty(t, client.authToken)
+		assert.Len(t, sshSignersOf(client), 1)
+		assert.Nil(t, tokenProviderOf(client))
+	})
+
+	t.Run("load encrypted private key", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+		t.Setenv("SERVERADMIN_KEY_PATH", "testdata/test_encrypted.key")
+		t.Setenv("SERVERADMIN_KEY_PASSPHRASE", "test-passphrase")
+
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, "test-passphrase", cfg.KeyPassphrase)
+
+		client, err := NewClient(cfg)
+		require.NoError(t, err)
+		assert.Len(t, sshSignersOf(client), 1)
 	})
 
 	t.Run("load invalid private key", func(t *testing.T) {
@@ -64,4 +87,172 @@ func TestConfigFromEnv(t *testing.T) {
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to read private key from testdata/nope.key")
 	})
+
+	t.Run("commit protocol", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+		t.Setenv("SERVERADMIN_KEY_PATH", "")
+		t.Setenv("SERVERADMIN_TOKEN", "jolo")
+
+		t.Setenv("SERVERADMIN_COMMIT_PROTOCOL", "objects")
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, CommitProtocolDeletedObjects, cfg.CommitProtocol)
+
+		t.Setenv("SERVERADMIN_COMMIT_PROTOCOL", "bogus")
+		_, err = configFromEnv()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "SERVERADMIN_COMMIT_PROTOCOL")
+	})
+
+	t.Run("token algorithm", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+		t.Setenv("SERVERADMIN_KEY_PATH", "")
+		t.Setenv("SERVERADMIN_TOKEN", "jolo")
+
+		t.Setenv("SERVERADMIN_TOKEN_ALGORITHM", "sha256")
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, TokenAlgorithmSHA256, cfg.TokenAlgorithm)
+
+		t.Setenv("SERVERADMIN_TOKEN_ALGORITHM", "md5")
+		_, err = configFromEnv()
+		require.ErrorContains(t, err, "SERVERADMIN_TOKEN_ALGORITHM")
+	})
+
+	t.Run("token from vault", func(t *testing.T) {
+		t.Setenv("SSH_AUTH_SOCK", "")
+		t.Setenv("SERVERADMIN_KEY_PATH", "")
+		t.Setenv("SERVERADMIN_TOKEN", "jolo")
+		t.Setenv("SERVERADMIN_VAULT_PATH", "secret/data/serveradmin")
+		t.Setenv("VAULT_ADDR", "https://vault.example.com")
+		t.Setenv("VAULT_TOKEN", "vault-token")
+
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		assert.Empty(t, cfg.Token)
+		assert.IsType(t, &VaultTokenProvider{}, cfg.TokenProvider)
+	})
+
+	t.Run("load all signers from SSH agent", func(t *testing.T) {
+		_, extraKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		startTestAgent(t, extraKey)
+		t.Setenv("SERVERADMIN_KEY_PATH", "")
+
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		assert.Len(t, cfg.SSHSigners, 2)
+	})
+
+	t.Run("load signer from SSH agent", func(t *testing.T) {
+		signer := startTestAgent(t)
+		t.Setenv("SERVERADMIN_KEY_PATH", "")
+
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		require.Len(t, cfg.SSHSigners, 1)
+		assert.Equal(t, signer.PublicKey().Marshal(), cfg.SSHSigners[0].PublicKey().Marshal())
+		assert.Empty(t, cfg.Token)
+	})
+
+	t.Run("fall back to default identity file", func(t *testing.T) {
+		home := t.TempDir()
+		keyBytes, err := os.ReadFile("testdata/test.key")
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Join(home, ".ssh"), 0o700))
+		keyPath := filepath.Join(home, ".ssh", "id_ed25519")
+		require.NoError(t, os.WriteFile(keyPath, keyBytes, 0o600))
+
+		t.Setenv("HOME", home)
+		t.Setenv("SSH_AUTH_SOCK", "")
+		t.Setenv("SERVERADMIN_KEY_PATH", "")
+		t.Setenv("SERVERADMIN_TOKEN", "")
+		var notices strings.Builder
+		envNotices = &notices
+		t.Cleanup(func() { envNotices = os.Stderr })
+
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, keyPath, cfg.KeyPath)
+		assert.Contains(t, notices.String(), keyPath, "the picked key is reported")
+
+		client, err := NewClient(cfg)
+		require.NoError(t, err)
+		assert.Len(t, sshSignersOf(client), 1)
+	})
+
+	t.Run("token wins over default identity file", func(t *testing.T) {
+		home := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(home, ".ssh"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(home, ".ssh", "id_rsa"), []byte("unused"), 0o600))
+
+		t.Setenv("HOME", home)
+		t.Setenv("SSH_AUTH_SOCK", "")
+		t.Setenv("SERVERADMIN_KEY_PATH", "")
+		t.Setenv("SERVERADMIN_TOKEN", "jolo")
+
+		cfg, err := configFromEnv()
+		require.NoError(t, err)
+		assert.Empty(t, cfg.KeyPath)
+		assert.Equal(t, "jolo", cfg.Token)
+	})
+}
+
+// startTestAgent serves an in-memory SSH agent holding testdata/test.key and
+// any extra keys on a unix socket and points SSH_AUTH_SOCK at it.
+func startTestAgent(t *testing.T, extraKeys ...any) ssh.Signer {
+	t.Helper()
+
+	keyBytes, err := os.ReadFile("testdata/test.key")
+	require.NoError(t, err)
+	rawKey, err := ssh.ParseRawPrivateKey(keyBytes)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(rawKey)
+	require.NoError(t, err)
+
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: rawKey}))
+	for _, key := range extraKeys {
+		require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := (&net.ListenConfig{}).Listen(context.Background(), "unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	t.Setenv("SSH_AUTH_SOCK", socketPath)
+	return signer
+}
+
+func TestConfigFromEnvAuthMode(t *testing.T) {
+	t.Setenv("SERVERADMIN_BASE_URL", "https://example.com")
+
+	t.Setenv("SERVERADMIN_AUTH", "ldap")
+	_, err := configFromEnv()
+	require.ErrorContains(t, err, "SERVERADMIN_AUTH")
+
+	t.Setenv("SERVERADMIN_AUTH", "kerberos")
+	_, err = configFromEnv()
+	require.ErrorContains(t, err, "adminapi/kerberos", "the package is not imported")
+
+	auth := NewTokenAuthenticator(StaticToken("tok"), TokenAlgorithmAuto)
+	RegisterEnvAuthenticator("test-scheme", func() (Authenticator, error) { return auth, nil })
+	t.Setenv("SERVERADMIN_AUTH", "test-scheme")
+	cfg, err := configFromEnv()
+	require.NoError(t, err)
+	assert.Same(t, auth, cfg.Authenticator)
 }
@@ -0,0 +1,26 @@
+package adminapi
+
+import "context"
+
+// clientContextKey is the context key under which WithClient stores a Client.
+type clientContextKey struct{}
+
+// WithClient returns a copy of ctx carrying client. Queries and objects that are
+// not bound to a client fall back to the client found in the context, so
+// libraries deep in a call stack can use the caller's configured client without
+// threading it through every function signature:
+//
+//	ctx = adminapi.WithClient(ctx, client)
+//	...
+//	client, ok := adminapi.FromContext(ctx)
+func WithClient(ctx context.Context, client *Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// FromContext returns the client stored in ctx by WithClient. The boolean
+// reports whether a non-nil client was found; there is no fallback to
+// environment configuration.
+func FromContext(ctx context.Context) (*Client, bool) {
+	client, ok := ctx.Value(clientContextKey{}).(*Client)
+	return client, ok && client != nil
+}
@@ -0,0 +1,65 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientContext(t *testing.T) {
+	client := mustClient(t, "https://example.com")
+
+	got, ok := FromContext(context.Background())
+	assert.False(t, ok)
+	assert.Nil(t, got)
+
+	got, ok = FromContext(WithClient(context.Background(), nil))
+	assert.False(t, ok)
+	assert.Nil(t, got)
+
+	got, ok = FromContext(WithClient(context.Background(), client))
+	assert.True(t, ok)
+	assert.Same(t, client, got)
+}
+
+func TestContextClientFallback(t *testing.T) {
+	var commits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == apiEndpointCommit {
+			commits++
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":7}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a.local"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := WithClient(context.Background(), mustClient(t, server.URL))
+
+	// An unbound query resolves the client from the context.
+	q := Query{filters: Filters{"hostname": "a.local"}, restrictedAttributes: []string{"hostname"}}
+	servers, err := q.All(ctx)
+	require.NoError(t, err)
+	require.Len(t, servers, 1)
+
+	// So does an unbound object on commit.
+	obj := &ServerObject{
+		attributes: Attributes{"hostname": "a.local", "object_id": float64(1)},
+		oldValues:  Attributes{},
+	}
+	require.NoError(t, obj.Set("hostname", "b.local"))
+	commitID, err := obj.Commit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 7, commitID)
+	assert.Equal(t, 1, commits)
+
+	// Without a context client the unbound query fails clearly.
+	_, err = (&Query{filters: Filters{}}).All(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not bound to a client")
+}
@@ -10,7 +10,9 @@ import (
 // NewObject creates a new server object with the given attributes using this
 // client, commits it, and returns the fully populated object with a
 // server-assigned object_id. The attributes map must include "hostname".
-func (c *Client) NewObject(ctx context.Context, serverType string, attributes Attributes) (*ServerObject, error) {
+func (c *Client) NewObject(ctx context.Context, serverType string, attributes Attributes) (_ *ServerObject, err error) {
+	defer recoverPanic(c, &err)
+
 	if !attributes.Has("hostname") {
 		return nil, fmt.Errorf("attributes must include %q: %w", "hostname", ErrUnknownAttribute)
 	}
@@ -20,24 +22,10 @@ func (c *Client) NewObject(ctx context.Context, serverType string, attributes At
 		oldValues: Attributes{},
 	}
 
-	// Fetch default attributes from the API
-	params := url.Values{}
-	params.Add("servertype", serverType)
-	fullURL := apiEndpointNewObject + "?" + params.Encode()
-
-	resp, err := c.sendRequest(ctx, fullURL, nil)
+	server.attributes, err = c.newObjectDefaults(ctx, serverType)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var response struct {
-		Result Attributes `json:"result"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
-	}
-	server.attributes = response.Result
 
 	// Ensure object_id is nil so CommitState() returns "created"
 	server.attributes["object_id"] = nil
@@ -56,6 +44,7 @@ func (c *Client) NewObject(ctx context.Context, serverType string, attributes At
 
 	// Re-query to get the server-assigned object_id
 	q := c.NewQuery(Filters{"hostname": attributes["hostname"]})
+	q.IncludeRetired()
 	created, err := q.One(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("re-querying created object: %w", err)
@@ -64,3 +53,50 @@ func (c *Client) NewObject(ctx context.Context, serverType string, attributes At
 
 	return created, nil
 }
+
+// newObjectDefaults returns the default attributes of serverType, served from
+// the client's defaults cache when Config.NewObjectDefaultsTTL is set. Stale
+// cache entries are used for a while if refreshing them fails because the
+// server is unavailable, but not on other errors such as a revoked credential
+// or a deleted servertype.
+func (c *Client) newObjectDefaults(ctx context.Context, serverType string) (Attributes, error) {
+	if c.defaults == nil {
+		return c.fetchNewObjectDefaults(ctx, serverType)
+	}
+
+	cached, fresh, ok := c.defaults.get(serverType)
+	if fresh {
+		return cached, nil
+	}
+
+	attributes, err := c.fetchNewObjectDefaults(ctx, serverType)
+	if err != nil {
+		if ok && isOutage(err) {
+			return cached, nil
+		}
+		return nil, err
+	}
+	c.defaults.put(serverType, attributes)
+	return attributes, nil
+}
+
+// fetchNewObjectDefaults fetches the default attributes of serverType from the API.
+func (c *Client) fetchNewObjectDefaults(ctx context.Context, serverType string) (Attributes, error) {
+	params := url.Values{}
+	params.Add("servertype", serverType)
+	fullURL := apiEndpointNewObject + "?" + params.Encode()
+
+	resp, err := c.sendRequest(ctx, fullURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp.Body)
+
+	var response struct {
+		Result Attributes `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
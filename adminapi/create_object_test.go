@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -238,3 +239,64 @@ func TestNewObject_CommitPayload(t *testing.T) {
 	assert.Equal(t, "test.local", receivedCommit.Created[0]["hostname"])
 	assert.Equal(t, "admin", receivedCommit.Created[0]["project"])
 }
+
+func TestNewObjectDefaultsCache(t *testing.T) {
+	var defaultsCalls int
+	defaultsStatus := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case apiEndpointNewObject:
+			defaultsCalls++
+			if defaultsStatus != http.StatusOK {
+				w.WriteHeader(defaultsStatus)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status":"success","result":{"hostname":"","servertype":"vm","tags":["default"]}}`))
+		case apiEndpointCommit:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"vm.local"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", NewObjectDefaultsTTL: time.Minute})
+	require.NoError(t, err)
+	now := time.Now()
+	client.defaults.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for range 3 {
+		_, err := client.NewObject(ctx, "vm", Attributes{"hostname": "vm.local", "tags": []any{"changed"}})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, defaultsCalls, "defaults are fetched once per TTL")
+
+	cached, _, _ := client.defaults.get("vm")
+	assert.Equal(t, []any{"default"}, cached["tags"], "cached defaults must not be modified")
+
+	// expired entries are refreshed, but still used while the API is down
+	now = now.Add(2 * time.Minute)
+	defaultsStatus = http.StatusServiceUnavailable
+	_, err = client.NewObject(ctx, "vm", Attributes{"hostname": "vm.local"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, defaultsCalls)
+
+	// but not when the server rejects the request
+	defaultsStatus = http.StatusForbidden
+	_, err = client.NewObject(ctx, "vm", Attributes{"hostname": "vm.local"})
+	require.Error(t, err)
+
+	// nor once they are too old
+	defaultsStatus = http.StatusServiceUnavailable
+	now = now.Add(maxStaleDefaults)
+	_, err = client.NewObject(ctx, "vm", Attributes{"hostname": "vm.local"})
+	require.Error(t, err)
+
+	// without a cached entry the error is returned
+	_, err = client.NewObject(ctx, "hypervisor", Attributes{"hostname": "hv.local"})
+	require.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package adminapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are never written to the debug writer.
+var sensitiveHeaders = []string{
+	"Authorization",
+	"X-Application",
+	"X-SecurityToken",
+	"X-Signatures",
+}
+
+// sensitiveKeys are substrings of JSON keys whose values are redacted from
+// debug output of request and response bodies.
+var sensitiveKeys = []string{"password", "secret", "token"}
+
+// debugWriter dumps requests and responses to Config.DebugWriter.
+type debugWriter struct {
+	mu sync.Mutex // serializes dumps of concurrent requests
+	w  io.Writer
+}
+
+// dumpRequest writes the request line, headers and body of req.
+func (d *debugWriter) dumpRequest(req *http.Request, body []byte) {
+	if d == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--> %s %s\n", req.Method, req.URL)
+	writeHeaders(&buf, req.Header)
+	writeBody(&buf, body)
+	d.write(buf.Bytes())
+}
+
+// dumpResponse writes the status, headers and body of resp. The body is
+// replaced by an in-memory copy, so it can still be read by the caller.
+func (d *debugWriter) dumpResponse(resp *http.Response, endpoint string, elapsed time.Duration) {
+	if d == nil {
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<-- %s %s (%s)\n", resp.Status, endpoint, elapsed.Round(time.Millisecond))
+	writeHeaders(&buf, resp.Header)
+	if err != nil {
+		fmt.Fprintf(&buf, "\n(reading body: %v)\n", err)
+	}
+	writeBody(&buf, body)
+	d.write(buf.Bytes())
+}
+
+// dumpError writes a request failure without a response.
+func (d *debugWriter) dumpError(endpoint string, err error) {
+	if d == nil {
+		return
+	}
+	d.write(fmt.Appendf(nil, "<-- error %s: %v\n\n", endpoint, err))
+}
+
+func (d *debugWriter) write(p []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, _ = d.w.Write(p)
+}
+
+func writeHeaders(buf *bytes.Buffer, header http.Header) {
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		value := strings.Join(header[name], ", ")
+		if slices.ContainsFunc(sensitiveHeaders, func(s string) bool { return strings.EqualFold(s, name) }) {
+			value = redacted
+		}
+		fmt.Fprintf(buf, "%s: %s\n", name, value)
+	}
+}
+
+func writeBody(buf *bytes.Buffer, body []byte) {
+	buf.WriteByte('\n')
+	if len(body) > 0 {
+		buf.Write(redactBody(body))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+}
+
+// redactBody replaces the values of sensitive keys in a JSON body. Bodies that
+// are not JSON are returned unchanged.
+func redactBody(body []byte) []byte {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			lower := strings.ToLower(key)
+			if slices.ContainsFunc(sensitiveKeys, func(s string) bool { return strings.Contains(lower, s) }) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+	}
+	return v
+}
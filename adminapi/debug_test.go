@@ -0,0 +1,45 @@
+package adminapi
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugWriter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "success", "result": [{"object_id": 1, "hostname": "web1", "root_password": "hunter2"}]}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "secret-token", DebugWriter: &out})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{"hostname": "web1"})
+	q.SetAttributes("hostname", "root_password")
+	obj, err := q.One(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", obj.Get("root_password"), "the caller still sees the unredacted body")
+
+	dump := out.String()
+	assert.Contains(t, dump, "--> GET "+server.URL+"/api/dataset/query")
+	assert.Contains(t, dump, `"filters":{"hostname":"web1"}`)
+	assert.Contains(t, dump, "<-- 200 OK /api/dataset/query")
+	assert.Contains(t, dump, "X-Securitytoken: [REDACTED]")
+	assert.Contains(t, dump, `"root_password":"[REDACTED]"`)
+	assert.NotContains(t, dump, "hunter2")
+	assert.NotContains(t, dump, calcAppID([]byte("secret-token")))
+}
+
+func TestRedactBody(t *testing.T) {
+	assert.JSONEq(t,
+		`{"changed": [{"object_id": 1, "api_token": "[REDACTED]", "state": "online"}]}`,
+		string(redactBody([]byte(`{"changed": [{"object_id": 1, "api_token": "abc", "state": "online"}]}`))),
+	)
+	assert.Equal(t, "not json", string(redactBody([]byte("not json"))))
+}
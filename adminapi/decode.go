@@ -0,0 +1,194 @@
+package adminapi
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Decode stores the object's attributes in the fields of the struct target
+// points to, mapping attributes to fields with `serveradmin` tags:
+//
+//	type Server struct {
+//		Hostname string       `serveradmin:"hostname"`
+//		NumCPU   int          `serveradmin:"num_cpu"`
+//		IP       netip.Addr   `serveradmin:"intern_ip"`
+//		Verified time.Time    `serveradmin:"last_verified"`
+//		Tags     []string     `serveradmin:"tags"`
+//		Backup   *string      `serveradmin:"backup_server"`
+//	}
+//
+//	var server Server
+//	err := obj.Decode(&server)
+//
+// Values are converted like GetAs converts them. Fields without a tag, or
+// tagged "-", and fields whose attribute the object does not hold are left
+// unchanged. A value that cannot be converted fails with ErrAttributeType.
+func (s *ServerObject) Decode(target any) error {
+	if s == nil {
+		return ErrNilObject
+	}
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decoding server object: target must be a non-nil struct pointer, got %T", target)
+	}
+
+	var errs []error
+	for field, attribute := range taggedFields(v.Elem()) {
+		value, ok := s.attributes[attribute]
+		if !ok {
+			continue
+		}
+		converted, ok := convertValue(value, field.Type())
+		if !ok {
+			errs = append(errs, fmt.Errorf("attribute %q: %w: cannot convert %T %v to %s",
+				attribute, ErrAttributeType, value, value, field.Type()))
+			continue
+		}
+		field.Set(converted)
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyStruct is the counterpart of Decode: it sets the attributes mapped by
+// the `serveradmin` tags of the struct v, or the struct it points to, to the
+// field values, so objects can be changed by editing a decoded struct:
+//
+//	var server Server
+//	obj.Decode(&server)
+//	server.Tags = append(server.Tags, "web")
+//	obj.ApplyStruct(server)
+//	obj.Commit(ctx)
+//
+// Only attributes whose value differs from the field are set, comparing multi
+// attributes as sets, so unchanged fields leave no pending changes. Fields
+// whose attribute the object does not hold are skipped, as Decode leaves them
+// unchanged. Times are set as dates at midnight and RFC 3339 datetimes
+// otherwise, values implementing encoding.TextMarshaler like netip.Addr as
+// their text, and nil pointers and zero times as null.
+func (s *ServerObject) ApplyStruct(v any) (err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("applying struct: v must be a struct or struct pointer, got %T", v)
+	}
+
+	var errs []error
+	for field, attribute := range taggedFields(rv) {
+		current, ok := s.attributes[attribute]
+		if !ok {
+			continue
+		}
+		if converted, ok := convertValue(current, field.Type()); ok && sameFieldValue(converted, field) {
+			continue
+		}
+		if err := s.Set(attribute, attributeValue(field)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// attributeValue converts a struct field value to the attribute value sent
+// to Serveradmin.
+func attributeValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return attributeValue(v.Elem())
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return nil
+		}
+		return orderedFilterValue(value)
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil || len(text) == 0 {
+			return nil
+		}
+		return string(text)
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice:
+		values := make([]any, v.Len())
+		for i := range v.Len() {
+			values[i] = attributeValue(v.Index(i))
+		}
+		return values
+	default:
+		return v.Interface()
+	}
+}
+
+// sameFieldValue reports whether two values of a struct field are the same
+// attribute value: times are compared as instants and slices as sets.
+func sameFieldValue(a, b reflect.Value) bool {
+	if ta, ok := a.Interface().(time.Time); ok {
+		return ta.Equal(b.Interface().(time.Time))
+	}
+	av, bv := attributeValue(a), attributeValue(b)
+	if as, bs := toAnySlice(av), toAnySlice(bv); as != nil && bs != nil {
+		add, remove := sliceDiff(as, bs)
+		return len(add) == 0 && len(remove) == 0
+	}
+	return jsonEqual(av, bv)
+}
+
+// taggedFields yields the settable fields of the struct v that have a
+// `serveradmin` tag, with the attribute name of the tag. Embedded structs are
+// searched as well.
+func taggedFields(v reflect.Value) iter.Seq2[reflect.Value, string] {
+	return func(yield func(reflect.Value, string) bool) {
+		for i := range v.NumField() {
+			field, structField := v.Field(i), v.Type().Field(i)
+			tag, ok := structField.Tag.Lookup("serveradmin")
+			if !ok && structField.Anonymous && field.Kind() == reflect.Struct {
+				for nested, attribute := range taggedFields(field) {
+					if !yield(nested, attribute) {
+						return
+					}
+				}
+				continue
+			}
+			if !structField.IsExported() {
+				continue
+			}
+			attribute, _, _ := strings.Cut(tag, ",")
+			if attribute == "" || attribute == "-" {
+				continue
+			}
+			if !yield(field, attribute) {
+				return
+			}
+		}
+	}
+}
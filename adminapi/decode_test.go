@@ -0,0 +1,148 @@
+package adminapi
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeBase struct {
+	ObjectID int `serveradmin:"object_id"`
+}
+
+type decodedServer struct {
+	decodeBase
+	Hostname  string       `serveradmin:"hostname"`
+	NumCPU    int          `serveradmin:"num_cpu"`
+	Memory    float64      `serveradmin:"memory"`
+	Active    bool         `serveradmin:"active"`
+	IP        netip.Addr   `serveradmin:"intern_ip"`
+	Network   netip.Prefix `serveradmin:"network"`
+	Verified  time.Time    `serveradmin:"last_verified"`
+	Created   time.Time    `serveradmin:"created"`
+	Tags      []string     `serveradmin:"tags"`
+	Ports     []int        `serveradmin:"ports"`
+	Backup    *string      `serveradmin:"backup_server,omitempty"`
+	Monitor   *string      `serveradmin:"monitor"`
+	Untagged  string
+	Ignored   string `serveradmin:"-"`
+	Missing   string `serveradmin:"missing"`
+	unexposed string `serveradmin:"hostname"` //nolint:unused // must be skipped
+}
+
+func TestDecode(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id":     float64(42),
+			"hostname":      "web01",
+			"num_cpu":       float64(8),
+			"memory":        float64(65536),
+			"active":        true,
+			"intern_ip":     "10.0.0.1",
+			"network":       "10.0.0.0/16",
+			"last_verified": "2024-05-01",
+			"created":       "2024-05-01T12:30:00+02:00",
+			"tags":          []any{"web", "prod"},
+			"ports":         []any{float64(80), float64(443)},
+			"backup_server": "backup01",
+			"monitor":       nil,
+			"Untagged":      "x",
+			"Ignored":       "x",
+		},
+		oldValues: Attributes{},
+	}
+
+	server := decodedServer{Missing: "kept", Monitor: new(string)}
+	require.NoError(t, obj.Decode(&server))
+
+	backup := "backup01"
+	assert.Equal(t, decodedServer{
+		decodeBase: decodeBase{ObjectID: 42},
+		Hostname:   "web01",
+		NumCPU:     8,
+		Memory:     65536,
+		Active:     true,
+		IP:         netip.MustParseAddr("10.0.0.1"),
+		Network:    netip.MustParsePrefix("10.0.0.0/16"),
+		Verified:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Created:    time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("", 2*60*60)),
+		Tags:       []string{"web", "prod"},
+		Ports:      []int{80, 443},
+		Backup:     &backup,
+		Missing:    "kept",
+	}, server)
+}
+
+func TestDecodeErrors(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"num_cpu": 2.5, "intern_ip": "not-an-ip", "hostname": "web01"},
+		oldValues:  Attributes{},
+	}
+
+	var server decodedServer
+	err := obj.Decode(&server)
+	require.ErrorIs(t, err, ErrAttributeType)
+	assert.ErrorContains(t, err, `"num_cpu"`)
+	assert.ErrorContains(t, err, `"intern_ip"`)
+	assert.Equal(t, "web01", server.Hostname, "convertible attributes are still decoded")
+
+	require.Error(t, obj.Decode(server))
+	require.Error(t, obj.Decode((*decodedServer)(nil)))
+	var notStruct string
+	require.Error(t, obj.Decode(&notStruct))
+}
+
+func TestApplyStruct(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id":     float64(42),
+			"hostname":      "web01",
+			"num_cpu":       float64(8),
+			"memory":        float64(65536),
+			"active":        true,
+			"intern_ip":     "10.0.0.1",
+			"network":       "10.0.0.0/16",
+			"last_verified": "2024-05-01",
+			"created":       "2024-05-01T12:30:00+02:00",
+			"tags":          []any{"web", "prod"},
+			"ports":         []any{float64(80)},
+			"backup_server": "backup01",
+			"monitor":       nil,
+		},
+		oldValues: Attributes{},
+	}
+
+	var server decodedServer
+	require.NoError(t, obj.Decode(&server))
+	server.Created = server.Created.UTC()
+	server.Tags = []string{"prod", "web"}
+	require.NoError(t, obj.ApplyStruct(server))
+	assert.Equal(t, StateConsistent, obj.CommitState(), "unchanged values, reordered multis and equal instants are no changes")
+
+	server.NumCPU = 16
+	server.IP = netip.MustParseAddr("10.0.0.2")
+	server.Verified = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	server.Created = time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	server.Tags = append(server.Tags, "api")
+	server.Backup = nil
+	monitor := "mon01"
+	server.Monitor = &monitor
+	require.NoError(t, obj.ApplyStruct(&server))
+
+	changes := obj.serializeChanges()
+	delete(changes, "object_id")
+	assert.Equal(t, Attributes{
+		"num_cpu":       map[string]any{"action": "update", "old": float64(8), "new": 16},
+		"intern_ip":     map[string]any{"action": "update", "old": "10.0.0.1", "new": "10.0.0.2"},
+		"last_verified": map[string]any{"action": "update", "old": "2024-05-01", "new": "2024-06-01"},
+		"created":       map[string]any{"action": "update", "old": "2024-05-01T12:30:00+02:00", "new": "2024-06-01T08:00:00Z"},
+		"tags":          map[string]any{"action": "multi", "add": []any{"api"}, "remove": []any{}},
+		"backup_server": map[string]any{"action": "update", "old": "backup01", "new": nil},
+		"monitor":       map[string]any{"action": "update", "old": nil, "new": "mon01"},
+	}, changes)
+
+	require.Error(t, obj.ApplyStruct("not a struct"))
+}
@@ -0,0 +1,92 @@
+package adminapi
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// defaultsCache caches the servertype defaults returned by new_object per
+// servertype. Expired entries are refreshed on use, but kept as a fallback
+// for up to maxStaleDefaults in case the server is unavailable, so brief API
+// outages don't stop object creation.
+type defaultsCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]defaultsEntry
+}
+
+type defaultsEntry struct {
+	attributes Attributes
+	fetched    time.Time
+}
+
+func newDefaultsCache(ttl time.Duration) *defaultsCache {
+	return &defaultsCache{ttl: ttl, now: time.Now, entries: map[string]defaultsEntry{}}
+}
+
+// maxStaleDefaults bounds how long after expiry cached defaults are still used
+// while the API is unavailable.
+const maxStaleDefaults = 15 * time.Minute
+
+// get returns a copy of the cached defaults of serverType and whether they
+// are still fresh. ok is false if nothing is cached, or if the entry expired
+// more than maxStaleDefaults ago.
+func (c *defaultsCache) get(serverType string) (attributes Attributes, fresh, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[serverType]
+	age := c.now().Sub(entry.fetched)
+	if !ok || age >= c.ttl+maxStaleDefaults {
+		return nil, false, false
+	}
+	return cloneAttributes(entry.attributes), age < c.ttl, true
+}
+
+// put stores a copy of the defaults of serverType.
+func (c *defaultsCache) put(serverType string, attributes Attributes) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[serverType] = defaultsEntry{attributes: cloneAttributes(attributes), fetched: c.now()}
+}
+
+// cloneAttributes copies attrs including slice values, so modifying the
+// copy's multi attributes doesn't affect the original.
+func cloneAttributes(attrs Attributes) Attributes {
+	clone := maps.Clone(attrs)
+	for key, value := range clone {
+		if values, ok := value.([]any); ok {
+			clone[key] = append([]any(nil), values...)
+		}
+	}
+	return clone
+}
+
+// attributeNames returns the names of the attributes of serverType, or of
+// all cached servertypes if serverType is not cached, as far as the cached
+// defaults know them. ok is false if nothing is cached.
+func (c *defaultsCache) attributeNames(serverType string) (names map[string]bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) == 0 {
+		return nil, false
+	}
+	names = map[string]bool{"object_id": true, "hostname": true, "servertype": true}
+	add := func(entry defaultsEntry) {
+		for attribute := range entry.attributes {
+			names[attribute] = true
+		}
+	}
+	if entry, ok := c.entries[serverType]; ok {
+		add(entry)
+		return names, true
+	}
+	for _, entry := range c.entries {
+		add(entry)
+	}
+	return names, true
+}
@@ -0,0 +1,129 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ObjectDiff lists what committing an object will do, see ServerObject.Diff.
+type ObjectDiff struct {
+	ObjectID int             `json:"object_id"`
+	Hostname string          `json:"hostname"`
+	State    CommitState     `json:"state"`
+	Changes  []AttributeDiff `json:"changes"`
+}
+
+// AttributeDiff is a pending change of an attribute. Single-valued
+// attributes change from Old to New, multi attributes get the values in
+// Added and lose those in Removed, as they are committed.
+type AttributeDiff struct {
+	Attribute string `json:"attribute"`
+	Old       any    `json:"old,omitempty"`
+	New       any    `json:"new,omitempty"`
+	Added     []any  `json:"added,omitempty"`
+	Removed   []any  `json:"removed,omitempty"`
+}
+
+// Diff returns the pending changes of the object, so tools can show a review
+// of what a commit will do before it happens. Changes are ordered by
+// attribute. Created objects list all their attributes with values as
+// changes, deleted and consistent ones none.
+func (s *ServerObject) Diff() *ObjectDiff {
+	diff := &ObjectDiff{
+		ObjectID: s.ObjectID(),
+		Hostname: s.GetString("hostname"),
+		State:    s.CommitState(),
+		Changes:  []AttributeDiff{},
+	}
+
+	switch diff.State {
+	case StateCreated:
+		for _, key := range s.Keys() {
+			if value := s.attributes[key]; s.IsSet(key) {
+				diff.Changes = append(diff.Changes, AttributeDiff{Attribute: key, New: value})
+			}
+		}
+	case StateChanged:
+		for _, key := range slices.Sorted(maps.Keys(s.oldValues)) {
+			oldVal, newVal := s.oldValues[key], s.attributes[key]
+			if jsonEqual(oldVal, newVal) && !s.touched[key] {
+				continue
+			}
+			oldSlice, newSlice := toAnySlice(oldVal), toAnySlice(newVal)
+			if oldSlice != nil && newSlice != nil {
+				add, remove := sliceDiff(oldSlice, newSlice)
+				if len(add) == 0 && len(remove) == 0 {
+					add = slices.Clone(newSlice) // touched
+				}
+				diff.Changes = append(diff.Changes, AttributeDiff{
+					Attribute: key,
+					Added:     sortedByJSON(add),
+					Removed:   sortedByJSON(remove),
+				})
+			} else {
+				diff.Changes = append(diff.Changes, AttributeDiff{Attribute: key, Old: oldVal, New: newVal})
+			}
+		}
+	case StateDeleted, StateConsistent:
+		// no attribute changes
+	}
+	return diff
+}
+
+// String formats the diff for review: "+" for created, "-" for deleted and
+// "~" for changed objects, followed by one line per changed attribute:
+//
+//	~ web01 (object_id 42)
+//	    num_cpu: 8 -> 16
+//	    tags: +"api" -"old"
+func (d *ObjectDiff) String() string {
+	var sb strings.Builder
+	switch d.State {
+	case StateCreated:
+		fmt.Fprintf(&sb, "+ %s (new)\n", d.Hostname)
+	case StateDeleted:
+		fmt.Fprintf(&sb, "- %s (object_id %d)\n", d.Hostname, d.ObjectID)
+	case StateChanged:
+		fmt.Fprintf(&sb, "~ %s (object_id %d)\n", d.Hostname, d.ObjectID)
+	case StateConsistent:
+		fmt.Fprintf(&sb, "  %s (object_id %d): no changes\n", d.Hostname, d.ObjectID)
+	}
+	for _, change := range d.Changes {
+		fmt.Fprintf(&sb, "    %s\n", change)
+	}
+	return sb.String()
+}
+
+// String formats the change as "attribute: old -> new" with JSON values, or
+// for multi attributes as "attribute: +added -removed".
+func (c AttributeDiff) String() string {
+	if c.Added == nil && c.Removed == nil {
+		return fmt.Sprintf("%s: %s -> %s", c.Attribute, diffValue(c.Old), diffValue(c.New))
+	}
+	parts := []string{c.Attribute + ":"}
+	for _, value := range c.Added {
+		parts = append(parts, "+"+diffValue(value))
+	}
+	for _, value := range c.Removed {
+		parts = append(parts, "-"+diffValue(value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func diffValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// sortedByJSON sorts values by their JSON encoding, for a stable order of
+// the sets computed by sliceDiff.
+func sortedByJSON(values []any) []any {
+	slices.SortFunc(values, func(a, b any) int { return strings.Compare(diffValue(a), diffValue(b)) })
+	return values
+}
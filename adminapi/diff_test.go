@@ -0,0 +1,63 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id": float64(42),
+			"hostname":  "web01",
+			"num_cpu":   float64(8),
+			"backup":    nil,
+			"tags":      []any{"web", "old", "b"},
+		},
+		oldValues: Attributes{},
+	}
+	assert.Equal(t, &ObjectDiff{ObjectID: 42, Hostname: "web01", State: StateConsistent, Changes: []AttributeDiff{}}, obj.Diff())
+
+	require.NoError(t, obj.Set("num_cpu", 16))
+	require.NoError(t, obj.Set("backup", "backup01"))
+	require.NoError(t, obj.AddToMulti("tags", "z", "api"))
+	require.NoError(t, obj.RemoveFromMulti("tags", "old", "b"))
+	require.NoError(t, obj.Set("hostname", "web01")) // unchanged
+
+	diff := obj.Diff()
+	assert.Equal(t, []AttributeDiff{
+		{Attribute: "backup", Old: nil, New: "backup01"},
+		{Attribute: "num_cpu", Old: float64(8), New: 16},
+		{Attribute: "tags", Added: []any{"api", "z"}, Removed: []any{"b", "old"}},
+	}, diff.Changes)
+	assert.Equal(t, `~ web01 (object_id 42)
+    backup: null -> "backup01"
+    num_cpu: 8 -> 16
+    tags: +"api" +"z" -"b" -"old"
+`, diff.String())
+
+	data, err := json.Marshal(diff)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"object_id":42,"hostname":"web01","state":"changed","changes":[
+		{"attribute":"backup","new":"backup01"},
+		{"attribute":"num_cpu","old":8,"new":16},
+		{"attribute":"tags","added":["api","z"],"removed":["b","old"]}
+	]}`, string(data))
+
+	obj.Delete()
+	assert.Equal(t, "- web01 (object_id 42)\n", obj.Diff().String())
+}
+
+func TestDiffCreated(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"object_id": nil, "hostname": "new01", "tags": []any{}, "num_cpu": 4},
+		oldValues:  Attributes{},
+	}
+	assert.Equal(t, `+ new01 (new)
+    hostname: null -> "new01"
+    num_cpu: null -> 4
+`, obj.Diff().String())
+}
@@ -1,12 +1,18 @@
 package adminapi
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
 )
 
 var (
-	// ErrNoResults is returned by One() when the query matches zero objects.
+	// ErrNoResults is returned by One() and First() when the query matches zero objects.
 	ErrNoResults = errors.New("no server objects found")
 
 	// ErrMultipleResults is returned by One() when the query matches more than one object.
@@ -14,19 +20,203 @@ var (
 
 	// ErrUnknownAttribute is returned by Set() when the attribute does not exist on the object.
 	ErrUnknownAttribute = errors.New("unknown attribute")
+
+	// ErrSecurityKeyTouch is returned when signing with a FIDO2 security key
+	// (ed25519-sk, ecdsa-sk) fails because the touch confirmation was not
+	// given in time.
+	ErrSecurityKeyTouch = errors.New("security key touch confirmation timed out")
+
+	// ErrSoftDeleteNotConfigured is returned by Retire() and Unretire() when the
+	// object's client has no SoftDelete convention configured.
+	ErrSoftDeleteNotConfigured = errors.New("soft-delete convention not configured")
+
+	// ErrCommitTooLarge is returned by Commit() when the commit contains more
+	// objects than the server announced it accepts (see Client.Limits).
+	ErrCommitTooLarge = errors.New("commit too large")
+
+	// ErrCircuitOpen is returned without sending a request while the client's
+	// circuit breaker is open after consecutive failures.
+	ErrCircuitOpen = errors.New("circuit breaker open: serveradmin is failing")
+
+	// ErrTimeout is wrapped into errors of requests that did not get a
+	// response in time, be it by the context deadline, Config.Timeout or a
+	// network timeout. Such requests may still have been applied.
+	ErrTimeout = errors.New("serveradmin request timed out")
+
+	// ErrQueryTimeout is wrapped into the errors of queries that ran out of
+	// the time set with Query.WithTimeout, in addition to ErrTimeout.
+	ErrQueryTimeout = errors.New("serveradmin query timed out")
+
+	// ErrUnreachable is wrapped into errors of requests that could not be sent
+	// because Serveradmin could not be reached, e.g. on DNS failures or
+	// refused connections. Such requests have not been applied.
+	ErrUnreachable = errors.New("serveradmin unreachable")
+
+	// ErrRateLimited is wrapped around the APIError of a request that was still
+	// rejected with 429 Too Many Requests after waiting for the server's
+	// Retry-After delay several times, or that asked for too long a delay.
+	ErrRateLimited = errors.New("serveradmin rate limit exceeded")
+
+	// ErrQueryUnion is returned by ParseQuery for queries whose alternatives
+	// differ in more than one attribute, which cannot be expressed as one
+	// Filters map. FromQuery and ParseUnion support them.
+	ErrQueryUnion = errors.New("query alternatives cannot be combined into one filter set")
+
+	// ErrFilterConflict is returned by Filters.Merge with MergeErrorOnConflict
+	// when both sides filter an attribute differently.
+	ErrFilterConflict = errors.New("conflicting filters")
+
+	// ErrAttributeType is returned by GetAs when an attribute value cannot be
+	// converted to the requested type without losing information, and by the
+	// multi attribute helpers like AddToMulti for single-valued attributes.
+	ErrAttributeType = errors.New("attribute value has a different type")
+
+	// ErrRefreshConflict is returned by ServerObject.Refresh when an
+	// attribute with a pending local change was changed differently on the
+	// server.
+	ErrRefreshConflict = errors.New("attribute changed locally and on the server")
+
+	// ErrInvalidValue is wrapped into the errors of Set when the validator
+	// returned by SchemaValidator rejects a value.
+	ErrInvalidValue = errors.New("invalid attribute value")
+
+	// ErrNotConfirmed is returned by Query.DeleteAll() when its confirm
+	// callback declined deleting the matching objects.
+	ErrNotConfirmed = errors.New("deletion not confirmed")
+
+	// ErrUncommittedChanges is returned by PlanRename() and Rename() when the
+	// object to rename has changes that are not committed yet.
+	ErrUncommittedChanges = errors.New("object has uncommitted changes")
+
+	// ErrNilObject is returned by the methods of ServerObject that modify or
+	// load an object when called on a nil *ServerObject.
+	ErrNilObject = errors.New("nil server object")
 )
 
+// sendError marks errors of requests that were sent but got no response, as
+// opposed to errors building, signing or authenticating the request.
+type sendError struct {
+	err error
+}
+
+func (e *sendError) Error() string { return e.err.Error() }
+
+func (e *sendError) Unwrap() error { return e.err }
+
+// isTransient reports whether err is a network failure of a sent request that
+// may well succeed when repeated: a timeout, a refused or reset connection. TLS
+// failures, malformed responses and errors obtaining credentials or signing
+// are not.
+func isTransient(err error) bool {
+	var sendErr *sendError
+	if !errors.As(err, &sendErr) {
+		return false
+	}
+	return classifyNetworkError(sendErr.err) != nil ||
+		errors.Is(sendErr.err, syscall.ECONNRESET) ||
+		errors.Is(sendErr.err, io.ErrUnexpectedEOF) ||
+		errors.Is(sendErr.err, io.EOF)
+}
+
+// isOutage reports whether err means the server is unavailable rather than
+// rejecting the request: a 5xx response, a transient network failure or an
+// open circuit breaker.
+func isOutage(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return isTransient(err) || errors.Is(err, ErrCircuitOpen)
+}
+
+// classifyNetworkError returns ErrTimeout or ErrUnreachable for errors of
+// requests that got no response, or nil if err is neither.
+func classifyNetworkError(err error) error {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var opErr *net.OpError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	case errors.As(err, &dnsErr), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EHOSTUNREACH),
+		errors.Is(err, syscall.ENETUNREACH), errors.As(err, &opErr) && opErr.Op == "dial":
+		return ErrUnreachable
+	default:
+		return nil
+	}
+}
+
+// PanicError is returned instead of panicking by the client's public entry
+// points when Config.RecoverPanics is set. It carries the recovered value and
+// the stack trace at the point of recovery.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("recovered from panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error, so errors.Is/As see through it.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ParseError is returned by ParseQuery and FromQuery for malformed query
+// strings. It locates the problem, so interactive tools can highlight it.
+type ParseError struct {
+	Query   string // the parsed query string
+	Offset  int    // byte offset of the problem in Query
+	Token   string // the offending token, empty at the end of the query
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s at offset %d", e.Message, e.Offset)
+	}
+	return fmt.Sprintf("%s at offset %d: %s", e.Message, e.Offset, e.Token)
+}
+
+// Caret renders the query with a caret marking the problem below it:
+//
+//	hostname=any(web1 web2
+//	            ^
+func (e *ParseError) Caret() string {
+	var indent strings.Builder
+	for _, r := range e.Query[:min(e.Offset, len(e.Query))] {
+		if r == '\t' {
+			indent.WriteRune(r) // keeps the alignment of tabs
+		} else {
+			indent.WriteByte(' ')
+		}
+	}
+	return e.Query + "\n" + indent.String() + "^"
+}
+
 // APIError represents an HTTP error response from the Serveradmin API.
 // Use errors.As() to inspect status codes and messages from API failures.
 type APIError struct {
 	StatusCode int
 	Status     string
 	Message    string
+	// RequestID is the X-Request-ID the request was sent with, to find it in
+	// the Serveradmin server logs.
+	RequestID string
+	// RetryAfter is the delay the server asked for in the Retry-After header
+	// of a 429 response, zero if it did not send one.
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
+	msg := fmt.Sprintf("HTTP error %d %s", e.StatusCode, e.Status)
 	if e.Message != "" {
-		return fmt.Sprintf("HTTP error %d %s: %s", e.StatusCode, e.Status, e.Message)
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (request id " + e.RequestID + ")"
 	}
-	return fmt.Sprintf("HTTP error %d %s", e.StatusCode, e.Status)
+	return msg
 }
@@ -0,0 +1,68 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	t.Run("context deadline", func(t *testing.T) {
+		client := mustClient(t, server.URL)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		q := client.NewQuery(Filters{})
+		_, err := q.All(ctx)
+		require.ErrorIs(t, err, ErrTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrUnreachable)
+	})
+
+	t.Run("config timeout", func(t *testing.T) {
+		client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", Timeout: 20 * time.Millisecond})
+		require.NoError(t, err)
+
+		q := client.NewQuery(Filters{})
+		_, err = q.All(context.Background())
+		require.ErrorIs(t, err, ErrTimeout)
+		assert.NotErrorIs(t, err, ErrUnreachable)
+	})
+}
+
+func TestErrUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	client := mustClient(t, "http://"+addr)
+	q := client.NewQuery(Filters{})
+	_, err = q.All(context.Background())
+	require.ErrorIs(t, err, ErrUnreachable)
+	assert.NotErrorIs(t, err, ErrTimeout)
+}
+
+func TestClassifyNetworkError(t *testing.T) {
+	assert.Equal(t, ErrUnreachable, classifyNetworkError(&net.DNSError{Err: "no such host", Name: "serveradmin.invalid", IsNotFound: true}))
+	assert.Equal(t, ErrTimeout, classifyNetworkError(&net.DNSError{Err: "i/o timeout", IsTimeout: true}))
+	assert.NoError(t, classifyNetworkError(context.Canceled))
+	assert.NoError(t, classifyNetworkError(errors.New("malformed HTTP response")))
+}
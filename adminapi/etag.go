@@ -0,0 +1,139 @@
+package adminapi
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// etagCacheBytes bounds the total size of the query results kept for
+// conditional requests. Results larger than that are not cached.
+const etagCacheBytes = 64 << 20
+
+// etagCache keeps the last result of each query payload with its ETag, so
+// repeated queries are sent with If-None-Match and a 304 Not Modified answer
+// is served from memory. The least recently used results are evicted once
+// the cache exceeds etagCacheBytes.
+type etagCache struct {
+	maxBytes int
+
+	mu      sync.Mutex
+	size    int                      // bytes of all cached keys and bodies
+	entries map[string]*list.Element // of *etagEntry
+	lru     list.List                // most recently used first
+}
+
+type etagEntry struct {
+	key  string
+	etag string
+	body []byte
+}
+
+func (e *etagEntry) size() int {
+	return len(e.key) + len(e.body)
+}
+
+func newETagCache() *etagCache {
+	return &etagCache{maxBytes: etagCacheBytes, entries: map[string]*list.Element{}}
+}
+
+// prepare sets If-None-Match on req if a result for payload is cached.
+func (e *etagCache) prepare(req *http.Request, payload []byte) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if elem, ok := e.entries[string(payload)]; ok {
+		e.lru.MoveToFront(elem)
+		req.Header.Set("If-None-Match", elem.Value.(*etagEntry).etag)
+	}
+}
+
+// response replaces a 304 Not Modified response with the cached result for
+// payload and caches successful responses carrying an ETag. The body is read
+// without holding the lock, so a slow response doesn't hold up other queries.
+func (e *etagCache) response(resp *http.Response, payload []byte) *http.Response {
+	if e == nil {
+		return resp
+	}
+
+	key := string(payload)
+	switch etag := resp.Header.Get("ETag"); {
+	case resp.StatusCode == http.StatusNotModified:
+		body, ok := e.lookup(key)
+		if !ok {
+			return resp
+		}
+
+		resp.Body.Close()
+		cached := *resp
+		cached.StatusCode = http.StatusOK
+		cached.Status = "200 OK"
+		cached.Body = io.NopCloser(bytes.NewReader(body))
+		cached.ContentLength = int64(len(body))
+		return &cached
+	case resp.StatusCode == http.StatusOK && etag != "":
+		if resp.ContentLength > int64(e.maxBytes) {
+			e.store(key, nil)
+			return resp
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			e.store(key, nil)
+			return resp
+		}
+		e.store(key, &etagEntry{key: key, etag: etag, body: body})
+	}
+	return resp
+}
+
+// lookup returns the cached body for key, marking it as recently used.
+func (e *etagCache) lookup(key string) ([]byte, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	elem, ok := e.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e.lru.MoveToFront(elem)
+	return elem.Value.(*etagEntry).body, true
+}
+
+// store replaces the cached result for key with entry, or drops it if entry
+// is nil.
+func (e *etagCache) store(key string, entry *etagEntry) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.remove(key)
+	if entry != nil {
+		e.add(entry)
+	}
+}
+
+// add caches entry, evicting the least recently used entries to make room.
+func (e *etagCache) add(entry *etagEntry) {
+	if entry.size() > e.maxBytes {
+		return
+	}
+	for e.size+entry.size() > e.maxBytes {
+		e.remove(e.lru.Back().Value.(*etagEntry).key)
+	}
+	e.entries[entry.key] = e.lru.PushFront(entry)
+	e.size += entry.size()
+}
+
+func (e *etagCache) remove(key string) {
+	if elem, ok := e.entries[key]; ok {
+		e.size -= elem.Value.(*etagEntry).size()
+		e.lru.Remove(elem)
+		delete(e.entries, key)
+	}
+}
@@ -0,0 +1,94 @@
+package adminapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionalQueries(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"web1"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", ConditionalQueries: true})
+	require.NoError(t, err)
+
+	for range 2 {
+		q := client.NewQuery(Filters{"hostname": "web1"})
+		obj, err := q.One(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "web1", obj.GetString("hostname"))
+	}
+	assert.Equal(t, []string{"", `"v1"`}, ifNoneMatch)
+
+	q := client.NewQuery(Filters{"hostname": "web2"})
+	_, err = q.All(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, ifNoneMatch[2], "other queries are cached separately")
+}
+
+func TestConditionalQueriesDisabled(t *testing.T) {
+	var ifNoneMatch []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = append(ifNoneMatch, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	for range 2 {
+		q := client.NewQuery(Filters{})
+		_, err := q.All(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"", ""}, ifNoneMatch)
+}
+
+func TestETagCacheEviction(t *testing.T) {
+	cache := newETagCache()
+	cache.maxBytes = 20
+
+	respond := func(payload, body string) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Etag": []string{`"` + payload + `"`}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		resp = cache.response(resp, []byte(payload))
+		got, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, body, string(got))
+	}
+	cached := func(payload string) bool {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		cache.prepare(req, []byte(payload))
+		return req.Header.Get("If-None-Match") != ""
+	}
+
+	respond("a", "123456")
+	respond("b", "123456")
+	assert.True(t, cached("a")) // a is now the most recently used
+	respond("c", "123456")
+	assert.True(t, cached("a"))
+	assert.False(t, cached("b"), "the least recently used entry is evicted")
+	assert.True(t, cached("c"))
+	assert.LessOrEqual(t, cache.size, cache.maxBytes)
+
+	respond("d", strings.Repeat("x", 30))
+	assert.False(t, cached("d"), "results larger than the cache are not kept")
+}
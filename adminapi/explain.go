@@ -0,0 +1,174 @@
+package adminapi
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Explain returns a human-readable breakdown of the query for debugging
+// unexpected or empty results: its filter tree, the attributes it fetches,
+// ordering and paging, followed by warnings about common mistakes, such as a
+// regexp without any regexp syntax, which matches all values containing it
+// rather than only the value itself, or a filter on an attribute unknown to
+// the servertypes in the client's defaults cache (see
+// Config.NewObjectDefaultsTTL). It sends no request.
+func (q *Query) Explain() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "query: %s\n", q.QueryString())
+
+	var warnings []string
+	alternatives := q.alternativeFilters()
+	for i, filters := range alternatives {
+		if len(alternatives) > 1 {
+			fmt.Fprintf(&sb, "alternative %d:\n", i+1)
+		} else {
+			sb.WriteString("filters:\n")
+		}
+		if len(filters) == 0 {
+			sb.WriteString("  none, all objects match\n")
+		}
+		for _, attribute := range slices.Sorted(maps.Keys(filters)) {
+			explainFilter(&sb, attribute+": ", filters[attribute], 1, false)
+			warnings = append(warnings, lintFilter(attribute, filters[attribute])...)
+		}
+		warnings = append(warnings, q.lintAttributes(filters)...)
+	}
+
+	fmt.Fprintf(&sb, "attributes: %s\n", strings.Join(q.restrictedAttributes, ", "))
+	for _, related := range q.relatedAttributes {
+		fmt.Fprintf(&sb, "related %s: %s\n", related.attribute, strings.Join(related.attributes, ", "))
+	}
+	if len(q.orderBy) > 0 {
+		keys := make([]string, len(q.orderBy))
+		for i, key := range q.orderBy {
+			keys[i] = key.attribute
+			if key.direction == Desc {
+				keys[i] += " desc"
+			}
+		}
+		fmt.Fprintf(&sb, "order by: %s\n", strings.Join(keys, ", "))
+	}
+	if q.limit > 0 {
+		fmt.Fprintf(&sb, "limit: %d\n", q.limit)
+	}
+	if q.offset > 0 {
+		fmt.Fprintf(&sb, "offset: %d\n", q.offset)
+	}
+	if q.timeout > 0 {
+		fmt.Fprintf(&sb, "timeout: %s\n", q.timeout)
+	}
+	if q.includeRetired {
+		sb.WriteString("including retired objects\n")
+	}
+
+	if len(warnings) > 0 {
+		sb.WriteString("warnings:\n")
+		seen := make(map[string]bool, len(warnings))
+		for _, warning := range warnings {
+			if !seen[warning] {
+				seen[warning] = true
+				fmt.Fprintf(&sb, "  - %s\n", warning)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// explainFilter writes a filter or value as a line at the given depth,
+// followed by the arguments of filter functions one level deeper.
+func explainFilter(sb *strings.Builder, label string, value any, depth int, raw bool) {
+	indent := strings.Repeat("  ", depth)
+	filter, ok := value.(Filter)
+	if m, isMap := value.(map[string]any); isMap {
+		filter, ok = m, true
+	}
+	if !ok {
+		sb.WriteString(indent + label)
+		writeFilterValue(sb, value, raw)
+		sb.WriteByte('\n')
+		return
+	}
+	for _, name := range slices.Sorted(maps.Keys(filter)) {
+		sb.WriteString(indent + label + strings.ToLower(name) + "\n")
+		for _, arg := range filterArgs(filter[name]) {
+			explainFilter(sb, "", arg, depth+1, name == "Regexp")
+		}
+	}
+}
+
+// filterArgs returns the arguments of a filter function: the elements of a
+// list, none for nil or the value itself.
+func filterArgs(value any) []any {
+	if value == nil {
+		return nil
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice {
+		args := make([]any, v.Len())
+		for i := range args {
+			args[i] = v.Index(i).Interface()
+		}
+		return args
+	}
+	return []any{value}
+}
+
+// lintFilter returns warnings about common mistakes in the filter of an
+// attribute.
+func lintFilter(attribute string, value any) []string {
+	filter, ok := value.(Filter)
+	if m, isMap := value.(map[string]any); isMap {
+		filter, ok = m, true
+	}
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	for _, name := range slices.Sorted(maps.Keys(filter)) {
+		args := filterArgs(filter[name])
+		pattern, isString := filter[name].(string)
+		switch {
+		case name == "Regexp" && isString:
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: invalid regexp %q: %v", attribute, pattern, err))
+			} else if literal, complete := re.LiteralPrefix(); complete {
+				warnings = append(warnings, fmt.Sprintf(
+					"%s: regexp %q contains no regexp syntax, so it matches every value containing it; use %s for an exact match",
+					attribute, pattern, Filters{attribute: literal}.Format()))
+			}
+		case name == "Any" && len(args) == 0:
+			warnings = append(warnings, attribute+": any() without values matches no objects")
+		}
+		for _, arg := range args {
+			warnings = append(warnings, lintFilter(attribute, arg)...)
+		}
+	}
+	return warnings
+}
+
+// lintAttributes warns about filtered attributes that none of the
+// servertypes in the client's defaults cache has, or not the filtered
+// servertype if it is cached.
+func (q *Query) lintAttributes(filters Filters) []string {
+	if q.client == nil || q.client.defaults == nil {
+		return nil
+	}
+	serverType, _ := filters["servertype"].(string)
+	names, ok := q.client.defaults.attributeNames(serverType)
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	for _, attribute := range slices.Sorted(maps.Keys(filters)) {
+		if !names[attribute] {
+			warnings = append(warnings, attribute+": unknown attribute, not found in the cached servertype defaults")
+		}
+	}
+	return warnings
+}
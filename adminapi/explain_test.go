@@ -0,0 +1,61 @@
+package adminapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryExplain(t *testing.T) {
+	client, err := NewClient(Config{BaseURL: "https://serveradmin.example.com", Token: "test-token", NewObjectDefaultsTTL: time.Hour})
+	require.NoError(t, err)
+
+	q, err := client.FromQuery(`hostname=regexp(web01) game_world=any(1 2) os=not(empty()) servertype=vm`)
+	require.NoError(t, err)
+	q.AddAttributes("num_cpu")
+	q.OrderBy("num_cpu", Desc)
+	q.Limit(10)
+
+	assert.Equal(t, `query: game_world=any(1 2) hostname=regexp(web01) os=not(empty()) servertype=vm
+filters:
+  game_world: any
+    1
+    2
+  hostname: regexp
+    web01
+  os: not
+    empty
+  servertype: vm
+attributes: object_id, hostname, num_cpu
+order by: num_cpu desc
+limit: 10
+warnings:
+  - hostname: regexp "web01" contains no regexp syntax, so it matches every value containing it; use hostname=web01 for an exact match
+`, q.Explain())
+
+	client.defaults.put("vm", Attributes{"os": "bookworm", "num_cpu": 1})
+	assert.Contains(t, q.Explain(), "  - game_world: unknown attribute, not found in the cached servertype defaults\n")
+	assert.NotContains(t, q.Explain(), "os: unknown attribute")
+}
+
+func TestQueryExplainWarnings(t *testing.T) {
+	tests := []struct {
+		query   string
+		warning string
+	}{
+		{`hostname=regexp("web[")`, "hostname: invalid regexp"},
+		{`hostname=regexp(web\ 01)`, `use hostname="web 01" for an exact match`},
+		{"game_world=any()", "game_world: any() without values matches no objects"},
+		{"hostname=not(regexp(db))", `hostname: regexp "db" contains no regexp syntax`},
+	}
+	for _, tt := range tests {
+		query, err := mustClient(t, "https://serveradmin.example.com").FromQuery(tt.query)
+		require.NoError(t, err)
+		assert.Contains(t, query.Explain(), tt.warning, tt.query)
+	}
+
+	union := mustClient(t, "https://serveradmin.example.com").NewUnionQuery(Filters{"env": "staging"}, Filters{"state": "online"})
+	assert.Contains(t, union.Explain(), "alternative 1:\n  env: staging\nalternative 2:\n  state: online\n")
+}
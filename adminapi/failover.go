@@ -0,0 +1,71 @@
+package adminapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// endpointRetryAfter is how long an unreachable endpoint is tried only after
+// all others.
+const endpointRetryAfter = 30 * time.Second
+
+// endpoints tracks the health of the base URLs of a Client configured with
+// Config.Endpoints. Requests go to the first healthy one in configured order;
+// one that could not be reached is skipped for endpointRetryAfter, but still
+// tried as a last resort.
+type endpoints struct {
+	urls []string
+	now  func() time.Time
+
+	mu        sync.Mutex
+	downUntil []time.Time // zero while healthy
+}
+
+// newEndpoints returns the failover state for primary followed by the extra
+// base URLs, rejecting unix socket URLs, which need a dedicated transport.
+func newEndpoints(primary string, extra []string) (*endpoints, error) {
+	urls := []string{primary}
+	for _, rawURL := range extra {
+		if strings.HasPrefix(rawURL, "unix:") {
+			return nil, fmt.Errorf("config: endpoint %q: unix sockets do not support failover", rawURL)
+		}
+		urls = append(urls, strings.TrimSuffix(rawURL, "/api"))
+	}
+	return &endpoints{urls: urls, now: time.Now, downUntil: make([]time.Time, len(urls))}, nil
+}
+
+// order returns the indexes of the endpoints in the order to try them:
+// healthy ones first, then those recently found unreachable.
+func (e *endpoints) order() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	healthy := make([]int, 0, len(e.urls))
+	var down []int
+	for i, until := range e.downUntil {
+		if now.Before(until) {
+			down = append(down, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	return append(healthy, down...)
+}
+
+// record updates the health of endpoint i with the outcome of a request:
+// it is marked down if it could not be reached and healthy once it responds.
+func (e *endpoints) record(i int, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch {
+	case errors.Is(err, ErrUnreachable):
+		e.downUntil[i] = e.now().Add(endpointRetryAfter)
+	case err == nil, errors.As(err, new(*APIError)):
+		e.downUntil[i] = time.Time{}
+	}
+}
@@ -0,0 +1,87 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailover(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	downURL := down.URL
+	down.Close() // connections are refused from now on
+
+	var calls int
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer up.Close()
+
+	client, err := NewClient(Config{BaseURL: downURL, Endpoints: []string{up.URL + "/api"}, Token: "tok"})
+	require.NoError(t, err)
+	now := time.Now()
+	client.endpoints.now = func() time.Time { return now }
+
+	q := client.NewQuery(Filters{"hostname": "a.local"})
+	_, err = q.All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{downURL, up.URL}, client.endpoints.urls)
+	assert.Equal(t, []int{1, 0}, client.endpoints.order(), "the unreachable endpoint is tried last")
+
+	now = now.Add(endpointRetryAfter)
+	assert.Equal(t, []int{0, 1}, client.endpoints.order(), "the unreachable endpoint is tried again")
+}
+
+func TestFailoverAllUnreachable(t *testing.T) {
+	var urls []string
+	for range 2 {
+		server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		urls = append(urls, server.URL)
+		server.Close()
+	}
+
+	client, err := NewClient(Config{BaseURL: urls[0], Endpoints: urls[1:], Token: "tok"})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{"hostname": "a.local"})
+	_, err = q.All(context.Background())
+	require.ErrorIs(t, err, ErrUnreachable)
+}
+
+func TestFailoverRecord(t *testing.T) {
+	e, err := newEndpoints("https://a", []string{"https://b"})
+	require.NoError(t, err)
+
+	e.record(0, &sendError{ErrTimeout})
+	assert.Equal(t, []int{0, 1}, e.order(), "timeouts may have reached the server")
+
+	e.record(0, &sendError{ErrUnreachable})
+	assert.Equal(t, []int{1, 0}, e.order())
+
+	e.record(0, &APIError{StatusCode: http.StatusInternalServerError})
+	assert.Equal(t, []int{0, 1}, e.order(), "any response marks the endpoint healthy")
+}
+
+func TestFailoverConfig(t *testing.T) {
+	_, err := NewClient(Config{BaseURL: "https://a", Endpoints: []string{"unix:///run/sa.sock"}, Token: "tok"})
+	require.Error(t, err)
+
+	_, err = NewClient(Config{BaseURL: "unix:///run/sa.sock", Endpoints: []string{"https://b"}, Token: "tok"})
+	require.Error(t, err)
+
+	baseURL, endpoints := splitBaseURLs("https://a, https://b,,https://c")
+	assert.Equal(t, "https://a", baseURL)
+	assert.Equal(t, []string{"https://b", "https://c"}, endpoints)
+
+	baseURL, endpoints = splitBaseURLs("https://a")
+	assert.Equal(t, "https://a", baseURL)
+	assert.Nil(t, endpoints)
+}
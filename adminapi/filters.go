@@ -1,5 +1,18 @@
 package adminapi
 
+import (
+	"fmt"
+	"maps"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
 type (
 	// Filters maps attribute names to filter values or Filter objects.
 	// Used as the top-level query predicate: Filters{"hostname": Regexp("web.*"), "state": "online"}.
@@ -17,22 +30,83 @@ type valueOrFilter interface {
 	value | Filter
 }
 
-// list of all valid functions with lowercased key
-var allFilters = map[string]string{
-	"any":                 "Any",
-	"all":                 "All",
-	"containedby":         "ContainedBy",
-	"containedonlyby":     "ContainedOnlyBy",
-	"contains":            "Contains",
-	"empty":               "Empty",
-	"greaterthan":         "GreaterThan",
-	"greaterthanorequals": "GreaterThanOrEquals",
-	"lessthan":            "LessThan",
-	"lessthanorequals":    "LessThanOrEquals",
-	"not":                 "Not",
-	"overlaps":            "Overlaps",
-	"regexp":              "Regexp",
-	"startswith":          "StartsWith",
+// orderedValue is accepted by the comparison filters: int64 for large IDs,
+// float64 for attributes like load or disk_usage.
+type orderedValue interface {
+	int | int64 | float64 | time.Time
+}
+
+// networkValueOrFilter additionally accepts parsed addresses and networks,
+// which are sent in their string form.
+type networkValueOrFilter interface {
+	valueOrFilter | netip.Addr | netip.Prefix
+}
+
+// allFilters lists all valid functions with lowercased key, guarded by
+// filtersMu as RegisterFilter adds to it.
+var (
+	filtersMu  sync.RWMutex
+	allFilters = map[string]string{
+		"any":                 "Any",
+		"all":                 "All",
+		"containedby":         "ContainedBy",
+		"containedonlyby":     "ContainedOnlyBy",
+		"contains":            "Contains",
+		"empty":               "Empty",
+		"greaterthan":         "GreaterThan",
+		"greaterthanorequals": "GreaterThanOrEquals",
+		"lessthan":            "LessThan",
+		"lessthanorequals":    "LessThanOrEquals",
+		"not":                 "Not",
+		"overlaps":            "Overlaps",
+		"regexp":              "Regexp",
+		"startswith":          "StartsWith",
+	}
+)
+
+// RegisterFilter makes a filter function introduced on the server known to
+// ParseQuery before the client ships a constructor for it, e.g.
+// RegisterFilter("IContains") to accept "hostname=icontains(web)". Build the
+// filter in Go with RawFilter. The name is the function's name as the server
+// expects it; registering a name again has no effect. It panics if the name
+// is not a word of letters and digits.
+func RegisterFilter(name string) {
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		panic(fmt.Sprintf("adminapi: invalid filter name %q", name))
+	}
+	filtersMu.Lock()
+	defer filtersMu.Unlock()
+	allFilters[strings.ToLower(name)] = name
+}
+
+// RawFilter creates a filter of any function the server knows, e.g. one
+// added after this client version, as RawFilter("IContains", "web"). To also
+// use it in query strings, register it with RegisterFilter.
+func RawFilter(name string, value any) Filter {
+	return createFilter(name, value)
+}
+
+// lookupFilter returns the name of the filter function called name in any
+// case.
+func lookupFilter(name string) (string, bool) {
+	filtersMu.RLock()
+	defer filtersMu.RUnlock()
+	canonical, ok := allFilters[strings.ToLower(name)]
+	return canonical, ok
+}
+
+// filterNames returns the lowercased names of all filter functions, sorted.
+func filterNames() []string {
+	filtersMu.RLock()
+	defer filtersMu.RUnlock()
+	return slices.Sorted(maps.Keys(allFilters))
+}
+
+// isFilterName reports whether name is the canonical name of a filter
+// function, like "Regexp".
+func isFilterName(name string) bool {
+	canonical, ok := lookupFilter(name)
+	return ok && canonical == name
 }
 
 // Not creates a filter that negates the given filter or value. For example, Not(2) means "!= 2".
@@ -60,54 +134,226 @@ func All[V valueOrFilter](values ...V) Filter {
 	return createFilter("All", values)
 }
 
+// Or matches if the attribute matches any of the given values or filters,
+// like Any, but takes a mix of both, e.g. Or("online", Regexp("deploy_.*")).
+// time.Time, netip.Addr and netip.Prefix values are sent like by the
+// comparison and network filters.
+func Or(values ...any) Filter {
+	return createFilter("Any", mixedValues(values))
+}
+
+// And matches if the attribute matches all of the given values or filters,
+// like All, but takes a mix of both, see Or.
+func And(values ...any) Filter {
+	return createFilter("All", mixedValues(values))
+}
+
+// mixedValues converts the values of Or and And to what is sent.
+func mixedValues(values []any) []any {
+	converted := make([]any, len(values))
+	for i, value := range values {
+		converted[i] = networkValue(orderedFilterValue(value))
+	}
+	return converted
+}
+
 // Regexp matches the attribute value against the given regular expression pattern.
 func Regexp(value string) Filter {
 	return createFilter("Regexp", value)
 }
 
+// IRegexp matches the attribute value against the given regular expression
+// pattern ignoring case, by prefixing it with the (?i) flag. The pattern is
+// compiled client-side, so an invalid one fails here rather than with a
+// server error.
+func IRegexp(pattern string) (Filter, error) {
+	pattern = "(?i)" + pattern
+	if _, err := regexp.Compile(pattern); err != nil {
+		return nil, fmt.Errorf("invalid regexp %q: %w", pattern, err)
+	}
+	return Regexp(pattern), nil
+}
+
+// Glob matches the attribute value against a shell-style glob pattern like
+// "web-*.prod.example.com", in which "*" matches any number of characters,
+// "?" a single one and "[ab]" or "[!ab]" one of or none of the enclosed
+// characters. Unlike a Regexp, it matches the whole value, and "." is just a
+// dot: it compiles to the anchored Regexp("^web-.*\.prod\.example\.com$").
+func Glob(pattern string) Filter {
+	return Regexp(globRegexp(pattern))
+}
+
+// globRegexp translates a glob pattern to an anchored regular expression.
+func globRegexp(pattern string) string {
+	var sb strings.Builder
+	sb.WriteByte('^')
+	for i := 0; i < len(pattern); {
+		r, size := utf8.DecodeRuneInString(pattern[i:])
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteByte('.')
+		case '[':
+			if class, ok := globClass(pattern[i:]); ok {
+				sb.WriteString(class.regexp)
+				i += class.length
+				continue
+			}
+			sb.WriteString(`\[`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+size]))
+		}
+		i += size
+	}
+	sb.WriteByte('$')
+	return sb.String()
+}
+
+// globCharClass is a translated character class of a glob pattern.
+type globCharClass struct {
+	regexp string
+	length int // of the class in the glob pattern
+}
+
+// globClass translates the character class like "[ab]" or "[!ab]" at the
+// start of pattern, reporting false if it is not terminated.
+func globClass(pattern string) (globCharClass, bool) {
+	start := 1
+	negated := strings.HasPrefix(pattern[start:], "!")
+	if negated {
+		start++
+	}
+	// a "]" right after the opening bracket is part of the class
+	search := min(start+1, len(pattern))
+	end := strings.IndexByte(pattern[search:], ']')
+	if end < 0 {
+		return globCharClass{}, false
+	}
+	end += search
+
+	var sb strings.Builder
+	sb.WriteByte('[')
+	if negated {
+		sb.WriteByte('^')
+	}
+	for _, r := range pattern[start:end] {
+		if r == '\\' || r == '[' || r == ']' || r == '^' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte(']')
+	return globCharClass{regexp: sb.String(), length: end + 1}, true
+}
+
 // StartsWith matches attributes whose value begins with the given prefix.
 func StartsWith(value string) Filter {
 	return createFilter("StartsWith", value)
 }
 
 // GreaterThan matches attributes with a numeric value strictly greater than the given value.
-func GreaterThan(value int) Filter {
-	return createFilter("GreaterThan", value)
+// Like GreaterThanOrEquals, LessThan and LessThanOrEquals, it also compares
+// date and datetime attributes with a time.Time, see OlderThan.
+func GreaterThan[V orderedValue](value V) Filter {
+	return createFilter("GreaterThan", orderedFilterValue(value))
 }
 
 // GreaterThanOrEquals matches attributes with a numeric value greater than or equal to the given value.
-func GreaterThanOrEquals(value int) Filter {
-	return createFilter("GreaterThanOrEquals", value)
+func GreaterThanOrEquals[V orderedValue](value V) Filter {
+	return createFilter("GreaterThanOrEquals", orderedFilterValue(value))
 }
 
 // LessThan matches attributes with a numeric value strictly less than the given value.
-func LessThan(value int) Filter {
-	return createFilter("LessThan", value)
+func LessThan[V orderedValue](value V) Filter {
+	return createFilter("LessThan", orderedFilterValue(value))
 }
 
 // LessThanOrEquals matches attributes with a numeric value less than or equal to the given value.
-func LessThanOrEquals(value int) Filter {
-	return createFilter("LessThanOrEquals", value)
+func LessThanOrEquals[V orderedValue](value V) Filter {
+	return createFilter("LessThanOrEquals", orderedFilterValue(value))
+}
+
+// Between matches attributes with a numeric value from lo to hi, both
+// included, e.g. Between(8, 16) for num_cpu. It is short for
+// All(GreaterThanOrEquals(lo), LessThanOrEquals(hi)).
+func Between[V orderedValue](lo, hi V) Filter {
+	return All(GreaterThanOrEquals(lo), LessThanOrEquals(hi))
+}
+
+// OlderThan matches date and datetime attributes before t, e.g. objects not
+// verified for 90 days:
+//
+//	adminapi.Filters{"last_verified": adminapi.OlderThan(time.Now().AddDate(0, 0, -90))}
+//
+// It is short for LessThan(t).
+func OlderThan(t time.Time) Filter {
+	return LessThan(t)
 }
 
-// Contains matches multi-valued attributes that contain the given value.
-func Contains[V valueOrFilter](value V) Filter {
-	return createFilter("Contains", value)
+// NewerThan matches date and datetime attributes after t. It is short for
+// GreaterThan(t).
+func NewerThan(t time.Time) Filter {
+	return GreaterThan(t)
+}
+
+// Contains matches multi-valued attributes that contain the given value. Like
+// ContainedBy, ContainedOnlyBy and Overlaps, it also takes netip.Addr and
+// netip.Prefix values, e.g. for networks containing an address.
+func Contains[V networkValueOrFilter](value V) Filter {
+	return createFilter("Contains", networkValue(value))
 }
 
 // ContainedBy matches attributes whose values are a subset of the given value.
-func ContainedBy[V valueOrFilter](value V) Filter {
-	return createFilter("ContainedBy", value)
+func ContainedBy[V networkValueOrFilter](value V) Filter {
+	return createFilter("ContainedBy", networkValue(value))
 }
 
 // ContainedOnlyBy matches attributes whose values are exclusively contained by the given value.
-func ContainedOnlyBy[V valueOrFilter](value V) Filter {
-	return createFilter("ContainedOnlyBy", value)
+func ContainedOnlyBy[V networkValueOrFilter](value V) Filter {
+	return createFilter("ContainedOnlyBy", networkValue(value))
 }
 
 // Overlaps matches multi-valued attributes that share at least one element with the given value.
-func Overlaps[V valueOrFilter](value V) Filter {
-	return createFilter("Overlaps", value)
+func Overlaps[V networkValueOrFilter](value V) Filter {
+	return createFilter("Overlaps", networkValue(value))
+}
+
+// InsideNetwork matches addresses and networks inside the given network, for
+// IPAM lookups on intern_ip or primary_ip:
+//
+//	adminapi.Filters{"intern_ip": adminapi.InsideNetwork("10.0.0.0/8")}
+//
+// The network is a CIDR string or a netip.Prefix. It compiles to the
+// ContainedOnlyBy filter.
+func InsideNetwork[N string | netip.Prefix](network N) Filter {
+	return createFilter("ContainedOnlyBy", networkValue(network))
+}
+
+// orderedFilterValue returns times in the format Serveradmin expects: a date
+// like "2024-05-01" at midnight, e.g. for date attributes, otherwise a
+// datetime like "2024-05-01T12:30:00Z". Other values are unchanged.
+func orderedFilterValue(value any) any {
+	t, ok := value.(time.Time)
+	if !ok {
+		return value
+	}
+	if t.Equal(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())) {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// networkValue returns the string form of netip.Addr and netip.Prefix values
+// and any other value unchanged.
+func networkValue(value any) any {
+	switch value := value.(type) {
+	case netip.Addr:
+		return value.String()
+	case netip.Prefix:
+		return value.String()
+	}
+	return value
 }
 
 func createFilter(filterType string, value any) Filter {
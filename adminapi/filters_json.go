@@ -0,0 +1,133 @@
+package adminapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MarshalJSON encodes the filters as sent to Serveradmin, e.g.
+// {"hostname":{"Regexp":"web.*"},"num_cpu":4}, with floats always written
+// with a fraction or exponent, so that UnmarshalJSON reads back equal filters.
+// This allows saving searches in config files or databases.
+func (f Filters) MarshalJSON() ([]byte, error) {
+	if f == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(encodeFilterValue(map[string]any(f)))
+}
+
+// UnmarshalJSON decodes filters encoded by MarshalJSON. Objects with a single
+// filter function name as key, like {"Regexp":"web.*"}, become Filter values,
+// integral numbers int and other numbers float64. Lists become []any, so
+// filters built from typed lists, like Any(1, 2), come back as the
+// equivalent Filter{"Any": []any{1, 2}}, as ParseQuery returns them.
+func (f *Filters) UnmarshalJSON(data []byte) error {
+	var decoded map[string]any
+	if err := decodeJSONNumbers(data, &decoded); err != nil {
+		return err
+	}
+	*f = Filters(decodeFilterValues(decoded))
+	return nil
+}
+
+// MarshalJSON encodes the filter like Filters.MarshalJSON.
+func (f Filter) MarshalJSON() ([]byte, error) {
+	if f == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(encodeFilterValue(map[string]any(f)))
+}
+
+// UnmarshalJSON decodes a filter like Filters.UnmarshalJSON.
+func (f *Filter) UnmarshalJSON(data []byte) error {
+	var decoded map[string]any
+	if err := decodeJSONNumbers(data, &decoded); err != nil {
+		return err
+	}
+	*f = Filter(decodeFilterValues(decoded))
+	return nil
+}
+
+// decodeFilterValues converts the values of m with decodeFilterValue.
+func decodeFilterValues(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	decoded := make(map[string]any, len(m))
+	for key, v := range m {
+		decoded[key] = decodeFilterValue(v)
+	}
+	return decoded
+}
+
+// decodeJSONNumbers decodes data into v, keeping numbers as json.Number.
+func decodeJSONNumbers(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// encodeFilterValue converts filter values for encoding: floats to numbers
+// with a fraction and filters and lists recursively.
+func encodeFilterValue(value any) any {
+	switch value := value.(type) {
+	case Filter:
+		return encodeFilterValue(map[string]any(value))
+	case Filters:
+		return encodeFilterValue(map[string]any(value))
+	case map[string]any:
+		encoded := make(map[string]any, len(value))
+		for key, v := range value {
+			encoded[key] = encodeFilterValue(v)
+		}
+		return encoded
+	case float64:
+		return json.Number(formatFloat(value))
+	case float32:
+		return json.Number(formatFloat(float64(value)))
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		if v.IsNil() {
+			return value
+		}
+		encoded := make([]any, v.Len())
+		for i := range encoded {
+			encoded[i] = encodeFilterValue(v.Index(i).Interface())
+		}
+		return encoded
+	}
+	return value
+}
+
+// decodeFilterValue converts a value decoded with json.Decoder.UseNumber to
+// filter values, see Filters.UnmarshalJSON.
+func decodeFilterValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		decoded := decodeFilterValues(value)
+		for name := range decoded {
+			if len(decoded) == 1 && isFilterName(name) {
+				return Filter(decoded)
+			}
+		}
+		return decoded
+	case []any:
+		decoded := make([]any, len(value))
+		for i, v := range value {
+			decoded[i] = decodeFilterValue(v)
+		}
+		return decoded
+	case json.Number:
+		if !strings.ContainsAny(value.String(), ".eE") {
+			if i, err := strconv.Atoi(value.String()); err == nil {
+				return i
+			}
+		}
+		f, _ := value.Float64()
+		return f
+	}
+	return value
+}
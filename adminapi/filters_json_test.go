@@ -0,0 +1,83 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiltersJSON(t *testing.T) {
+	filters := Filters{
+		"hostname":   Regexp("web.*"),
+		"num_cpu":    GreaterThanOrEquals(4),
+		"load":       LessThan(2.0),
+		"state":      Not(Any("retired", "maintenance")),
+		"os":         NotEmpty(),
+		"game_world": Or(1, 2.5, nil, true),
+	}
+
+	data, err := json.Marshal(filters)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"hostname": {"Regexp": "web.*"},
+		"num_cpu": {"GreaterThanOrEquals": 4},
+		"load": {"LessThan": 2.0},
+		"state": {"Not": {"Any": ["retired", "maintenance"]}},
+		"os": {"Not": {"Empty": null}},
+		"game_world": {"Any": [1, 2.5, null, true]}
+	}`, string(data))
+	assert.Contains(t, string(data), `"LessThan":2.0`, "floats keep a fraction")
+
+	var decoded Filters
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, Filters{
+		"hostname":   Filter{"Regexp": "web.*"},
+		"num_cpu":    Filter{"GreaterThanOrEquals": 4},
+		"load":       Filter{"LessThan": 2.0},
+		"state":      Filter{"Not": Filter{"Any": []any{"retired", "maintenance"}}},
+		"os":         Filter{"Not": Filter{"Empty": nil}},
+		"game_world": Filter{"Any": []any{1, 2.5, nil, true}},
+	}, decoded)
+	assert.Equal(t, filters.Format(), decoded.Format())
+
+	var filter Filter
+	require.NoError(t, json.Unmarshal([]byte(`{"Any": [{"Regexp": "a"}, 3]}`), &filter))
+	assert.Equal(t, Filter{"Any": []any{Filter{"Regexp": "a"}, 3}}, filter)
+
+	var null Filters
+	require.NoError(t, json.Unmarshal([]byte(`null`), &null))
+	assert.Nil(t, null)
+	data, err = json.Marshal(Filters(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+}
+
+func FuzzFiltersJSONRoundTrip(f *testing.F) {
+	for _, query := range []string{
+		"hostname=regexp(web.*) state=online",
+		"game_world=any(1 2.5 true 1e21 -0.0 null) num_cpu>=4",
+		`description="quoted \"string\"" note='{"Any": 1}'`,
+		"hostname=not(empty()) os=all(any(a b) not(c))",
+	} {
+		f.Add(query)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		if !utf8.ValidString(query) {
+			return // JSON strings are UTF-8
+		}
+		filters, err := ParseQuery(query)
+		if err != nil || containsNaN(filters) {
+			return
+		}
+		data, err := json.Marshal(filters)
+		if err != nil {
+			return // infinite floats have no JSON encoding
+		}
+		var decoded Filters
+		require.NoError(t, json.Unmarshal(data, &decoded), "query %q encoded as %s", query, data)
+		require.Equal(t, filters, decoded, "query %q encoded as %s", query, data)
+	})
+}
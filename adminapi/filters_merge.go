@@ -0,0 +1,66 @@
+package adminapi
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// MergePolicy decides how Filters.Merge handles an attribute filtered on both
+// sides with different filters.
+type MergePolicy int
+
+const (
+	// MergeOverride uses the filter of the merged Filters.
+	MergeOverride MergePolicy = iota
+	// MergeErrorOnConflict fails with ErrFilterConflict.
+	MergeErrorOnConflict
+	// MergeCombine requires both filters to match, combining them with All.
+	MergeCombine
+)
+
+// Merge returns the filters of f and other, e.g. to layer user-supplied
+// filters over a base selection of servertype and project:
+//
+//	filters, err := base.Merge(userFilters, adminapi.MergeErrorOnConflict)
+//
+// Attributes filtered on both sides with equal filters are kept once; policy
+// decides about different ones. Neither f nor other is modified.
+func (f Filters) Merge(other Filters, policy MergePolicy) (Filters, error) {
+	merged := maps.Clone(f)
+	if merged == nil {
+		merged = make(Filters, len(other))
+	}
+	for _, attribute := range slices.Sorted(maps.Keys(other)) {
+		value := other[attribute]
+		existing, ok := merged[attribute]
+		if !ok || reflect.DeepEqual(existing, value) {
+			merged[attribute] = value
+			continue
+		}
+		switch policy {
+		case MergeOverride:
+			merged[attribute] = value
+		case MergeErrorOnConflict:
+			return nil, fmt.Errorf("%w on %s: %s and %s", ErrFilterConflict, attribute,
+				Filters{attribute: existing}.Format(), Filters{attribute: value}.Format())
+		case MergeCombine:
+			merged[attribute] = createFilter("All", append(allFilterArgs(existing), allFilterArgs(value)...))
+		default:
+			return nil, fmt.Errorf("unknown merge policy %d", policy)
+		}
+	}
+	return merged, nil
+}
+
+// allFilterArgs returns the filters an All filter has to combine for value:
+// the arguments if it is an All filter itself, or value.
+func allFilterArgs(value any) []any {
+	if filter, ok := value.(Filter); ok && len(filter) == 1 {
+		if args, ok := filter["All"].([]any); ok {
+			return slices.Clone(args)
+		}
+	}
+	return []any{value}
+}
@@ -0,0 +1,38 @@
+package adminapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiltersMerge(t *testing.T) {
+	base := Filters{"servertype": "vm", "project": "ops", "state": "online"}
+	user := Filters{"state": Not("retired"), "hostname": Regexp("web.*"), "project": "ops"}
+
+	merged, err := base.Merge(user, MergeOverride)
+	require.NoError(t, err)
+	assert.Equal(t, Filters{"servertype": "vm", "project": "ops", "state": Not("retired"), "hostname": Regexp("web.*")}, merged)
+	assert.Equal(t, "online", base["state"], "the receiver is not modified")
+
+	_, err = base.Merge(user, MergeErrorOnConflict)
+	require.ErrorIs(t, err, ErrFilterConflict)
+	assert.EqualError(t, err, "conflicting filters on state: state=online and state=not(retired)")
+
+	merged, err = base.Merge(Filters{"project": "ops", "hostname": Regexp("web.*")}, MergeErrorOnConflict)
+	require.NoError(t, err, "equal filters don't conflict")
+	assert.Len(t, merged, 4)
+
+	merged, err = base.Merge(user, MergeCombine)
+	require.NoError(t, err)
+	assert.Equal(t, Filter{"All": []any{"online", Not("retired")}}, merged["state"])
+
+	merged, err = merged.Merge(Filters{"state": Regexp("on.*")}, MergeCombine)
+	require.NoError(t, err)
+	assert.Equal(t, Filter{"All": []any{"online", Not("retired"), Regexp("on.*")}}, merged["state"], "All filters are extended")
+
+	merged, err = Filters(nil).Merge(user, MergeErrorOnConflict)
+	require.NoError(t, err)
+	assert.Equal(t, user, merged)
+}
@@ -0,0 +1,136 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"net/netip"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetween(t *testing.T) {
+	filter := Between(8, 16)
+	assert.Equal(t, All(GreaterThanOrEquals(8), LessThanOrEquals(16)), filter)
+	assert.Equal(t, "num_cpu=all(greaterthanorequals(8) lessthanorequals(16))", Filters{"num_cpu": filter}.String())
+
+	body, err := json.Marshal(Filters{"num_cpu": filter})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"num_cpu":{"All":[{"GreaterThanOrEquals":8},{"LessThanOrEquals":16}]}}`, string(body))
+}
+
+func TestOrAnd(t *testing.T) {
+	filter := Or("online", Regexp("deploy_.*"), 3)
+	assert.Equal(t, Filter{"Any": []any{"online", Filter{"Regexp": "deploy_.*"}, 3}}, filter)
+	assert.Equal(t, "state=any(online regexp(deploy_.*) 3)", Filters{"state": filter}.String())
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t,
+		Filter{"All": []any{Filter{"Not": "10.0.0.1"}, "10.0.0.0/8", "2024-05-01"}},
+		And(Not("10.0.0.1"), netip.MustParsePrefix("10.0.0.0/8"), day))
+}
+
+func TestRegisterFilter(t *testing.T) {
+	_, err := ParseQuery("hostname=icontains(web)")
+	require.Error(t, err, "unknown filters are rejected")
+
+	RegisterFilter("IContains")
+	t.Cleanup(func() {
+		filtersMu.Lock()
+		defer filtersMu.Unlock()
+		delete(allFilters, "icontains")
+	})
+
+	filters, err := ParseQuery("hostname=IContains(web)")
+	require.NoError(t, err)
+	assert.Equal(t, Filters{"hostname": RawFilter("IContains", "web")}, filters)
+	assert.Equal(t, "hostname=icontains(web)", filters.Format())
+
+	var decoded Filters
+	require.NoError(t, json.Unmarshal([]byte(`{"hostname": {"IContains": "web"}}`), &decoded))
+	assert.Equal(t, filters, decoded)
+
+	assert.Panics(t, func() { RegisterFilter("bad name") })
+	assert.Panics(t, func() { RegisterFilter("") })
+}
+
+func TestIRegexp(t *testing.T) {
+	filter, err := IRegexp("^web[0-9]+$")
+	require.NoError(t, err)
+	assert.Equal(t, Regexp("(?i)^web[0-9]+$"), filter)
+
+	_, err = IRegexp("web[")
+	require.ErrorContains(t, err, "invalid regexp")
+}
+
+func TestNumericFilters(t *testing.T) {
+	assert.Equal(t, Filter{"GreaterThan": 0.75}, GreaterThan(0.75))
+	assert.Equal(t, Filter{"LessThanOrEquals": int64(1 << 40)}, LessThanOrEquals(int64(1<<40)))
+	assert.Equal(t, All(GreaterThanOrEquals(0.5), LessThanOrEquals(1.5)), Between(0.5, 1.5))
+	assert.Equal(t, "load=greaterthan(2.0) object_id=lessthan(1099511627776)",
+		Filters{"load": GreaterThan(2.0), "object_id": LessThan(int64(1 << 40))}.String())
+}
+
+func TestTimeFilters(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	moment := time.Date(2024, 5, 1, 12, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	assert.Equal(t, Filter{"LessThan": "2024-05-01"}, OlderThan(day))
+	assert.Equal(t, Filter{"GreaterThan": "2024-05-01T12:30:00+02:00"}, NewerThan(moment))
+	assert.Equal(t, Filter{"GreaterThanOrEquals": "2024-05-01"}, GreaterThanOrEquals(day))
+	assert.Equal(t, Filter{"LessThanOrEquals": "2024-05-01T12:30:00+02:00"}, LessThanOrEquals(moment))
+	assert.Equal(t, All(GreaterThanOrEquals(day), LessThanOrEquals(day.AddDate(0, 1, 0))), Between(day, day.AddDate(0, 1, 0)))
+	assert.Equal(t, Filter{"LessThan": 4}, LessThan(4), "numbers are unchanged")
+
+	assert.Equal(t, "last_verified=lessthan(2024-05-01)", Filters{"last_verified": OlderThan(day)}.String())
+}
+
+func TestNetworkFilters(t *testing.T) {
+	addr := netip.MustParseAddr("10.0.0.1")
+	prefix := netip.MustParsePrefix("10.0.0.0/16")
+
+	assert.Equal(t, Filter{"Contains": "10.0.0.1"}, Contains(addr))
+	assert.Equal(t, Filter{"ContainedBy": "10.0.0.0/16"}, ContainedBy(prefix))
+	assert.Equal(t, Filter{"ContainedOnlyBy": "10.0.0.0/16"}, ContainedOnlyBy(prefix))
+	assert.Equal(t, Filter{"Overlaps": "2001:db8::/32"}, Overlaps(netip.MustParsePrefix("2001:db8::/32")))
+	assert.Equal(t, Filter{"ContainedBy": "10.0.0.0/16"}, ContainedBy("10.0.0.0/16"), "strings are unchanged")
+	assert.Equal(t, Filter{"ContainedOnlyBy": "10.0.0.0/8"}, InsideNetwork("10.0.0.0/8"))
+	assert.Equal(t, Filter{"ContainedOnlyBy": "10.0.0.0/16"}, InsideNetwork(prefix))
+
+	body, err := json.Marshal(Filters{"intern_ip": ContainedBy(prefix)})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"intern_ip":{"ContainedBy":"10.0.0.0/16"}}`, string(body))
+	assert.Equal(t, "intern_ip=containedby(10.0.0.0/16)", Filters{"intern_ip": ContainedBy(prefix)}.String())
+}
+
+func TestGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"web-*.prod.example.com", `^web-.*\.prod\.example\.com$`},
+		{"db??", "^db..$"},
+		{"web[12]", "^web[12]$"},
+		{"web[!0-4]", "^web[^0-4]$"},
+		{"web[]a]", `^web[\]a]$`},
+		{"web[^\\]", `^web[\^\\]$`},
+		{"web[1", `^web\[1$`},
+		{"plain", "^plain$"},
+		{"ü*", "^ü.*$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			filter := Glob(tt.pattern)
+			assert.Equal(t, Regexp(tt.want), filter)
+			_, err := regexp.Compile(tt.want)
+			require.NoError(t, err)
+		})
+	}
+
+	re := regexp.MustCompile(Glob("web-*.prod.example.com")["Regexp"].(string))
+	assert.True(t, re.MatchString("web-01.prod.example.com"))
+	assert.False(t, re.MatchString("web-01.prodXexample.com"))
+	assert.False(t, re.MatchString("old-web-01.prod.example.com"))
+}
@@ -0,0 +1,151 @@
+package adminapi
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Format renders the filters in canonical Serveradmin query language form,
+// e.g. "hostname=regexp(web.*) state=online": attributes are ordered by name,
+// filter functions are lowercase and values are only quoted when they would
+// otherwise be read differently. ParseQuery reads the result back into equal
+// Filters, so that queries written differently, like "num_cpu>4" and
+// "num_cpu=GreaterThan(4)", can be normalized and compared.
+func (f Filters) Format() string {
+	var sb strings.Builder
+	for i, attribute := range slices.Sorted(maps.Keys(f)) {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(attribute)
+		sb.WriteByte('=')
+		writeFilterValue(&sb, f[attribute], false)
+	}
+	return sb.String()
+}
+
+// String renders the filters in the Serveradmin query language, see Format,
+// so that the effective query can be logged, shown to users or passed to the
+// CLI.
+func (f Filters) String() string {
+	return f.Format()
+}
+
+// String renders the filter in the Serveradmin query language, e.g.
+// "regexp(web.*)".
+func (f Filter) String() string {
+	var sb strings.Builder
+	writeFilterValue(&sb, f, false)
+	return sb.String()
+}
+
+// QueryString renders the query's filters in the Serveradmin query language,
+// see Filters.String. The alternatives of a union query are separated by
+// "; ".
+func (q *Query) QueryString() string {
+	alternatives := q.alternativeFilters()
+	formatted := make([]string, len(alternatives))
+	for i, filters := range alternatives {
+		formatted[i] = filters.Format()
+	}
+	return strings.Join(formatted, "; ")
+}
+
+// writeFilterValue writes a filter or value in query language syntax. Raw
+// values are arguments of regexp(), whose backslashes are kept by ParseQuery.
+func writeFilterValue(sb *strings.Builder, value any, raw bool) {
+	switch value := value.(type) {
+	case Filter:
+		writeFilterFunc(sb, value)
+	case map[string]any:
+		writeFilterFunc(sb, value)
+	case string:
+		writeString(sb, value, raw)
+	case bool:
+		sb.WriteString(strconv.FormatBool(value))
+	case int:
+		sb.WriteString(strconv.Itoa(value))
+	case float64:
+		writeFloat(sb, value)
+	case nil:
+		sb.WriteString("null")
+	default:
+		switch v := reflect.ValueOf(value); v.Kind() {
+		case reflect.Slice:
+			for i := range v.Len() {
+				if i > 0 {
+					sb.WriteByte(' ')
+				}
+				writeFilterValue(sb, v.Index(i).Interface(), raw)
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			sb.WriteString(strconv.FormatInt(v.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			sb.WriteString(strconv.FormatUint(v.Uint(), 10))
+		case reflect.Float32:
+			writeFloat(sb, v.Float())
+		default:
+			writeString(sb, fmt.Sprint(value), raw)
+		}
+	}
+}
+
+// writeFilterFunc writes a filter function like {"Regexp": "web.*"} as
+// "regexp(web.*)".
+func writeFilterFunc(sb *strings.Builder, filter map[string]any) {
+	for _, name := range slices.Sorted(maps.Keys(filter)) {
+		sb.WriteString(strings.ToLower(name))
+		sb.WriteByte('(')
+		if arg := filter[name]; arg != nil || name != "Empty" {
+			writeFilterValue(sb, arg, name == "Regexp")
+		}
+		sb.WriteByte(')')
+	}
+}
+
+// writeFloat writes f so that it is read back as a float, e.g. 1 as "1.0".
+func writeFloat(sb *strings.Builder, f float64) {
+	sb.WriteString(formatFloat(f))
+}
+
+// formatFloat formats f so that it is read back as a float rather than an
+// integer, e.g. 1 as "1.0".
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if _, err := strconv.Atoi(s); err == nil {
+		s += ".0"
+	}
+	return s
+}
+
+// writeString writes s unquoted if it is read back as the same string, e.g.
+// unless it contains spaces or parentheses or looks like a number, and quoted
+// otherwise. Double quotes are preferred; single quotes avoid escaping double
+// quotes in s.
+func writeString(sb *strings.Builder, s string, raw bool) {
+	candidates := []string{`"` + s + `"`, `'` + s + `'`}
+	if s != "" {
+		candidates = slices.Insert(candidates, 0, s)
+	}
+	for _, candidate := range candidates {
+		if readsAs(candidate, raw, s) {
+			sb.WriteString(candidate)
+			return
+		}
+	}
+	sb.WriteString(`"`)
+	sb.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+	sb.WriteString(`"`)
+}
+
+// readsAs reports whether the query language value v is read back as the
+// string s when followed by a space or the end of a filter function.
+func readsAs(v string, raw bool, s string) bool {
+	p := &parser{input: v + " "}
+	value, err := p.parseValue(true, raw)
+	return err == nil && p.pos == len(v) && value == s
+}
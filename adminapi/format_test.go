@@ -0,0 +1,143 @@
+package adminapi
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFiltersString(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters Filters
+		want    string
+	}{
+		{
+			name:    "empty",
+			filters: Filters{},
+			want:    "",
+		},
+		{
+			name:    "sorted attributes",
+			filters: Filters{"state": "online", "hostname": Regexp("web.*")},
+			want:    "hostname=regexp(web.*) state=online",
+		},
+		{
+			name:    "typed values",
+			filters: Filters{"num_cpu": 4, "memory": 10.5, "active": true},
+			want:    "active=true memory=10.5 num_cpu=4",
+		},
+		{
+			name:    "integral floats",
+			filters: Filters{"memory": 16.0, "disk": 1e21},
+			want:    "disk=1e+21 memory=16.0",
+		},
+		{
+			name:    "quoted strings",
+			filters: Filters{"description": "quoted string", "game_world": "12", "os": "true", "note": `say "hi"`},
+			want:    `description="quoted string" game_world="12" note='say "hi"' os="true"`,
+		},
+		{
+			name:    "null",
+			filters: Filters{"primary_ip6": nil, "backup_server": Filter{"Not": nil}, "comment": "null"},
+			want:    `backup_server=not(null) comment="null" primary_ip6=null`,
+		},
+		{
+			name:    "nested filters",
+			filters: Filters{"hostname": Not(Empty()), "game_world": Any(1, 2, 3)},
+			want:    "game_world=any(1 2 3) hostname=not(empty())",
+		},
+		{
+			name:    "quoted filter argument",
+			filters: Filters{"hostname": Regexp("web (a|b)")},
+			want:    `hostname=regexp("web (a|b)")`,
+		},
+		{
+			name:    "escaped strings",
+			filters: Filters{"description": `it's "quoted" \ twice`, "comment": ""},
+			want:    `comment="" description="it's \"quoted\" \\ twice"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filters.String())
+		})
+	}
+}
+
+func TestFiltersStringRoundTrip(t *testing.T) {
+	for _, query := range []string{
+		"hostname=regexp(web.*) state=online",
+		`description="quoted string" game_world="12"`,
+		"hostname=not(empty()) num_cpu=4",
+		"game_world=any(1 2 3) hypervisor=all(1 server true)",
+		`hostname=regexp("web (a|b)")`,
+	} {
+		filters, err := ParseQuery(query)
+		require.NoError(t, err)
+		assert.Equal(t, query, filters.String())
+	}
+}
+
+func TestFiltersFormatNormalizes(t *testing.T) {
+	a, err := ParseQuery(`num_cpu>4 hostname=Regexp(web\d+)   state="online"`)
+	require.NoError(t, err)
+	b, err := ParseQuery(`state=online num_cpu=greaterthan(4) hostname=regexp("web\d+")`)
+	require.NoError(t, err)
+
+	assert.Equal(t, `hostname=regexp(web\d+) num_cpu=greaterthan(4) state=online`, a.Format())
+	assert.Equal(t, a.Format(), b.Format())
+}
+
+func FuzzFormatRoundTrip(f *testing.F) {
+	for _, query := range []string{
+		"hostname=regexp(web.*) state=online",
+		`description="quoted string" game_world="12"`,
+		"hostname=not(empty()) num_cpu>=4 memory=1.0",
+		`hostname=regexp("web \"(a|b)\"") note='say "hi"'`,
+		`hostname=web\ 01 os=any(a\(b\) "c\\d" 'e')`,
+		"game_world=any(1 2.5 true 1e21 -0.0 null)",
+		"hostname=empty(null) backup_server=not(null)",
+		"web01 db* or web01 web01; state=online",
+		"0;0 0 ",
+		"a=1 and b=2 or c=3 AND and=and",
+	} {
+		f.Add(query)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		filters, err := ParseQuery(query)
+		if err != nil || containsNaN(filters) {
+			return
+		}
+		formatted := filters.Format()
+		reparsed, err := ParseQuery(formatted)
+		require.NoError(t, err, "query %q formatted as %q", query, formatted)
+		require.Equal(t, filters, reparsed, "query %q formatted as %q", query, formatted)
+	})
+}
+
+// containsNaN reports whether value contains a NaN, which never equals
+// itself.
+func containsNaN(value any) bool {
+	switch value := value.(type) {
+	case float64:
+		return math.IsNaN(value)
+	case Filters:
+		return slices.ContainsFunc(slices.Collect(maps.Values(value)), containsNaN)
+	case Filter:
+		return slices.ContainsFunc(slices.Collect(maps.Values(value)), containsNaN)
+	case []any:
+		return slices.ContainsFunc(value, containsNaN)
+	}
+	return false
+}
+
+func TestQueryString(t *testing.T) {
+	client := mustClient(t, "https://serveradmin.example.com")
+	q := client.NewQuery(Filters{"hostname": StartsWith("web"), "servertype": "vm"})
+	assert.Equal(t, "hostname=startswith(web) servertype=vm", q.QueryString())
+}
@@ -0,0 +1,181 @@
+package adminapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// GetAs retrieves an attribute converted to T, as a single type-safe
+// alternative to the GetX getters:
+//
+//	memory, err := adminapi.GetAs[int64](obj, "memory")
+//	tags, err := adminapi.GetAs[[]string](obj, "tags")
+//
+// Numbers convert between all integer and float types as long as the value
+// fits exactly, so a JSON 4.0 reads as int but 4.5 does not. Multi attributes
+// read as slices of any convertible type, and strings and bools also convert
+// to named types like `type State string`. Strings convert to time.Time from
+// dates and datetimes, and to types implementing encoding.TextUnmarshaler,
+// like netip.Addr and netip.Prefix. A null value reads as the zero value.
+// Missing attributes fail with ErrUnknownAttribute, values that cannot be
+// converted with ErrAttributeType.
+func GetAs[T any](obj *ServerObject, attribute string) (T, error) {
+	var zero T
+	value, ok := obj.attributes[attribute]
+	if !ok {
+		return zero, fmt.Errorf("attribute %q: %w", attribute, ErrUnknownAttribute)
+	}
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	if value == nil {
+		return zero, nil
+	}
+
+	converted, ok := convertValue(value, reflect.TypeFor[T]())
+	if !ok {
+		return zero, fmt.Errorf("attribute %q: %w: cannot convert %T %v to %s",
+			attribute, ErrAttributeType, value, value, reflect.TypeFor[T]())
+	}
+	return converted.Interface().(T), nil
+}
+
+// convertValue converts value to the target type without losing information.
+// Null converts to the zero value.
+func convertValue(value any, target reflect.Type) (reflect.Value, bool) {
+	result := reflect.New(target).Elem()
+	if value == nil {
+		return result, true
+	}
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(target) {
+		result.Set(source)
+		return result, true
+	}
+
+	if str, ok := value.(string); ok {
+		if target == reflect.TypeFor[time.Time]() {
+			t, err := parseTime(str)
+			if err != nil {
+				return result, false
+			}
+			result.Set(reflect.ValueOf(t))
+			return result, true
+		}
+		if unmarshaler, ok := result.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return result, unmarshaler.UnmarshalText([]byte(str)) == nil
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := exactInt(value)
+		if !ok || result.OverflowInt(i) {
+			return result, false
+		}
+		result.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		i, ok := exactInt(value)
+		if !ok || i < 0 || result.OverflowUint(uint64(i)) {
+			return result, false
+		}
+		result.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		f, ok := exactFloat(value)
+		if !ok || target.Kind() == reflect.Float32 && float64(float32(f)) != f {
+			return result, false
+		}
+		result.SetFloat(f)
+	case reflect.String, reflect.Bool:
+		if source.Kind() != target.Kind() {
+			return result, false
+		}
+		result.Set(source.Convert(target))
+	case reflect.Slice:
+		if source.Kind() != reflect.Slice {
+			return result, false
+		}
+		result = reflect.MakeSlice(target, source.Len(), source.Len())
+		for i := range source.Len() {
+			elem, ok := convertValue(source.Index(i).Interface(), target.Elem())
+			if !ok {
+				return result, false
+			}
+			result.Index(i).Set(elem)
+		}
+	case reflect.Pointer:
+		elem, ok := convertValue(value, target.Elem())
+		if !ok {
+			return result, false
+		}
+		result.Set(reflect.New(target.Elem()))
+		result.Elem().Set(elem)
+	default:
+		return result, false
+	}
+	return result, true
+}
+
+// parseTime parses Serveradmin's datetime values, RFC 3339 with or without
+// the "T" separator, and dates.
+func parseTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05Z07:00", time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+// exactInt returns the numeric value as an int64 if it is integral and fits.
+func exactInt(value any) (int64, bool) {
+	if n, ok := value.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		value = f
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint()), v.Uint() <= math.MaxInt64
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return int64(f), f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+// exactFloat returns the numeric value as a float64 if it is represented
+// exactly, i.e. integers up to 2^53.
+func exactFloat(value any) (float64, bool) {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	const maxExact = 1 << 53
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), v.Int() >= -maxExact && v.Int() <= maxExact
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), v.Uint() <= maxExact
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
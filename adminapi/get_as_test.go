@@ -0,0 +1,92 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAs(t *testing.T) {
+	type state string
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id": float64(42),
+			"load":      0.5,
+			"memory":    json.Number("65536"),
+			"big":       float64(1 << 60),
+			"counter":   int64(1<<60 + 1),
+			"negative":  float64(-1),
+			"state":     "online",
+			"active":    true,
+			"tags":      []any{"web", "prod"},
+			"mixed":     []any{"web", 1},
+			"missing":   nil,
+		},
+		oldValues: Attributes{},
+	}
+
+	id, err := GetAs[int](obj, "object_id")
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+
+	id8, err := GetAs[uint8](obj, "object_id")
+	require.NoError(t, err)
+	assert.Equal(t, uint8(42), id8)
+
+	memory, err := GetAs[int64](obj, "memory")
+	require.NoError(t, err)
+	assert.Equal(t, int64(65536), memory)
+
+	memoryFloat, err := GetAs[float64](obj, "memory")
+	require.NoError(t, err)
+	assert.InDelta(t, 65536.0, memoryFloat, 0)
+
+	load, err := GetAs[float32](obj, "load")
+	require.NoError(t, err)
+	assert.InDelta(t, float32(0.5), load, 0)
+
+	s, err := GetAs[state](obj, "state")
+	require.NoError(t, err)
+	assert.Equal(t, state("online"), s)
+
+	active, err := GetAs[bool](obj, "active")
+	require.NoError(t, err)
+	assert.True(t, active)
+
+	tags, err := GetAs[[]string](obj, "tags")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web", "prod"}, tags)
+
+	multi, err := GetAs[MultiAttr](obj, "tags")
+	require.NoError(t, err)
+	assert.Equal(t, MultiAttr{"web", "prod"}, multi)
+
+	raw, err := GetAs[any](obj, "tags")
+	require.NoError(t, err)
+	assert.Equal(t, []any{"web", "prod"}, raw)
+
+	null, err := GetAs[string](obj, "missing")
+	require.NoError(t, err)
+	assert.Empty(t, null)
+
+	_, err = GetAs[int](obj, "nonexistent")
+	require.ErrorIs(t, err, ErrUnknownAttribute)
+
+	for name, get := range map[string]func() error{
+		"fraction to int":      func() error { _, err := GetAs[int](obj, "load"); return err },
+		"overflow":             func() error { _, err := GetAs[int32](obj, "big"); return err },
+		"negative to uint":     func() error { _, err := GetAs[uint](obj, "negative"); return err },
+		"string to int":        func() error { _, err := GetAs[int](obj, "state"); return err },
+		"number to string":     func() error { _, err := GetAs[string](obj, "object_id"); return err },
+		"mixed string slice":   func() error { _, err := GetAs[[]string](obj, "mixed"); return err },
+		"unsupported target":   func() error { _, err := GetAs[[]int](obj, "tags"); return err },
+		"string to bool":       func() error { _, err := GetAs[bool](obj, "state"); return err },
+		"inexact int to float": func() error { _, err := GetAs[float64](obj, "counter"); return err },
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, get(), ErrAttributeType)
+		})
+	}
+}
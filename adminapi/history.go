@@ -0,0 +1,166 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+const apiEndpointChanges = "/api/dataset/changes"
+
+// ChangeLogEntry is a change of an object recorded by Serveradmin. Commits
+// changing several attributes of an object yield one entry per attribute.
+type ChangeLogEntry struct {
+	CommitID int       `json:"commit_id"`
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	App      string    `json:"app"`
+	ObjectID int       `json:"object_id"`
+	// Type is "create", "change" or "delete".
+	Type string `json:"type"`
+	// Attribute is the changed attribute, empty for deletions.
+	Attribute string `json:"attribute,omitempty"`
+	// Old and New are the values of single-valued attributes before and
+	// after the change; created objects only have New.
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+	// Added and Removed are the values added to and removed from multi
+	// attributes.
+	Added   []any `json:"added,omitempty"`
+	Removed []any `json:"removed,omitempty"`
+}
+
+// changesResponse mirrors {"status": "success", "result": [{...}, ...]} with
+// one result per commit changing the object.
+type changesResponse struct {
+	Status  string         `json:"status"`
+	Result  []objectChange `json:"result"`
+	Message string         `json:"message"`
+}
+
+// objectChange is a change as Serveradmin stores it: the commit's metadata
+// and a change_json like the objects of a commit request.
+type objectChange struct {
+	CommitID   int            `json:"commit_id"`
+	ChangeOn   string         `json:"change_on"`
+	User       *string        `json:"user"`
+	App        *string        `json:"app"`
+	ObjectID   int            `json:"object_id"`
+	ChangeType string         `json:"change_type"`
+	ChangeJSON map[string]any `json:"change_json"`
+}
+
+// ChangeLog fetches the change history of the object with the given
+// object_id from Serveradmin's changes endpoint, in the order the server
+// returns it, so audits can be scripted. It requires a Serveradmin version
+// providing the endpoint.
+func (c *Client) ChangeLog(ctx context.Context, objectID int) (_ []ChangeLogEntry, err error) {
+	defer recoverPanic(c, &err)
+
+	resp, err := c.sendRequest(ctx, apiEndpointChanges, map[string]any{"object_id": objectID})
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", apiEndpointChanges, err)
+	}
+	defer closeBody(resp.Body)
+
+	var result changesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding changes response: %w", err)
+	}
+	if result.Status == "error" {
+		return nil, fmt.Errorf("fetching changes failed: %s", result.Message)
+	}
+
+	var entries []ChangeLogEntry
+	for _, change := range result.Result {
+		changeEntries, err := change.entries()
+		if err != nil {
+			return nil, fmt.Errorf("decoding changes response: %w", err)
+		}
+		entries = append(entries, changeEntries...)
+	}
+	return entries, nil
+}
+
+// ChangeLog fetches the change history of an object using the client
+// attached to ctx with WithClient, see Client.ChangeLog.
+func ChangeLog(ctx context.Context, objectID int) ([]ChangeLogEntry, error) {
+	client, ok := FromContext(ctx)
+	if !ok {
+		return nil, errors.New("no client attached to the context; use Client.ChangeLog or WithClient")
+	}
+	return client.ChangeLog(ctx, objectID)
+}
+
+// History fetches the change history of the object, see Client.ChangeLog.
+func (s *ServerObject) History(ctx context.Context) (_ []ChangeLogEntry, err error) {
+	defer recoverPanic(clientOrContext(ctx, s.boundClient()), &err)
+
+	client, err := s.resolveClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := s.ObjectID()
+	if id == 0 {
+		return nil, errors.New("fetching history: object not committed yet")
+	}
+	return client.ChangeLog(ctx, id)
+}
+
+// entries flattens the change into one entry per attribute.
+func (c objectChange) entries() ([]ChangeLogEntry, error) {
+	changeOn, err := parseTime(c.ChangeOn)
+	if err != nil {
+		return nil, err
+	}
+	base := ChangeLogEntry{
+		CommitID: c.CommitID,
+		Time:     changeOn,
+		User:     derefString(c.User),
+		App:      derefString(c.App),
+		ObjectID: c.ObjectID,
+		Type:     c.ChangeType,
+	}
+	if c.ChangeType == "delete" {
+		return []ChangeLogEntry{base}, nil
+	}
+
+	var entries []ChangeLogEntry
+	for _, attribute := range slices.Sorted(maps.Keys(c.ChangeJSON)) {
+		if attribute == "object_id" {
+			continue
+		}
+		entry := base
+		entry.Attribute = attribute
+		value := c.ChangeJSON[attribute]
+		if c.ChangeType == "create" {
+			entry.New = value
+			entries = append(entries, entry)
+			continue
+		}
+		action, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("change of attribute %q: unexpected %T", attribute, value)
+		}
+		switch action["action"] {
+		case "multi":
+			entry.Added = toAnySlice(action["add"])
+			entry.Removed = toAnySlice(action["remove"])
+		default:
+			entry.Old, entry.New = action["old"], action["new"]
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,71 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, apiEndpointChanges, r.URL.Path)
+		var req map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, map[string]any{"object_id": float64(42)}, req)
+		_, _ = w.Write([]byte(`{"status": "success", "result": [
+			{"commit_id": 3, "change_on": "2024-05-02T10:00:00.5+00:00", "user": "alice", "app": null,
+			 "object_id": 42, "change_type": "change", "change_json": {
+				"object_id": 42,
+				"state": {"action": "update", "old": "online", "new": "maintenance"},
+				"tags": {"action": "multi", "add": ["web"], "remove": ["old"]}}},
+			{"commit_id": 1, "change_on": "2024-05-01T09:00:00+00:00", "user": null, "app": "deploy",
+			 "object_id": 42, "change_type": "create", "change_json": {"object_id": 42, "hostname": "web01"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	obj := &ServerObject{client: mustClient(t, server.URL), attributes: Attributes{"object_id": float64(42)}, oldValues: Attributes{}}
+	entries, err := obj.History(context.Background())
+	require.NoError(t, err)
+
+	changed := time.Date(2024, 5, 2, 10, 0, 0, 500_000_000, time.UTC)
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	require.Len(t, entries, 3)
+	for i, want := range []ChangeLogEntry{
+		{CommitID: 3, Time: changed, User: "alice", ObjectID: 42, Type: "change", Attribute: "state", Old: "online", New: "maintenance"},
+		{CommitID: 3, Time: changed, User: "alice", ObjectID: 42, Type: "change", Attribute: "tags", Added: []any{"web"}, Removed: []any{"old"}},
+		{CommitID: 1, Time: created, App: "deploy", ObjectID: 42, Type: "create", Attribute: "hostname", New: "web01"},
+	} {
+		assert.True(t, want.Time.Equal(entries[i].Time), i)
+		entries[i].Time = want.Time
+		assert.Equal(t, want, entries[i])
+	}
+
+	ctxEntries, err := ChangeLog(WithClient(context.Background(), obj.client), 42)
+	require.NoError(t, err)
+	assert.Len(t, ctxEntries, 3)
+}
+
+func TestHistoryErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status": "error", "message": "object not found"}`))
+	}))
+	defer server.Close()
+	client := mustClient(t, server.URL)
+
+	_, err := client.ChangeLog(context.Background(), 42)
+	require.ErrorContains(t, err, "object not found")
+
+	_, err = ChangeLog(context.Background(), 42)
+	require.Error(t, err)
+
+	created := &ServerObject{client: client, attributes: Attributes{"object_id": nil}, oldValues: Attributes{}}
+	_, err = created.History(context.Background())
+	require.Error(t, err)
+}
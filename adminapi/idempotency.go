@@ -0,0 +1,35 @@
+package adminapi
+
+import "context"
+
+// idempotencyKeyHeader carries the key identifying a commit across retries.
+// Servers honoring it apply a commit only once per key and answer repeated
+// requests with the original result.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context whose commits are sent with the given
+// Idempotency-Key instead of a generated one. Reusing the key when retrying a
+// failed Commit yourself, e.g. after a timeout, prevents the commit from being
+// applied twice.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// idempotencyKeyFromContext returns the key set by WithIdempotencyKey, or an
+// empty string.
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
+
+// ensureIdempotencyKey returns ctx carrying an idempotency key for commits to
+// endpoint, generating one if there is none yet. All attempts of a commit
+// share the key.
+func ensureIdempotencyKey(ctx context.Context, endpoint string) context.Context {
+	if endpoint != apiEndpointCommit || idempotencyKeyFromContext(ctx) != "" {
+		return ctx
+	}
+	return WithIdempotencyKey(ctx, newRequestID())
+}
@@ -0,0 +1,54 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			// the commit was applied, but the response got lost
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","commit_id":7}`))
+	}))
+	defer server.Close()
+
+	newClient := func(retryCommits bool) *Client {
+		client, err := NewClient(Config{
+			BaseURL: server.URL,
+			Token:   "tok",
+			Retry:   RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, RetryCommits: retryCommits},
+		})
+		require.NoError(t, err)
+		return client
+	}
+
+	commitID, err := newClient(true).sendCommit(context.Background(), commitRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 7, commitID)
+	require.Len(t, keys, 2)
+	assert.Len(t, keys[0], 32)
+	assert.Equal(t, keys[0], keys[1], "retries reuse the idempotency key")
+
+	keys = nil
+	_, err = newClient(false).sendCommit(WithIdempotencyKey(context.Background(), "my-key"), commitRequest{})
+	require.Error(t, err, "commits are not retried by default")
+	assert.Equal(t, []string{"my-key"}, keys)
+
+	keys = nil
+	q := newClient(false).NewQuery(Filters{})
+	_, _ = q.All(WithIdempotencyKey(context.Background(), "my-key"))
+	require.NotEmpty(t, keys)
+	assert.Empty(t, keys[0], "only commits carry the key")
+}
@@ -0,0 +1,54 @@
+package adminapi
+
+import (
+	"context"
+	"slices"
+)
+
+// ByIDs returns a query for the SA objects with the given object IDs, for
+// reconciliation jobs that already know which objects they care about. The
+// query is not bound to a client; it uses the one attached to the context
+// with WithClient, see Client.ByIDs for a bound one. The query is split into
+// several requests if there are more IDs than fit in one (see Limits);
+// ordering, Limit and Offset then apply to each request separately.
+func ByIDs(ids ...int) Query {
+	return newQuery(nil, Filters{"object_id": Any(ids...)})
+}
+
+// ByIDs returns a query for the SA objects with the given object IDs, bound to
+// this client. See the package-level ByIDs.
+func (c *Client) ByIDs(ids ...int) Query {
+	return newQuery(c, Filters{"object_id": Any(ids...)})
+}
+
+// streamIDChunks runs the query once per chunk of object IDs if it filters on
+// more of them than fit in one request, passing the objects of all chunks to
+// fn. It reports false without sending anything if the query needs no split.
+func (q *Query) streamIDChunks(ctx context.Context, client *Client, fn func(*ServerObject) error) (bool, error) {
+	ids, ok := objectIDList(q.filters)
+	if !ok || len(ids) <= client.maxQueryValues() {
+		return false, nil
+	}
+
+	for chunk := range slices.Chunk(ids, client.maxQueryValues()) {
+		sub := q.derive()
+		sub.filters["object_id"] = Any(chunk...)
+		err := sub.stream(ctx, fn)
+		q.warnings = append(q.warnings, sub.warnings...)
+		if err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// objectIDList returns the object IDs filters ask for if they filter on a
+// list of them, as ByIDs does.
+func objectIDList(filters Filters) ([]int, bool) {
+	filter, ok := filters["object_id"].(Filter)
+	if !ok || len(filter) != 1 {
+		return nil, false
+	}
+	ids, ok := filter["Any"].([]int)
+	return ids, ok
+}
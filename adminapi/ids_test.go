@@ -0,0 +1,59 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByIDs(t *testing.T) {
+	const ids = defaultMaxQueryValues*2 + 10
+
+	var chunkSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Filters struct {
+				ObjectID map[string][]int `json:"object_id"`
+			} `json:"filters"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		chunk := req.Filters.ObjectID["Any"]
+		chunkSizes = append(chunkSizes, len(chunk))
+
+		result := make([]Attributes, len(chunk))
+		for i, id := range chunk {
+			result[i] = Attributes{"object_id": id}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "result": result})
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	wanted := make([]int, ids)
+	for i := range wanted {
+		wanted[i] = i + 1
+	}
+
+	q := client.ByIDs(wanted...)
+	objects, err := q.All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{defaultMaxQueryValues, defaultMaxQueryValues, 10}, chunkSizes)
+	require.Len(t, objects, ids)
+	assert.Equal(t, ids, objects[ids-1].ObjectID())
+
+	chunkSizes = nil
+	unbound := ByIDs(1, 2, 3)
+	objects, err = unbound.All(WithClient(context.Background(), client))
+	require.NoError(t, err)
+	assert.Equal(t, []int{3}, chunkSizes, "short lists are sent in one request")
+	assert.Len(t, objects, 3)
+
+	unbound = ByIDs(1)
+	_, err = unbound.All(context.Background())
+	require.Error(t, err, "an unbound query needs a client in the context")
+}
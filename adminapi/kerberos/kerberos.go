@@ -0,0 +1,108 @@
+// Package kerberos provides SPNEGO authentication for Serveradmin instances
+// behind a Kerberos-enforcing gateway. It lives in its own package so that
+// only programs using it depend on a Kerberos implementation.
+//
+// Importing it also enables SERVERADMIN_AUTH=kerberos in
+// adminapi.NewClientFromEnv, with SERVERADMIN_KERBEROS_SPN overriding the
+// service principal.
+package kerberos
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+func init() {
+	adminapi.RegisterEnvAuthenticator("kerberos", func() (adminapi.Authenticator, error) {
+		return NewAuthenticator(Config{SPN: os.Getenv("SERVERADMIN_KERBEROS_SPN")})
+	})
+}
+
+// Config configures an Authenticator. Without Keytab, the
+// tickets of the user's credential cache (as obtained with kinit) are used.
+type Config struct {
+	// ConfigPath is the krb5.conf to use. Defaults to KRB5_CONFIG or
+	// /etc/krb5.conf.
+	ConfigPath string
+
+	// CCachePath is the credential cache to use. Defaults to KRB5CCNAME or
+	// /tmp/krb5cc_<uid>. Ignored when Keytab is set.
+	CCachePath string
+
+	// Keytab and Principal ("user@REALM") log in with a keytab instead of
+	// the credential cache, e.g. for service accounts.
+	Keytab    string
+	Principal string
+
+	// SPN is the service principal of the gateway. Defaults to HTTP/<host> of
+	// the request URL.
+	SPN string
+}
+
+// Authenticator is an adminapi.Authenticator authenticating requests with an SPNEGO
+// "Authorization: Negotiate" header, for Serveradmin instances behind a
+// Kerberos-enforcing gateway.
+type Authenticator struct {
+	client *client.Client
+	spn    string
+}
+
+// NewAuthenticator returns an Authenticator using the tickets described by
+// cfg. Set it as adminapi.Config.Authenticator.
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	if cfg.ConfigPath == "" {
+		cfg.ConfigPath = cmp.Or(os.Getenv("KRB5_CONFIG"), "/etc/krb5.conf")
+	}
+	krb5conf, err := config.Load(cfg.ConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: loading %s: %w", cfg.ConfigPath, err)
+	}
+
+	if cfg.Keytab != "" {
+		username, realm, ok := strings.Cut(cfg.Principal, "@")
+		if !ok || username == "" || realm == "" {
+			return nil, errors.New("kerberos: Principal must be user@REALM when using a keytab")
+		}
+		kt, err := keytab.Load(cfg.Keytab)
+		if err != nil {
+			return nil, fmt.Errorf("kerberos: loading keytab %s: %w", cfg.Keytab, err)
+		}
+		cl := client.NewWithKeytab(username, realm, kt, krb5conf, client.DisablePAFXFAST(true))
+		if err := cl.Login(); err != nil {
+			return nil, fmt.Errorf("kerberos: login as %s: %w", cfg.Principal, err)
+		}
+		return &Authenticator{client: cl, spn: cfg.SPN}, nil
+	}
+
+	if cfg.CCachePath == "" {
+		cfg.CCachePath = cmp.Or(strings.TrimPrefix(os.Getenv("KRB5CCNAME"), "FILE:"), fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid()))
+	}
+	ccache, err := credentials.LoadCCache(cfg.CCachePath)
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: loading credential cache %s (run kinit?): %w", cfg.CCachePath, err)
+	}
+	cl, err := client.NewFromCCache(ccache, krb5conf, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, fmt.Errorf("kerberos: %w", err)
+	}
+	return &Authenticator{client: cl, spn: cfg.SPN}, nil
+}
+
+// Apply sets the SPNEGO Authorization header.
+func (a *Authenticator) Apply(req *http.Request, _ []byte) error {
+	if err := spnego.SetSPNEGOHeader(a.client, req, a.spn); err != nil {
+		return fmt.Errorf("kerberos: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package kerberos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+func TestNewAuthenticator(t *testing.T) {
+	krb5conf := filepath.Join(t.TempDir(), "krb5.conf")
+	require.NoError(t, os.WriteFile(krb5conf, []byte("[libdefaults]\n  default_realm = EXAMPLE.COM\n"), 0o600))
+
+	t.Run("missing krb5.conf", func(t *testing.T) {
+		_, err := NewAuthenticator(Config{ConfigPath: "testdata/nope.conf"})
+		require.ErrorContains(t, err, "loading testdata/nope.conf")
+	})
+
+	t.Run("missing credential cache", func(t *testing.T) {
+		t.Setenv("KRB5CCNAME", "FILE:testdata/nope.ccache")
+		_, err := NewAuthenticator(Config{ConfigPath: krb5conf})
+		require.ErrorContains(t, err, "credential cache testdata/nope.ccache")
+	})
+
+	t.Run("keytab needs a principal", func(t *testing.T) {
+		_, err := NewAuthenticator(Config{ConfigPath: krb5conf, Keytab: "testdata/nope.keytab", Principal: "svc"})
+		require.ErrorContains(t, err, "user@REALM")
+	})
+}
+
+func TestEnvRegistration(t *testing.T) {
+	t.Setenv("SERVERADMIN_BASE_URL", "https://example.com")
+	t.Setenv("SERVERADMIN_AUTH", "kerberos")
+	t.Setenv("KRB5_CONFIG", "testdata/nope.conf")
+
+	_, err := adminapi.NewClientFromEnv()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kerberos: loading testdata/nope.conf")
+}
@@ -0,0 +1,122 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limits are the limits the server communicated in its most recent response
+// headers. Zero values mean the server has not (yet) announced the limit.
+//
+// The client honors them where it can do so safely: chunked queries use
+// MaxQueryValues, requests wait for RateLimitReset once RateLimitRemaining
+// dropped to zero or a 429 response asked to retry later, and commits exceeding
+// MaxCommitObjects fail early with ErrCommitTooLarge. Commits are not split, as
+// they would no longer be atomic.
+type Limits struct {
+	// MaxCommitObjects is the maximum number of objects in a single commit
+	// (X-Max-Commit-Objects).
+	MaxCommitObjects int
+
+	// MaxQueryValues is the maximum number of values in a single Any(...)
+	// filter (X-Max-Query-Values).
+	MaxQueryValues int
+
+	// RateLimit is the number of requests allowed per rate limit window
+	// (X-RateLimit-Limit).
+	RateLimit int
+
+	// RateLimitRemaining is the number of requests left in the current window
+	// (X-RateLimit-Remaining).
+	RateLimitRemaining int
+
+	// RateLimitReset is when the current window ends (X-RateLimit-Reset, in
+	// Unix seconds).
+	RateLimitReset time.Time
+}
+
+// limitsState holds the limits learned from responses, shared by all
+// requests of a Client.
+type limitsState struct {
+	mu     sync.Mutex
+	limits Limits
+	now    func() time.Time
+
+	// remainingKnown tells an exhausted rate limit from one never announced.
+	remainingKnown bool
+}
+
+// Limits returns the limits the server communicated so far. It makes no
+// request; all fields are zero until a response announced them.
+func (c *Client) Limits() Limits {
+	c.limits.mu.Lock()
+	defer c.limits.mu.Unlock()
+	return c.limits.limits
+}
+
+// update records the limits announced in header. Limits missing from header
+// keep their previous value.
+func (s *limitsState) update(header http.Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	setInt := func(name string, field *int) {
+		if v, err := strconv.Atoi(header.Get(name)); err == nil && v >= 0 {
+			*field = v
+		}
+	}
+	setInt("X-Max-Commit-Objects", &s.limits.MaxCommitObjects)
+	setInt("X-Max-Query-Values", &s.limits.MaxQueryValues)
+	setInt("X-RateLimit-Limit", &s.limits.RateLimit)
+	if header.Get("X-RateLimit-Remaining") != "" {
+		setInt("X-RateLimit-Remaining", &s.limits.RateLimitRemaining)
+		s.remainingKnown = true
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		s.limits.RateLimitReset = time.Unix(reset, 0)
+	}
+}
+
+// throttle waits until the current rate limit window ends if the server
+// announced that no requests are left in it, so that requests are not sent
+// just to be rejected. It returns early with ctx's error.
+func (s *limitsState) throttle(ctx context.Context) error {
+	s.mu.Lock()
+	var wait time.Duration
+	if s.remainingKnown && s.limits.RateLimitRemaining == 0 {
+		wait = s.limits.RateLimitReset.Sub(s.now())
+	}
+	s.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	return sleepCtx(ctx, wait)
+}
+
+// pause makes all requests wait for d, as asked by the Retry-After header of a
+// 429 response, by treating the current rate limit window as exhausted.
+func (s *limitsState) pause(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if reset := s.now().Add(d); reset.After(s.limits.RateLimitReset) {
+		s.limits.RateLimitReset = reset
+	}
+	s.limits.RateLimitRemaining = 0
+	s.remainingKnown = true
+}
+
+// maxQueryValues returns the number of values to send in a single Any(...)
+// filter: the server's limit if known, defaultMaxQueryValues otherwise.
+func (c *Client) maxQueryValues() int {
+	if c != nil {
+		if limit := c.Limits().MaxQueryValues; limit > 0 {
+			return limit
+		}
+	}
+	return defaultMaxQueryValues
+}
@@ -0,0 +1,93 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimits(t *testing.T) {
+	var chunkSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Max-Commit-Objects", "2")
+		w.Header().Set("X-Max-Query-Values", "3")
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "99")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+
+		var req struct {
+			Filters map[string]map[string][]any `json:"filters"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		chunkSizes = append(chunkSizes, len(req.Filters["hypervisor"]["Any"]))
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	assert.Equal(t, Limits{}, client.Limits(), "limits are unknown before the first response")
+	assert.Equal(t, defaultMaxQueryValues, client.maxQueryValues())
+
+	ctx := context.Background()
+	q := client.NewQuery(Filters{"servertype": "vm"})
+	_, err := q.All(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, Limits{
+		MaxCommitObjects:   2,
+		MaxQueryValues:     3,
+		RateLimit:          100,
+		RateLimitRemaining: 99,
+		RateLimitReset:     time.Unix(1700000000, 0),
+	}, client.Limits())
+
+	t.Run("chunking uses the announced query limit", func(t *testing.T) {
+		chunkSizes = nil
+		related := make(ServerObjects, 7)
+		for i := range related {
+			related[i] = &ServerObject{attributes: Attributes{"hostname": fmt.Sprintf("hv%d", i)}}
+		}
+		_, err := q.AllRelated(ctx, "hypervisor", related)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 3, 1}, chunkSizes)
+	})
+
+	t.Run("commits above the announced limit fail early", func(t *testing.T) {
+		objects := make(ServerObjects, 3)
+		for i := range objects {
+			objects[i] = &ServerObject{client: client, attributes: Attributes{"object_id": float64(i + 1)}, oldValues: Attributes{}}
+			objects[i].Delete()
+		}
+		_, err := objects.Commit(ctx)
+		require.ErrorIs(t, err, ErrCommitTooLarge)
+	})
+}
+
+func TestRateLimitThrottle(t *testing.T) {
+	now := time.Now()
+	limits := &limitsState{now: func() time.Time { return now }}
+
+	require.NoError(t, limits.throttle(context.Background()), "no rate limit announced")
+
+	limits.update(http.Header{"X-Ratelimit-Remaining": []string{"0"}, "X-Ratelimit-Reset": []string{strconv.FormatInt(now.Add(time.Hour).Unix(), 10)}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, limits.throttle(ctx), context.DeadlineExceeded, "exhausted rate limit waits for the reset")
+
+	now = now.Add(2 * time.Hour)
+	require.NoError(t, limits.throttle(context.Background()), "the window has ended")
+
+	limits.update(http.Header{"X-Ratelimit-Remaining": []string{"5"}})
+	now = now.Add(-2 * time.Hour)
+	require.NoError(t, limits.throttle(context.Background()), "requests are left")
+}
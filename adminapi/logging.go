@@ -0,0 +1,39 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// logRequest emits a structured event for a single request attempt to the
+// client's logger: completed requests at info level, failed ones at warn.
+func (c *Client) logRequest(ctx context.Context, endpoint string, payloadSize, attempt int, elapsed time.Duration, resp *http.Response, err error) {
+	if c.logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("endpoint", endpoint),
+		slog.String("request_id", RequestIDFromContext(ctx)),
+		slog.Duration("duration", elapsed),
+		slog.Int("payload_size", payloadSize),
+		slog.Int("retries", attempt-1),
+	}
+	var apiErr *APIError
+	switch {
+	case resp != nil:
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	case errors.As(err, &apiErr):
+		attrs = append(attrs, slog.Int("status", apiErr.StatusCode))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "serveradmin request failed", attrs...)
+		return
+	}
+	c.logger.LogAttrs(ctx, slog.LevelInfo, "serveradmin request", attrs...)
+}
@@ -0,0 +1,61 @@
+package adminapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	client, err := NewClient(Config{
+		BaseURL: server.URL,
+		Token:   "tok",
+		Retry:   RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+		Logger:  slog.New(slog.NewJSONHandler(&out, nil)),
+	})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{})
+	_, err = q.All(t.Context())
+	require.NoError(t, err)
+
+	var events []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var event map[string]any
+		require.NoError(t, dec.Decode(&event))
+		events = append(events, event)
+	}
+	require.Len(t, events, 2)
+
+	assert.Equal(t, "WARN", events[0]["level"])
+	assert.Equal(t, "serveradmin request failed", events[0]["msg"])
+	assert.InDelta(t, 502, events[0]["status"], 0)
+	assert.InDelta(t, 0, events[0]["retries"], 0)
+
+	assert.Equal(t, "INFO", events[1]["level"])
+	assert.Equal(t, apiEndpointQuery, events[1]["endpoint"])
+	assert.InDelta(t, 200, events[1]["status"], 0)
+	assert.InDelta(t, 1, events[1]["retries"], 0)
+	assert.Greater(t, events[1]["payload_size"], float64(0))
+	assert.Contains(t, events[1], "duration")
+}
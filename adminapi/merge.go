@@ -0,0 +1,83 @@
+package adminapi
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// MergeConflict is an attribute changed differently in ours and theirs.
+type MergeConflict struct {
+	Attribute string
+	Base      any
+	Ours      any
+	Theirs    any
+}
+
+// Merge performs an attribute-level three-way merge of two versions of the
+// same object: ours with local edits and theirs as currently on the server,
+// both derived from base. The result starts from theirs and carries our
+// changes as pending changes, so committing it sends only what we changed.
+//
+// An attribute changed on one side only takes that side's value. Multi
+// attributes changed on both sides are merged as sets: values we added or
+// removed are added to or removed from theirs. Any other attribute changed to
+// different values on both sides is a conflict: the merged object keeps
+// theirs and the conflict is reported, so the caller can resolve it with Set.
+func Merge(base, ours, theirs *ServerObject) (*ServerObject, []MergeConflict) {
+	merged := &ServerObject{
+		client:     cmp.Or(theirs.client, ours.client),
+		attributes: cloneAttributes(theirs.attributes),
+		oldValues:  Attributes{},
+	}
+
+	var conflicts []MergeConflict
+	for _, key := range slices.Sorted(maps.Keys(attributeKeys(base.attributes, ours.attributes))) {
+		baseVal, oursVal, theirsVal := base.attributes[key], ours.attributes[key], theirs.attributes[key]
+		if sameValue(baseVal, oursVal) || sameValue(oursVal, theirsVal) {
+			continue // unchanged by us, or both made the same change
+		}
+		if !sameValue(baseVal, theirsVal) {
+			if mergedVal, ok := mergeMulti(baseVal, oursVal, theirsVal); ok {
+				oursVal = mergedVal
+			} else {
+				conflicts = append(conflicts, MergeConflict{Attribute: key, Base: baseVal, Ours: oursVal, Theirs: theirsVal})
+				continue
+			}
+		}
+		if _, exists := merged.attributes[key]; !exists {
+			// attributes only we know of can't be set on the server version
+			conflicts = append(conflicts, MergeConflict{Attribute: key, Base: baseVal, Ours: oursVal})
+			continue
+		}
+		_ = merged.Set(key, oursVal)
+	}
+
+	return merged, conflicts
+}
+
+// mergeMulti merges multi attribute values as sets: theirs plus the values we
+// added minus the values we removed relative to base.
+func mergeMulti(base, ours, theirs any) ([]any, bool) {
+	baseVals, oursVals, theirsVals := toAnySlice(base), toAnySlice(ours), toAnySlice(theirs)
+	if oursVals == nil || theirsVals == nil {
+		return nil, false
+	}
+
+	contains := func(values []any, v any) bool {
+		return slices.ContainsFunc(values, func(other any) bool { return jsonEqual(v, other) })
+	}
+	merged := make([]any, 0, len(theirsVals))
+	for _, v := range theirsVals {
+		if contains(baseVals, v) && !contains(oursVals, v) {
+			continue // removed by us
+		}
+		merged = append(merged, v)
+	}
+	for _, v := range oursVals {
+		if !contains(baseVals, v) && !contains(merged, v) {
+			merged = append(merged, v) // added by us
+		}
+	}
+	return merged, true
+}
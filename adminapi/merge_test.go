@@ -0,0 +1,37 @@
+package adminapi
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	object := func(attrs Attributes) *ServerObject {
+		attrs["object_id"] = float64(1)
+		return &ServerObject{attributes: attrs, oldValues: Attributes{}}
+	}
+	base := object(Attributes{"hostname": "a", "state": "online", "owner": "x", "comment": "", "tags": []any{"a", "b"}})
+	ours := object(Attributes{"hostname": "a", "state": "maintenance", "owner": "y", "comment": "ours", "tags": []any{"a", "b", "c"}})
+	theirs := object(Attributes{"hostname": "b", "state": "online", "owner": "z", "comment": "ours", "tags": []any{"b", "d"}})
+
+	merged, conflicts := Merge(base, ours, theirs)
+
+	assert.Equal(t, "b", merged.GetString("hostname"), "their change is kept")
+	assert.Equal(t, "maintenance", merged.GetString("state"), "our change is applied")
+	assert.Equal(t, "ours", merged.GetString("comment"), "same change on both sides")
+	assert.Equal(t, []any{"b", "d", "c"}, merged.Get("tags"), "multi attributes merge as sets")
+
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, MergeConflict{Attribute: "owner", Base: "x", Ours: "y", Theirs: "z"}, conflicts[0])
+	assert.Equal(t, "z", merged.GetString("owner"), "conflicts keep their value")
+
+	// only our changes are pending relative to the server version
+	assert.Equal(t, StateChanged, merged.CommitState())
+	assert.ElementsMatch(t, []string{"state", "tags"}, slices.Collect(maps.Keys(merged.oldValues)))
+	assert.Equal(t, "maintenance", ours.GetString("state"), "inputs are not modified")
+	assert.Equal(t, []any{"b", "d"}, theirs.Get("tags"))
+}
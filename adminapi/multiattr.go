@@ -1,13 +1,19 @@
 package adminapi
 
-import "slices"
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
 
 // MultiAttr is a helper type for multi-valued attributes.
 // It provides set-like operations on string slices.
 //
 // MultiAttr maintains set semantics: Add prevents duplicates, Delete removes all
 // occurrences. Changes are made in-place but do NOT automatically update a ServerObject.
-// Users must call obj.Set() manually after modifications.
+// Users must call obj.Set() manually after modifications, or change the object
+// directly with AddToMulti, RemoveFromMulti and ClearMulti or the handle
+// returned by ServerObject.Multi.
 //
 // Example usage:
 //
@@ -74,3 +80,140 @@ func (m *MultiAttr) Clear() {
 func (m MultiAttr) Contains(elem string) bool {
 	return slices.Contains(m, elem)
 }
+
+// AddToMulti adds values to a multi attribute of the object, skipping values
+// it already holds, and tracks the change for commit:
+//
+//	obj.AddToMulti("tags", "web", "prod")
+//
+// It fails with ErrUnknownAttribute for attributes the object does not hold
+// and with ErrAttributeType for single-valued ones.
+func (s *ServerObject) AddToMulti(key string, values ...any) (err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
+	current, err := s.multiValues(key)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		if !containsJSONEqual(current, value) {
+			current = append(current, value)
+		}
+	}
+	return s.Set(key, current)
+}
+
+// RemoveFromMulti removes all occurrences of values from a multi attribute of
+// the object and tracks the change for commit. Values it does not hold are
+// ignored. It fails like AddToMulti.
+func (s *ServerObject) RemoveFromMulti(key string, values ...any) (err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
+	current, err := s.multiValues(key)
+	if err != nil {
+		return err
+	}
+	current = slices.DeleteFunc(current, func(elem any) bool {
+		return containsJSONEqual(values, elem)
+	})
+	return s.Set(key, current)
+}
+
+// ClearMulti removes all values from a multi attribute of the object and
+// tracks the change for commit. It fails like AddToMulti.
+func (s *ServerObject) ClearMulti(key string) (err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
+	if _, err := s.multiValues(key); err != nil {
+		return err
+	}
+	return s.Set(key, []any{})
+}
+
+// multiValues returns a copy of the values of a multi attribute, treating
+// null as empty.
+func (s *ServerObject) multiValues(key string) ([]any, error) {
+	value, ok := s.attributes[key]
+	if !ok {
+		return nil, fmt.Errorf("attribute %q: %w", key, ErrUnknownAttribute)
+	}
+	if value == nil {
+		return []any{}, nil
+	}
+	if reflect.ValueOf(value).Kind() != reflect.Slice {
+		return nil, fmt.Errorf("attribute %q: %w: %T is no multi attribute", key, ErrAttributeType, value)
+	}
+	return append([]any{}, toAnySlice(value)...), nil
+}
+
+// containsJSONEqual reports whether values contains value, comparing like
+// the change tracking does so that 1 and 1.0 are the same.
+func containsJSONEqual(values []any, value any) bool {
+	return slices.ContainsFunc(values, func(elem any) bool { return jsonEqual(elem, value) })
+}
+
+// BoundMultiAttr is a multi attribute of a ServerObject whose changes update
+// the object right away, see ServerObject.Multi.
+type BoundMultiAttr struct {
+	obj *ServerObject
+	key string
+}
+
+// Multi returns a handle to a multi attribute of the object with the
+// operations of MultiAttr, which unlike a MultiAttr from GetMulti change the
+// object right away, so there is no final Set to forget:
+//
+//	tags := obj.Multi("tags")
+//	tags.Add("web", "prod")
+//	tags.Delete("old-tag")
+//	obj.Commit(ctx)
+//
+// The operations fail like AddToMulti.
+func (s *ServerObject) Multi(key string) BoundMultiAttr {
+	return BoundMultiAttr{obj: s, key: key}
+}
+
+// Add adds elements the attribute does not hold yet.
+func (b BoundMultiAttr) Add(elems ...string) error {
+	return b.obj.AddToMulti(b.key, stringsToAny(elems)...)
+}
+
+// Delete removes all occurrences of the element.
+func (b BoundMultiAttr) Delete(elem string) error {
+	return b.obj.RemoveFromMulti(b.key, elem)
+}
+
+// Clear removes all elements.
+func (b BoundMultiAttr) Clear() error {
+	return b.obj.ClearMulti(b.key)
+}
+
+// Contains returns true if the element exists in the attribute.
+func (b BoundMultiAttr) Contains(elem string) bool {
+	return b.Values().Contains(elem)
+}
+
+// Values returns a copy of the current elements, like GetMulti.
+func (b BoundMultiAttr) Values() MultiAttr {
+	return slices.Clone(b.obj.GetMulti(b.key))
+}
+
+func stringsToAny(elems []string) []any {
+	values := make([]any, len(elems))
+	for i, elem := range elems {
+		values[i] = elem
+	}
+	return values
+}
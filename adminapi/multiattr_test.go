@@ -283,3 +283,65 @@ func TestMultiAttr_OrderPreservation(t *testing.T) {
 	m.Delete("second")
 	assert.Equal(t, MultiAttr{"first", "third", "fourth"}, m)
 }
+
+func TestServerObject_MultiHelpers(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id": float64(42),
+			"tags":      []any{"web", "old"},
+			"ports":     []any{float64(80)},
+			"services":  nil,
+			"hostname":  "web01",
+		},
+		oldValues: Attributes{},
+	}
+
+	require.NoError(t, obj.AddToMulti("tags", "prod", "web"))
+	require.NoError(t, obj.RemoveFromMulti("tags", "old", "missing"))
+	require.NoError(t, obj.AddToMulti("ports", 80, 443))
+	require.NoError(t, obj.AddToMulti("services", "nginx"))
+	assert.Equal(t, []any{"web", "prod"}, obj.Get("tags"))
+	assert.Equal(t, []any{float64(80), 443}, obj.Get("ports"))
+	assert.Equal(t, MultiAttr{"nginx"}, obj.GetMulti("services"))
+
+	changes := obj.serializeChanges()
+	assert.Equal(t, map[string]any{"action": "multi", "add": []any{"prod"}, "remove": []any{"old"}}, changes["tags"])
+	assert.Equal(t, map[string]any{"action": "multi", "add": []any{443}, "remove": []any{}}, changes["ports"])
+
+	require.NoError(t, obj.ClearMulti("tags"))
+	assert.Equal(t, []any{"web", "old"}, obj.oldValues["tags"], "original value is kept for the commit")
+	assert.ElementsMatch(t, []any{"web", "old"}, obj.serializeChanges()["tags"].(map[string]any)["remove"])
+
+	require.ErrorIs(t, obj.AddToMulti("hostname", "x"), ErrAttributeType)
+	require.ErrorIs(t, obj.RemoveFromMulti("unknown", "x"), ErrUnknownAttribute)
+	require.ErrorIs(t, obj.ClearMulti("hostname"), ErrAttributeType)
+	assert.Equal(t, "web01", obj.Get("hostname"))
+}
+
+func TestServerObject_Multi(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"object_id": float64(42), "tags": MultiAttr{"web", "old"}, "hostname": "web01"},
+		oldValues:  Attributes{},
+	}
+
+	tags := obj.Multi("tags")
+	require.NoError(t, tags.Add("prod", "web"))
+	require.NoError(t, tags.Delete("old"))
+	assert.True(t, tags.Contains("prod"))
+	assert.False(t, tags.Contains("old"))
+	assert.Equal(t, MultiAttr{"web", "prod"}, tags.Values())
+	assert.Equal(t, StateChanged, obj.CommitState())
+
+	changes := obj.serializeChanges()
+	assert.Equal(t, map[string]any{"action": "multi", "add": []any{"prod"}, "remove": []any{"old"}}, changes["tags"])
+
+	values := tags.Values()
+	values.Add("not-synced")
+	assert.False(t, tags.Contains("not-synced"), "Values returns a copy")
+
+	require.NoError(t, tags.Clear())
+	assert.Empty(t, tags.Values())
+
+	require.ErrorIs(t, obj.Multi("hostname").Add("x"), ErrAttributeType)
+	require.ErrorIs(t, obj.Multi("unknown").Clear(), ErrUnknownAttribute)
+}
@@ -0,0 +1,189 @@
+package adminapi
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortDirection is the direction of a sort key of a query.
+type SortDirection int
+
+const (
+	// Asc sorts in ascending order, the default.
+	Asc SortDirection = iota
+	// Desc sorts in descending order.
+	Desc
+)
+
+// sortKey is an attribute to sort query results by.
+type sortKey struct {
+	attribute string
+	direction SortDirection
+}
+
+// OrderBy sets the attribute to sort results by, replacing any previous
+// ordering. The optional direction defaults to Asc:
+//
+//	q.OrderBy("num_cpu", adminapi.Desc)
+//	q.ThenBy("hostname")
+//
+// An empty attribute removes the ordering.
+func (q *Query) OrderBy(attribute string, direction ...SortDirection) {
+	q.orderBy = nil
+	if attribute != "" {
+		q.ThenBy(attribute, direction...)
+	}
+}
+
+// ThenBy adds an attribute to sort results by when they are equal in all
+// attributes given to OrderBy and earlier ThenBy calls.
+func (q *Query) ThenBy(attribute string, direction ...SortDirection) {
+	key := sortKey{attribute: attribute}
+	if len(direction) > 0 {
+		key.direction = direction[0]
+	}
+	q.orderBy = append(q.orderBy, key)
+}
+
+// orderByValue returns the order_by value of a query request: a single
+// ascending attribute is sent as a plain string as always, anything else as
+// a list of attributes with descending ones prefixed by "-".
+func orderByValue(keys []sortKey) any {
+	switch {
+	case len(keys) == 0:
+		return nil
+	case len(keys) == 1 && keys[0].direction == Asc:
+		return keys[0].attribute
+	}
+
+	attributes := make([]string, len(keys))
+	for i, key := range keys {
+		attributes[i] = key.attribute
+		if key.direction == Desc {
+			attributes[i] = "-" + key.attribute
+		}
+	}
+	return attributes
+}
+
+// SortBy sorts the objects in place with the given comparison function, which
+// returns a negative number if a sorts before b, a positive one if after, and
+// zero if they are equal, in which case their order is kept. Unlike the
+// server-side OrderBy, it can sort by computed values:
+//
+//	objects.SortBy(func(a, b *adminapi.ServerObject) int {
+//		return cmp.Or(
+//			adminapi.CompareAttribute("datacenter")(a, b),
+//			cmp.Compare(a.GetInt("memory")/a.GetInt("num_cpu"), b.GetInt("memory")/b.GetInt("num_cpu")),
+//		)
+//	})
+func (s ServerObjects) SortBy(compare func(a, b *ServerObject) int) {
+	slices.SortStableFunc(s, compare)
+}
+
+// SortByAttribute sorts the objects in place by an attribute, see
+// CompareAttribute. The optional direction defaults to Asc.
+func (s ServerObjects) SortByAttribute(attribute string, direction ...SortDirection) {
+	s.SortBy(CompareAttribute(attribute, direction...))
+}
+
+// CompareAttribute returns a comparison function for SortBy ordering objects
+// by an attribute. Numbers are compared numerically and strings naturally, so
+// that "web2" sorts before "web10"; objects without the attribute come first.
+// The optional direction defaults to Asc.
+func CompareAttribute(attribute string, direction ...SortDirection) func(a, b *ServerObject) int {
+	desc := len(direction) > 0 && direction[0] == Desc
+	return func(a, b *ServerObject) int {
+		order := compareAttributeValues(a.attributes[attribute], b.attributes[attribute])
+		if desc {
+			return -order
+		}
+		return order
+	}
+}
+
+// compareAttributeValues orders empty values before booleans, numbers,
+// strings and anything else, and values of the same kind by their value.
+func compareAttributeValues(a, b any) int {
+	rankA, rankB := valueRank(a), valueRank(b)
+	if rankA != rankB {
+		return cmp.Compare(rankA, rankB)
+	}
+	switch rankA {
+	case 0:
+		return 0
+	case 1:
+		return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b)) // "false" < "true"
+	case 2:
+		x, _ := toFloat(a)
+		y, _ := toFloat(b)
+		return cmp.Compare(x, y)
+	case 3:
+		return naturalCompare(a.(string), b.(string))
+	default:
+		return naturalCompare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
+
+func valueRank(v any) int {
+	if _, ok := toFloat(v); ok {
+		return 2
+	}
+	switch v.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case string:
+		return 3
+	}
+	return 4
+}
+
+// toFloat converts the numeric values decoded from JSON or set by callers.
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// naturalCompare compares strings with runs of digits ordered by their
+// numeric value.
+func naturalCompare(a, b string) int {
+	for a != "" && b != "" {
+		digitsA, digitsB := leadingDigits(a), leadingDigits(b)
+		if digitsA == "" || digitsB == "" {
+			if a[0] != b[0] {
+				return cmp.Compare(a[0], b[0])
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		numA, numB := strings.TrimLeft(digitsA, "0"), strings.TrimLeft(digitsB, "0")
+		if order := cmp.Or(cmp.Compare(len(numA), len(numB)), strings.Compare(numA, numB)); order != 0 {
+			return order
+		}
+		a, b = a[len(digitsA):], b[len(digitsB):]
+	}
+	return cmp.Compare(len(a), len(b))
+}
+
+func leadingDigits(s string) string {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	return s[:end]
+}
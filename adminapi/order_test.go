@@ -0,0 +1,70 @@
+package adminapi
+
+import (
+	"cmp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBy(t *testing.T) {
+	q := mustClient(t, "https://example.com").NewQuery(Filters{})
+	assert.Nil(t, orderByValue(q.orderBy))
+
+	q.OrderBy("hostname")
+	assert.Equal(t, "hostname", orderByValue(q.orderBy), "a single ascending key stays a string")
+
+	q.OrderBy("num_cpu", Desc)
+	assert.Equal(t, []string{"-num_cpu"}, orderByValue(q.orderBy))
+
+	q.ThenBy("memory", Asc)
+	q.ThenBy("hostname")
+	assert.Equal(t, []string{"-num_cpu", "memory", "hostname"}, orderByValue(q.orderBy))
+
+	q.OrderBy("")
+	assert.Nil(t, orderByValue(q.orderBy))
+}
+
+func TestServerObjectsSortByAttribute(t *testing.T) {
+	objects := ServerObjects{
+		{attributes: Attributes{"hostname": "web10", "memory": float64(2048)}},
+		{attributes: Attributes{"hostname": "web2", "memory": 16384}},
+		{attributes: Attributes{"hostname": "db1"}},
+		{attributes: Attributes{"hostname": "web02a", "memory": float64(512)}},
+	}
+
+	objects.SortByAttribute("hostname")
+	assert.Equal(t, []string{"db1", "web2", "web02a", "web10"}, objects.Hostnames(), "digits are compared numerically")
+
+	objects.SortByAttribute("memory")
+	assert.Equal(t, []string{"db1", "web02a", "web10", "web2"}, objects.Hostnames(), "missing values first, then numerically")
+
+	objects.SortByAttribute("memory", Desc)
+	assert.Equal(t, []string{"web2", "web10", "web02a", "db1"}, objects.Hostnames())
+}
+
+func TestServerObjectsSortBy(t *testing.T) {
+	objects := ServerObjects{
+		{attributes: Attributes{"hostname": "b", "datacenter": "fra1", "num_cpu": 4}},
+		{attributes: Attributes{"hostname": "a", "datacenter": "ams1", "num_cpu": 2}},
+		{attributes: Attributes{"hostname": "c", "datacenter": "fra1", "num_cpu": 16}},
+		{attributes: Attributes{"hostname": "d", "datacenter": "ams1", "num_cpu": 2}},
+	}
+
+	objects.SortBy(func(a, b *ServerObject) int {
+		return cmp.Or(
+			CompareAttribute("datacenter")(a, b),
+			CompareAttribute("num_cpu", Desc)(a, b),
+		)
+	})
+	assert.Equal(t, []string{"a", "d", "c", "b"}, objects.Hostnames(), "equal objects keep their order")
+}
+
+func TestNaturalCompare(t *testing.T) {
+	assert.Negative(t, naturalCompare("web2", "web10"))
+	assert.Positive(t, naturalCompare("web10", "web9"))
+	assert.Zero(t, naturalCompare("web1", "web1"))
+	assert.Negative(t, naturalCompare("web", "web1"))
+	assert.Negative(t, naturalCompare("a10b", "a10c"))
+	assert.Negative(t, naturalCompare("1", "a"))
+}
@@ -1,157 +1,460 @@
 package adminapi
 
 import (
-	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // ParseQuery parses a string query (e.g. "hostname=11111") and returns a Filters map.
+// Besides "=", the comparison operators ">", ">=", "<", "<=" and "!=" are
+// accepted as shorthands for the GreaterThan, GreaterThanOrEquals, LessThan,
+// LessThanOrEquals and Not filters.
 //
 // Example forms:
 //
 //	"hostname=11111"                               => map: {"hostname": 11111}
 //	"hostname=regexp(foo.*) game_world=any(1 2 3)" => map: {"hostname": {"Regexp": "foo.*"}, "game_world": {"Any": [1, 2, 3]}}
 //	"hostname=Not(Empty())"                        => map: {"hostname": {"Not": {"Empty": nil}}}
+//	"num_cpu>=8 state!=retired"                    => map: {"num_cpu": {"GreaterThanOrEquals": 8}, "state": {"Not": "retired"}}
+//
+// Unquoted values are typed: integers like 8 become int, other numbers like
+// 1.5 or 1e3 float64, true and false bool and null nil, so that numeric and
+// boolean attributes compare correctly on the server. Anything else, and any
+// quoted value, is a string.
+//
+// Expressions may be joined with the word "and", which is ignored, as all
+// expressions have to match anyway.
+//
+// A word without operator is a hostname, as in the Serveradmin web UI:
+// "web01 state=online" reads as "hostname=web01 state=online". In it, "*"
+// matches any number of characters and "?" a single one, so "web*.example.com"
+// reads as "hostname=regexp(^web.*\.example\.com$)". Several of them match
+// any of the hostnames. Words with parentheses or quotes, like the typo
+// "any(web1 web2)", are no hostnames and fail.
+//
+// Alternatives are separated by ";" or the word "or", e.g.
+// "env=staging; env=dev state=online". If they differ in only one attribute,
+// they are combined into an Any filter on it: "env=staging or env=dev" reads
+// as "env=any(staging dev)". Other alternatives cannot be expressed as one
+// Filters map and fail with ErrQueryUnion; use ParseUnion or FromQuery for
+// them.
+//
+// Malformed queries fail with a *ParseError locating the problem.
+//
+// Values containing spaces, parentheses or ";" are quoted with " or ', or have
+// these characters escaped with a backslash. A backslash makes a following
+// space, parenthesis, "=", ";", quote or backslash literal and is dropped,
+// except in the argument of regexp(), where it is kept as it means the same to
+// the regular expression: "hostname=regexp(web\(1\))" matches the hostname
+// "web(1)". Backslashes before other characters, as in "regexp(web\d+)", are
+// always kept. Quoted values are never converted to numbers or booleans, and
+// only quotes and backslashes are escaped in them.
 func ParseQuery(query string) (Filters, error) {
-	query = strings.TrimSpace(query)
-	if query == "" {
-		return nil, errors.New("query must not be empty")
-	}
-	parts, err := splitPairs(query)
+	alternatives, err := ParseUnion(query)
 	if err != nil {
 		return nil, err
 	}
+	if len(alternatives) > 1 {
+		return nil, fmt.Errorf("%w: %d alternatives differ in more than one attribute", ErrQueryUnion, len(alternatives))
+	}
+	return alternatives[0], nil
+}
+
+// ParseUnion parses a string query like ParseQuery, but returns alternatives
+// that cannot be combined into one Filters map separately instead of failing.
+// An object matches the query if it matches any of them.
+func ParseUnion(query string) ([]Filters, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, &ParseError{Query: query, Message: "query must not be empty"}
+	}
 
+	p := &parser{input: query}
+	var alternatives []Filters
 	filters := make(Filters)
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
+	var hostnames []any
+	for {
+		p.skipSpace()
+		start := p.pos
+		if end := p.done(); end || p.parseSeparator() {
+			if len(filters) == 0 {
+				return nil, p.errorAt(start, "empty alternative")
+			}
+			alternatives = append(alternatives, filters)
+			if end {
+				return mergeAlternatives(alternatives), nil
+			}
+			filters = make(Filters)
+			hostnames = nil
 			continue
 		}
-		keyVal := strings.SplitN(part, "=", 2)
-		if len(keyVal) != 2 || strings.TrimSpace(keyVal[0]) == "" {
-			return nil, fmt.Errorf("invalid expression: %s", part)
+		if p.parseKeyword("and") {
+			continue
 		}
-		key := strings.TrimSpace(keyVal[0])
-		valStr := strings.TrimSpace(keyVal[1])
-
-		val, err := parseValue(valStr)
+		if hostname, ok := p.parseHostname(); ok {
+			hostnames = addHostname(filters, hostnames, hostname)
+			continue
+		}
+		key, value, err := p.parseExpression()
 		if err != nil {
-			return nil, fmt.Errorf("parsing %s: %w", part, err)
+			return nil, err
 		}
-		filters[key] = val
+		filters[key] = value
 	}
-	return filters, nil
 }
 
-// splitPairs splits a string into key=value chunks at spaces, but never inside nested parens or quotes
-func splitPairs(s string) ([]string, error) {
-	var res []string
-	start := 0
-	depth := 0
-	inQuotes := rune(0)
-	for i, r := range s {
-		switch {
-		case (r == '\'' || r == '"') && (i == 0 || s[i-1] != '\\'):
-			switch inQuotes {
-			case 0:
-				inQuotes = r
-			case r:
-				inQuotes = 0
-			}
-		case inQuotes != 0:
-			// do nothing
-		case r == '(':
-			depth++
-		case r == ')':
-			depth--
-			if depth < 0 {
-				return nil, errors.New("unmatched ) found")
-			}
-		case unicode.IsSpace(r) && depth == 0:
-			if start < i {
-				res = append(res, s[start:i])
+// parseHostname parses the hostname shorthand: a word without operator, like
+// "web01" or "web*.example.com", which stands for a hostname matching the
+// glob pattern. It reports false without consuming anything for other input,
+// including words with parentheses, quotes or brackets outside a character
+// class, so that typos like "any(web1 web2)" fail as invalid expressions.
+func (p *parser) parseHostname() (any, bool) {
+	start := p.pos
+	end := start + strings.IndexFunc(p.input[start:], isKeyDelimiter)
+	if end < start {
+		end = len(p.input)
+	}
+	if end == start || (end < len(p.input) && strings.ContainsRune("=<>!", rune(p.input[end]))) {
+		return nil, false
+	}
+	if !isHostnamePattern(p.input[start:end]) {
+		return nil, false
+	}
+	p.pos = end
+	return globFilter(p.input[start:end]), true
+}
+
+// isHostnamePattern reports whether word can be a hostname glob pattern: it
+// has no parentheses, quotes or brackets, except around character classes.
+func isHostnamePattern(word string) bool {
+	for i := 0; i < len(word); i++ {
+		switch word[i] {
+		case '[':
+			class, ok := globClass(word[i:])
+			if !ok {
+				return false
 			}
-			start = i + 1
+			i += class.length - 1
+		case ']', '(', ')', '"', '\'':
+			return false
 		}
 	}
-	if depth != 0 {
-		return nil, errors.New("unmatched ( found")
+	return true
+}
+
+// addHostname adds hostname to the hostnames of the alternative read so far,
+// setting its hostname filter to any of them, and returns the new list.
+func addHostname(filters Filters, hostnames []any, hostname any) []any {
+	if !slices.ContainsFunc(hostnames, func(h any) bool { return reflect.DeepEqual(h, hostname) }) {
+		hostnames = append(hostnames, hostname)
 	}
-	if start < len(s) {
-		res = append(res, s[start:])
+	filters["hostname"] = anyFilter(slices.Clone(hostnames))
+	return hostnames
+}
+
+// isKeyDelimiter reports whether r ends an attribute name.
+func isKeyDelimiter(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("=<>!;", r)
+}
+
+// globFilter returns the filter matching the glob pattern: the pattern
+// itself if it has no wildcards, or Glob(pattern).
+func globFilter(pattern string) any {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern
 	}
-	return res, nil
+	return Glob(pattern)
 }
 
-// parseValue parses any individual left-hand side after the '='. It handles integers,
-// floats, booleans, quoted strings, and function-based filters like Regexp(...).
-func parseValue(s string) (any, error) {
-	s = strings.TrimSpace(s)
-	// Recognize quoted strings
-	if l := len(s); l >= 2 && ((s[0] == '"' && s[l-1] == '"') || (s[0] == '\'' && s[l-1] == '\'')) {
-		return s[1 : l-1], nil
+// parseSeparator skips a ";" or "or" separating alternatives, reporting
+// whether there was one.
+func (p *parser) parseSeparator() bool {
+	if p.peek() == ';' {
+		p.pos++
+		return true
 	}
+	return p.parseKeyword("or")
+}
 
-	// Try int
-	if i, err := strconv.Atoi(s); err == nil {
-		return i, nil
+// parseKeyword skips the word keyword in any case, reporting whether it is
+// next.
+func (p *parser) parseKeyword(keyword string) bool {
+	rest := p.input[p.pos:]
+	if len(rest) < len(keyword) || !strings.EqualFold(rest[:len(keyword)], keyword) {
+		return false
+	}
+	if after, size := utf8.DecodeRuneInString(rest[len(keyword):]); size > 0 && !unicode.IsSpace(after) {
+		return false
 	}
+	p.pos += len(keyword)
+	return true
+}
 
-	// Try float
-	if f, err := strconv.ParseFloat(s, 64); err == nil {
-		return f, nil
+// comparisonFilters maps the comparison operators besides "=" to the filter
+// they stand for, e.g. "num_cpu>=8" for "num_cpu=greaterthanorequals(8)".
+var comparisonFilters = map[string]string{
+	">=": "GreaterThanOrEquals",
+	"<=": "LessThanOrEquals",
+	">":  "GreaterThan",
+	"<":  "LessThan",
+	"!=": "Not",
+}
+
+// parser reads a query string from left to right.
+type parser struct {
+	input string
+	pos   int
+
+	// for ParsePartial: the open filter functions and the start of the value
+	// read last
+	functions  []string
+	valueStart int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+// peek returns the next byte, or 0 at the end of the input.
+func (p *parser) peek() byte {
+	if p.done() {
+		return 0
 	}
+	return p.input[p.pos]
+}
 
-	// Try bool
-	switch s {
-	case "true":
-		return true, nil
-	case "false":
-		return false, nil
+func (p *parser) atSpace() bool {
+	if p.done() {
+		return false
 	}
+	r, _ := utf8.DecodeRuneInString(p.input[p.pos:])
+	return unicode.IsSpace(r)
+}
+
+func (p *parser) skipSpace() {
+	for p.atSpace() {
+		_, size := utf8.DecodeRuneInString(p.input[p.pos:])
+		p.pos += size
+	}
+}
 
-	// Detect function filters: functionName(...)
-	idx := strings.Index(s, "(")
-	if idx > 0 && s[len(s)-1] == ')' {
-		fn := s[:idx]
-		argsBody := s[idx+1 : len(s)-1]
+// token returns the input from start up to the next space, for error messages.
+func (p *parser) token(start int) string {
+	end := strings.IndexFunc(p.input[start:], unicode.IsSpace)
+	if end < 0 {
+		return p.input[start:]
+	}
+	return p.input[start : start+end]
+}
 
-		// Convert "ReGEXP" -> "regexp" for the lookup
-		fnLower := strings.ToLower(strings.TrimSpace(fn))
-		canonicalFn, ok := allFilters[fnLower]
-		if !ok {
-			return nil, fmt.Errorf("invalid filter function: %s", fn)
+// errorAt returns a ParseError for the token at offset.
+func (p *parser) errorAt(offset int, format string, args ...any) *ParseError {
+	return &ParseError{
+		Query:   p.input,
+		Offset:  offset,
+		Token:   p.token(offset),
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// parseExpression parses "attribute=value" or a comparison like
+// "attribute>=value".
+func (p *parser) parseExpression() (string, any, error) {
+	start := p.pos
+	for !p.done() && !p.atSpace() && !strings.ContainsRune("=<>!;", rune(p.peek())) {
+		p.pos++
+	}
+	key := p.input[start:p.pos]
+	op := p.parseOperator()
+	if key == "" || op == "" {
+		return "", nil, p.errorAt(start, "invalid expression")
+	}
+
+	valueStart := p.pos
+	value, err := p.parseValue(false, false)
+	if err != nil {
+		return "", nil, err
+	}
+	p.valueStart = valueStart
+	if fn := comparisonFilters[op]; fn != "" {
+		value = createFilter(fn, value)
+	}
+	return key, value, nil
+}
+
+// parseOperator parses a comparison operator, returning "" if there is none.
+func (p *parser) parseOperator() string {
+	rest := p.input[p.pos:]
+	var op string
+	switch {
+	case strings.HasPrefix(rest, ">="), strings.HasPrefix(rest, "<="), strings.HasPrefix(rest, "!="):
+		op = rest[:2]
+	case strings.HasPrefix(rest, "="), strings.HasPrefix(rest, ">"), strings.HasPrefix(rest, "<"):
+		op = rest[:1]
+	default:
+		return ""
+	}
+	p.pos += len(op)
+	return op
+}
+
+// parseValue parses a value: a quoted string, a filter function like
+// regexp(...) or a plain word, which is converted to a number or boolean if
+// it looks like one. Nested values are arguments of a filter function. Raw
+// values keep the backslashes of escapes.
+func (p *parser) parseValue(nested, raw bool) (any, error) {
+	p.valueStart = p.pos
+	var value any
+	var err error
+	if c := p.peek(); c == '"' || c == '\'' {
+		value, err = p.parseQuoted(raw)
+	} else {
+		start := p.pos
+		var word string
+		var escaped bool
+		word, escaped = p.parseWord(raw)
+		switch {
+		case p.peek() == '(' && (word == "" || escaped):
+			return nil, p.errorAt(p.pos, "( without filter function")
+		case p.peek() == '(':
+			value, err = p.parseFunction(word, start)
+		case escaped:
+			value = word
+		default:
+			value = parseLiteral(word)
 		}
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		// Parse arguments (handle empty arg like Empty())
-		//goland:noinspection GoPreferNilSlice
-		argVals := []any{}
-		if strings.TrimSpace(argsBody) != "" {
-			argParts, err := splitPairs(argsBody)
-			if err != nil {
-				return nil, err
-			}
-			for _, ap := range argParts {
-				val, err := parseValue(ap)
-				if err != nil {
-					return nil, err
+	switch {
+	case p.done(), p.atSpace(), nested && p.peek() == ')', !nested && p.peek() == ';':
+		return value, nil
+	case p.peek() == ')':
+		return nil, p.errorAt(p.pos, "unmatched ) found")
+	default:
+		return nil, p.errorAt(p.pos, "unexpected %q after value", p.peek())
+	}
+}
+
+// isEscapable reports whether a backslash before r makes it literal.
+func isEscapable(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`()=;"'\`, r)
+}
+
+// parseWord reads an unquoted value up to the next unescaped space,
+// parenthesis or ";", reporting whether it contained escapes.
+func (p *parser) parseWord(raw bool) (word string, escaped bool) {
+	var sb strings.Builder
+	for !p.done() {
+		c := p.peek()
+		if c == '\\' && p.pos+1 < len(p.input) {
+			if r, size := utf8.DecodeRuneInString(p.input[p.pos+1:]); isEscapable(r) {
+				if raw {
+					sb.WriteByte(c)
 				}
-				argVals = append(argVals, val)
+				sb.WriteString(p.input[p.pos+1 : p.pos+1+size])
+				p.pos += 1 + size
+				escaped = true
+				continue
+			}
+		}
+		if c == '(' || c == ')' || c == ';' || p.atSpace() {
+			break
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	return sb.String(), escaped
+}
+
+// parseQuoted reads a value quoted with " or ', in which a backslash escapes
+// the quote and itself.
+func (p *parser) parseQuoted(raw bool) (string, error) {
+	start := p.pos
+	quote := p.peek()
+	p.pos++
+	var sb strings.Builder
+	for !p.done() {
+		c := p.peek()
+		if c == '\\' && p.pos+1 < len(p.input) && (p.input[p.pos+1] == quote || p.input[p.pos+1] == '\\') {
+			if raw {
+				sb.WriteByte(c)
 			}
+			sb.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		p.pos++
+		if c == quote {
+			return sb.String(), nil
 		}
+		sb.WriteByte(c)
+	}
+	return "", p.errorAt(start, "unterminated %c quote", quote)
+}
+
+// parseFunction parses the arguments of a filter function like any(1 2 3),
+// whose name starting at start has already been read. A single argument
+// becomes the filter's value, several or none a list.
+func (p *parser) parseFunction(name string, start int) (any, error) {
+	canonical, ok := lookupFilter(name)
+	if !ok {
+		return nil, p.errorAt(start, "invalid filter function %s", name)
+	}
+	open := p.pos
+	p.pos++ // (
+	p.functions = append(p.functions, canonical)
 
-		// Single arg: Not(x), Regexp(y), etc.
-		if len(argVals) == 1 {
-			return Filter{canonicalFn: argVals[0]}, nil
+	//goland:noinspection GoPreferNilSlice
+	args := []any{}
+	for {
+		p.skipSpace()
+		if p.done() {
+			return nil, p.errorAt(open, "unmatched ( found")
+		}
+		if p.peek() == ')' {
+			p.pos++
+			break
+		}
+		arg, err := p.parseValue(true, canonical == "Regexp")
+		if err != nil {
+			return nil, err
 		}
-		// Multi arg: Any(1 2 3), All(a b c)
-		return Filter{canonicalFn: argVals}, nil
+		args = append(args, arg)
 	}
+	p.functions = p.functions[:len(p.functions)-1]
 
-	// If not a filter, treat as simple string
-	return s, nil
+	if canonical == "Empty" && len(args) == 1 && args[0] == nil {
+		args = args[:0] // empty() takes no argument, so empty(null) reads as empty()
+	}
+	if len(args) == 1 {
+		return Filter{canonical: args[0]}, nil
+	}
+	return Filter{canonical: args}, nil
+}
+
+// parseLiteral converts an unquoted word to an int, float, bool or nil if it
+// looks like one.
+func parseLiteral(s string) any {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	return s
 }
@@ -39,6 +39,21 @@ func TestParseQuery(t *testing.T) {
 			query: "active=true",
 			want:  Filters{"active": true},
 		},
+		{
+			name:  "null value",
+			query: "primary_ip6=null backup_server=not(null)",
+			want:  Filters{"primary_ip6": nil, "backup_server": Filter{"Not": nil}},
+		},
+		{
+			name:  "typed filter arguments",
+			query: `num_cpu=any(4 2.5 1e3 false null "null" "4")`,
+			want:  Filters{"num_cpu": Filter{"Any": []any{4, 2.5, 1000.0, false, nil, "null", "4"}}},
+		},
+		{
+			name:  "empty with null argument",
+			query: "hostname=empty(null)",
+			want:  Filters{"hostname": Filter{"Empty": []any{}}},
+		},
 		{
 			name:  "Regexp filter",
 			query: "hostname=regexp(foo.*)",
@@ -69,10 +84,91 @@ func TestParseQuery(t *testing.T) {
 			query: "hostname=foo id=123 active=false",
 			want:  Filters{"hostname": "foo", "id": 123, "active": false},
 		},
+		{
+			name:  "comparison operators",
+			query: "num_cpu>=8 memory<65536 disk_size_gib>100 cores<=4 state!=retired",
+			want: Filters{
+				"num_cpu":       Filter{"GreaterThanOrEquals": 8},
+				"memory":        Filter{"LessThan": 65536},
+				"disk_size_gib": Filter{"GreaterThan": 100},
+				"cores":         Filter{"LessThanOrEquals": 4},
+				"state":         Filter{"Not": "retired"},
+			},
+		},
+		{
+			name:  "comparison with filter value",
+			query: "hostname!=regexp(web.*) load<0.5",
+			want:  Filters{"hostname": Filter{"Not": Filter{"Regexp": "web.*"}}, "load": Filter{"LessThan": 0.5}},
+		},
+		{
+			name:  "comparison characters in value",
+			query: "description=a<b!",
+			want:  Filters{"description": "a<b!"},
+		},
+		{
+			name:  "escaped space, parens and equals",
+			query: `description=two\ words\ \(a\=b\) path=C:\\tmp`,
+			want:  Filters{"description": "two words (a=b)", "path": `C:\tmp`},
+		},
+		{
+			name:  "escaped non-ASCII space",
+			query: "description=a\\\u00a0b",
+			want:  Filters{"description": "a\u00a0b"},
+		},
+		{
+			name:  "escapes are kept in regexp",
+			query: `hostname=regexp(web\(1\)\ \d+)`,
+			want:  Filters{"hostname": Filter{"Regexp": `web\(1\)\ \d+`}},
+		},
+		{
+			name:  "escaped parens in filter arguments",
+			query: `tags=any(a\(1\) b)`,
+			want:  Filters{"tags": Filter{"Any": []any{"a(1)", "b"}}},
+		},
+		{
+			name:  "escaped quotes in quoted strings",
+			query: `description="say \"hi\"" other='it\'s' path="C:\\tmp\d"`,
+			want:  Filters{"description": `say "hi"`, "other": "it's", "path": `C:\tmp\d`},
+		},
+		{
+			name:  "quoted regexp with parens",
+			query: `hostname=regexp("web (1|2)")`,
+			want:  Filters{"hostname": Filter{"Regexp": "web (1|2)"}},
+		},
+		{
+			name:  "quoted number stays a string",
+			query: `game_world="12"`,
+			want:  Filters{"game_world": "12"},
+		},
 		// --- Broken/Invalid syntax cases ---
 		{
-			name:        "missing equals",
-			query:       "hostnamefoo",
+			name:  "hostname shorthand",
+			query: "hostnamefoo",
+			want:  Filters{"hostname": "hostnamefoo"},
+		},
+		{
+			name:  "hostname shorthand with glob",
+			query: "web*.example.com state=online",
+			want:  Filters{"hostname": Filter{"Regexp": `^web.*\.example\.com$`}, "state": "online"},
+		},
+		{
+			name:  "several hostname shorthands",
+			query: "web01 db?? ",
+			want:  Filters{"hostname": Filter{"Any": []any{"web01", Filter{"Regexp": "^db..$"}}}},
+		},
+		{
+			name:  "hostname shorthand with character class",
+			query: "web[12]",
+			want:  Filters{"hostname": Filter{"Regexp": "^web[12]$"}},
+		},
+		{
+			name:  "and keyword",
+			query: "environment=production AND datacenter=fra1 and android",
+			want:  Filters{"environment": "production", "datacenter": "fra1", "hostname": "android"},
+		},
+		{
+			name:        "shorthand followed by an operator",
+			query:       "web! state=online",
 			expectError: true,
 		},
 		{
@@ -95,11 +191,51 @@ func TestParseQuery(t *testing.T) {
 			query:       "id=StrangeFunc[1 2]",
 			expectError: true,
 		},
+		{
+			name:        "filter function without attribute",
+			query:       "any(web1 web2)",
+			expectError: true,
+		},
+		{
+			name:        "quoted hostname shorthand",
+			query:       `"web01" state=online`,
+			expectError: true,
+		},
 		{
 			name:        "empty key",
 			query:       "=123",
 			expectError: true,
 		},
+		{
+			name:        "unmatched closing paren",
+			query:       "hostname=web)",
+			expectError: true,
+		},
+		{
+			name:        "unterminated quote",
+			query:       `description="open`,
+			expectError: true,
+		},
+		{
+			name:        "text after quoted string",
+			query:       `description="a"b`,
+			expectError: true,
+		},
+		{
+			name:        "parens without function",
+			query:       "hostname=(web)",
+			expectError: true,
+		},
+		{
+			name:        "empty key with operator",
+			query:       ">=8",
+			expectError: true,
+		},
+		{
+			name:        "lone exclamation mark",
+			query:       "state!retired",
+			expectError: true,
+		},
 		{
 			name:  "missing value",
 			query: "field=",
@@ -175,3 +311,44 @@ func BenchmarkParseQuery_Complex(b *testing.B) {
 		}
 	}
 }
+
+func TestParseQueryError(t *testing.T) {
+	tests := []struct {
+		query   string
+		offset  int
+		token   string
+		message string
+	}{
+		{query: "", offset: 0, token: "", message: "query must not be empty"},
+		{query: "state=online web!", offset: 13, token: "web!", message: "invalid expression"},
+		{query: "state=online any(web1 web2)", offset: 13, token: "any(web1", message: "invalid expression"},
+		{query: "hostname=any(web1 web2", offset: 12, token: "(web1", message: "unmatched ( found"},
+		{query: "hostname=web) state=online", offset: 12, token: ")", message: "unmatched ) found"},
+		{query: "hostname=nope(web)", offset: 9, token: "nope(web)", message: "invalid filter function nope"},
+		{query: `a=1 description="open`, offset: 16, token: `"open`, message: "unterminated \" quote"},
+		{query: "hostname=not(regexp(a)b)", offset: 22, token: "b)", message: `unexpected 'b' after value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.query, parseErr.Query)
+			assert.Equal(t, tt.offset, parseErr.Offset)
+			assert.Equal(t, tt.token, parseErr.Token)
+			assert.Equal(t, tt.message, parseErr.Message)
+		})
+	}
+}
+
+func TestParseErrorCaret(t *testing.T) {
+	_, err := ParseQuery("hostname=any(web1 web2")
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "unmatched ( found at offset 12: (web1", parseErr.Error())
+	assert.Equal(t, "hostname=any(web1 web2\n            ^", parseErr.Caret())
+
+	_, err = ParseQuery("descr=\"ü\"\thostname=web)")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "descr=\"ü\"\thostname=web)\n         \t            ^", parseErr.Caret(), "runes count once, tabs are kept")
+}
@@ -0,0 +1,153 @@
+package adminapi
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// CompletionKind tells what is being typed at the end of a partial query.
+type CompletionKind int
+
+const (
+	// CompleteAttribute is an attribute name starting a new expression.
+	CompleteAttribute CompletionKind = iota
+	// CompleteValue is the value of an expression or an argument of a filter
+	// function.
+	CompleteValue
+)
+
+// PartialQuery describes a query string that is still being typed, for tab
+// completion in interactive shells.
+type PartialQuery struct {
+	// Filters holds the complete expressions of the alternative being typed.
+	Filters Filters
+	Kind    CompletionKind
+	// Attribute is the attribute whose value is being typed.
+	Attribute string
+	// Function is the innermost filter function whose argument is being
+	// typed, e.g. "Regexp", or empty outside filter functions.
+	Function string
+	// Prefix is the partially typed token at the end of the query, starting
+	// at byte Offset. Completing the query replaces it with a continuation.
+	Prefix string
+	Offset int
+	// Continuations are the tokens Prefix can be completed to: the given
+	// attribute names for CompleteAttribute, filter functions like "regexp("
+	// and the literals true, false and null for CompleteValue, and ")" to
+	// close an empty filter function argument.
+	Continuations []string
+}
+
+// ParsePartial parses a query string that is still being typed, such as
+// "hostname=reg" or "env=staging game_world=any(1 ", and reports what is
+// being typed at its end and how it can be continued. attributes are the
+// attribute names to complete, e.g. those of the Serveradmin schema. Errors
+// before the end of the query fail with a *ParseError like ParseQuery.
+func ParsePartial(query string, attributes ...string) (*PartialQuery, error) {
+	p := &parser{input: query}
+	filters := make(Filters)
+	var hostnames []any
+	for {
+		p.skipSpace()
+		if p.done() {
+			return completeAttribute(filters, query, len(query), attributes), nil
+		}
+		if p.parseSeparator() {
+			filters = make(Filters)
+			hostnames = nil
+			continue
+		}
+		if p.parseKeyword("and") {
+			continue
+		}
+		start := p.pos
+		keyEnd := strings.IndexFunc(query[start:], isKeyDelimiter)
+		if keyEnd < 0 {
+			return completeAttribute(filters, query, start, attributes), nil
+		}
+		attribute := query[start : start+keyEnd]
+		if hostname, ok := p.parseHostname(); ok {
+			hostnames = addHostname(filters, hostnames, hostname)
+			continue
+		}
+
+		key, value, err := p.parseExpression()
+		var parseErr *ParseError
+		switch {
+		case err == nil && p.done():
+			return completeValue(filters, query, p.valueStart, attribute, ""), nil
+		case err == nil:
+			filters[key] = value
+		case !errors.As(err, &parseErr) || !isIncomplete(parseErr):
+			return nil, err
+		default:
+			var function string
+			if len(p.functions) > 0 {
+				function = p.functions[len(p.functions)-1]
+			}
+			offset := p.valueStart
+			if !strings.HasPrefix(parseErr.Message, "unterminated") &&
+				(strings.HasSuffix(query, "(") || strings.TrimRightFunc(query, unicode.IsSpace) != query) {
+				offset = len(query) // a new argument
+			}
+			return completeValue(filters, query, offset, attribute, function), nil
+		}
+	}
+}
+
+// isIncomplete reports whether err is due to the query ending too early.
+func isIncomplete(err *ParseError) bool {
+	return err.Message == "unmatched ( found" || strings.HasPrefix(err.Message, "unterminated")
+}
+
+// completeAttribute completes the attribute name starting at offset.
+func completeAttribute(filters Filters, query string, offset int, attributes []string) *PartialQuery {
+	prefix := query[offset:]
+	var continuations []string
+	for _, attribute := range attributes {
+		if strings.HasPrefix(attribute, prefix) {
+			continuations = append(continuations, attribute)
+		}
+	}
+	slices.Sort(continuations)
+	return &PartialQuery{
+		Filters:       filters,
+		Kind:          CompleteAttribute,
+		Prefix:        prefix,
+		Offset:        offset,
+		Continuations: slices.Compact(continuations),
+	}
+}
+
+// completeValue completes the value starting at offset.
+func completeValue(filters Filters, query string, offset int, attribute, function string) *PartialQuery {
+	prefix := query[offset:]
+	var continuations []string
+	if function != "" && prefix == "" {
+		continuations = append(continuations, ")")
+	}
+	if !strings.HasPrefix(prefix, `"`) && !strings.HasPrefix(prefix, "'") {
+		lower := strings.ToLower(prefix)
+		for _, name := range filterNames() {
+			if strings.HasPrefix(name, lower) {
+				continuations = append(continuations, name+"(")
+			}
+		}
+		for _, literal := range []string{"true", "false", "null"} {
+			if strings.HasPrefix(literal, prefix) {
+				continuations = append(continuations, literal)
+			}
+		}
+	}
+	return &PartialQuery{
+		Filters:       filters,
+		Kind:          CompleteValue,
+		Attribute:     attribute,
+		Function:      function,
+		Prefix:        prefix,
+		Offset:        offset,
+		Continuations: continuations,
+	}
+}
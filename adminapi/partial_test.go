@@ -0,0 +1,164 @@
+package adminapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePartial(t *testing.T) {
+	attributes := []string{"hostname", "hypervisor", "game_world", "state"}
+	tests := []struct {
+		name  string
+		query string
+		want  PartialQuery
+	}{
+		{
+			name:  "empty query",
+			query: "",
+			want: PartialQuery{
+				Filters:       Filters{},
+				Kind:          CompleteAttribute,
+				Continuations: []string{"game_world", "hostname", "hypervisor", "state"},
+			},
+		},
+		{
+			name:  "attribute prefix",
+			query: "state=online h",
+			want: PartialQuery{
+				Filters:       Filters{"state": "online"},
+				Kind:          CompleteAttribute,
+				Prefix:        "h",
+				Offset:        13,
+				Continuations: []string{"hostname", "hypervisor"},
+			},
+		},
+		{
+			name:  "new expression",
+			query: "state=online ",
+			want: PartialQuery{
+				Filters:       Filters{"state": "online"},
+				Kind:          CompleteAttribute,
+				Offset:        13,
+				Continuations: []string{"game_world", "hostname", "hypervisor", "state"},
+			},
+		},
+		{
+			name:  "value prefix",
+			query: "hostname=reg",
+			want: PartialQuery{
+				Filters:       Filters{},
+				Kind:          CompleteValue,
+				Attribute:     "hostname",
+				Prefix:        "reg",
+				Offset:        9,
+				Continuations: []string{"regexp("},
+			},
+		},
+		{
+			name:  "comparison value",
+			query: "game_world>=n",
+			want: PartialQuery{
+				Filters:       Filters{},
+				Kind:          CompleteValue,
+				Attribute:     "game_world",
+				Prefix:        "n",
+				Offset:        12,
+				Continuations: []string{"not(", "null"},
+			},
+		},
+		{
+			name:  "filter function argument",
+			query: "game_world=any(1 e",
+			want: PartialQuery{
+				Filters:       Filters{},
+				Kind:          CompleteValue,
+				Attribute:     "game_world",
+				Function:      "Any",
+				Prefix:        "e",
+				Offset:        17,
+				Continuations: []string{"empty("},
+			},
+		},
+		{
+			name:  "new filter function argument",
+			query: "state=online hostname=not(",
+			want: PartialQuery{
+				Filters:   Filters{"state": "online"},
+				Kind:      CompleteValue,
+				Attribute: "hostname",
+				Function:  "Not",
+				Offset:    26,
+				Continuations: []string{
+					")", "all(", "any(", "containedby(", "containedonlyby(", "contains(", "empty(",
+					"greaterthan(", "greaterthanorequals(", "lessthan(", "lessthanorequals(", "not(",
+					"overlaps(", "regexp(", "startswith(", "true", "false", "null",
+				},
+			},
+		},
+		{
+			name:  "unterminated quote",
+			query: `hostname=regexp("web `,
+			want: PartialQuery{
+				Filters:   Filters{},
+				Kind:      CompleteValue,
+				Attribute: "hostname",
+				Function:  "Regexp",
+				Prefix:    `"web `,
+				Offset:    16,
+			},
+		},
+		{
+			name:  "after hostname shorthand",
+			query: "web* s",
+			want: PartialQuery{
+				Filters:       Filters{"hostname": Filter{"Regexp": "^web.*$"}},
+				Kind:          CompleteAttribute,
+				Prefix:        "s",
+				Offset:        5,
+				Continuations: []string{"state"},
+			},
+		},
+		{
+			name:  "after alternative",
+			query: "state=online; st",
+			want: PartialQuery{
+				Filters:       Filters{},
+				Kind:          CompleteAttribute,
+				Prefix:        "st",
+				Offset:        14,
+				Continuations: []string{"state"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePartial(tt.query, attributes...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+}
+
+func TestParsePartialError(t *testing.T) {
+	_, err := ParsePartial("web! state=", "hostname")
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "invalid expression", parseErr.Message)
+
+	_, err = ParsePartial("hostname=any(a)) state=on")
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, "unmatched ) found", parseErr.Message)
+}
+
+func TestParsePartialHostnamesMatchParseQuery(t *testing.T) {
+	for _, query := range []string{"web1 web2", "web1 web2 web1", "web* db?? state=online", "web[12] web3"} {
+		want, err := ParseQuery(query)
+		require.NoError(t, err)
+
+		partial, err := ParsePartial(query+" st", "state")
+		require.NoError(t, err)
+		assert.Equal(t, want, partial.Filters, query)
+	}
+}
@@ -0,0 +1,50 @@
+package adminapi
+
+import "context"
+
+// PendingQuery is a query running in the background, see Query.Start.
+type PendingQuery struct {
+	done    chan struct{}
+	objects ServerObjects
+	err     error
+}
+
+// Start runs the query in a new goroutine and returns immediately, so the
+// request can be pipelined with other work, e.g. another query or API call:
+//
+//	pending := vmQuery.Start(ctx)
+//	hypervisors, err := hvQuery.All(ctx)
+//	...
+//	vms, err := pending.Await()
+//
+// The query runs on a copy, so the query itself is neither loaded nor affected
+// by changes made to it meanwhile. A loaded query completes immediately with
+// its loaded results. Cancelling ctx aborts the request.
+func (q *Query) Start(ctx context.Context) *PendingQuery {
+	p := &PendingQuery{done: make(chan struct{})}
+	if q.loaded {
+		p.objects = q.serverObjects
+		close(p.done)
+		return p
+	}
+
+	running := q.Clone()
+	go func() {
+		defer close(p.done)
+		p.objects, p.err = running.All(ctx)
+	}()
+	return p
+}
+
+// Await waits for the query to complete and returns its results like
+// Query.All. It may be called any number of times.
+func (p *PendingQuery) Await() (ServerObjects, error) {
+	<-p.done
+	return p.objects, p.err
+}
+
+// Done returns a channel that is closed once the query completed, e.g. to
+// wait for several pending queries in a select.
+func (p *PendingQuery) Done() <-chan struct{} {
+	return p.done
+}
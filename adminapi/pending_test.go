@@ -0,0 +1,66 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryStart(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"web1"}]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"hostname": "web1"})
+	pending := q.Start(context.Background())
+	q.AddFilter("state", "online") // doesn't affect the running query
+
+	select {
+	case <-pending.Done():
+		t.Fatal("query completed before the server answered")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	objects, err := pending.Await()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web1"}, objects.Hostnames())
+	assert.False(t, q.loaded, "the query itself is not loaded")
+
+	again, err := pending.Await()
+	require.NoError(t, err)
+	assert.Equal(t, objects, again)
+
+	_, err = q.All(context.Background())
+	require.NoError(t, err)
+	loaded := q.Start(context.Background())
+	<-loaded.Done()
+	objects, err = loaded.Await()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web1"}, objects.Hostnames())
+}
+
+func TestQueryStartCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"hostname": "web1"})
+	ctx, cancel := context.WithCancel(context.Background())
+	pending := q.Start(ctx)
+	cancel()
+	_, err := pending.Await()
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,61 @@
+package adminapi
+
+import (
+	"net/http"
+	"os"
+	"runtime/debug"
+)
+
+// Provenance identifies the automation run a commit originates from. It is
+// sent with every commit as X-Provenance-* headers, so changes in the CMDB can
+// be traced back to the tool, build and CI job that made them. Empty fields
+// are omitted.
+type Provenance struct {
+	// Tool is the name of the calling program, e.g. "dns-sync".
+	Tool string
+
+	// Commit is the VCS revision the calling binary was built from.
+	Commit string
+
+	// JobURL links to the CI job or pipeline run performing the change.
+	JobURL string
+}
+
+// ProvenanceFromBuild returns a Provenance for tool with Commit taken from
+// the binary's embedded VCS information and JobURL from the environment of
+// common CI systems (GitLab CI, GitHub Actions, Jenkins).
+func ProvenanceFromBuild(tool string) *Provenance {
+	p := &Provenance{Tool: tool, JobURL: ciJobURL()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				p.Commit = setting.Value
+			}
+		}
+	}
+	return p
+}
+
+// ciJobURL returns the URL of the current CI job, or "" outside of CI.
+func ciJobURL() string {
+	if url := os.Getenv("CI_JOB_URL"); url != "" {
+		return url
+	}
+	if server, repo, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"); server != "" && repo != "" && run != "" {
+		return server + "/" + repo + "/actions/runs/" + run
+	}
+	return os.Getenv("BUILD_URL")
+}
+
+// setHeaders adds the non-empty provenance fields to header.
+func (p *Provenance) setHeaders(header http.Header) {
+	for name, value := range map[string]string{
+		"X-Provenance-Tool":    p.Tool,
+		"X-Provenance-Commit":  p.Commit,
+		"X-Provenance-Job-URL": p.JobURL,
+	} {
+		if value != "" {
+			header.Set(name, value)
+		}
+	}
+}
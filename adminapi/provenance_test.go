@@ -0,0 +1,61 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenanceHeaders(t *testing.T) {
+	headers := map[string]http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers[r.URL.Path] = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == apiEndpointCommit {
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a.local"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		BaseURL:    server.URL,
+		Token:      "tok",
+		Provenance: &Provenance{Tool: "dns-sync", JobURL: "https://ci.example.com/jobs/42"},
+	})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{"hostname": "a.local"})
+	obj, err := q.One(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, obj.Set("hostname", "b.local"))
+	_, err = obj.Commit(context.Background())
+	require.NoError(t, err)
+
+	commit := headers[apiEndpointCommit]
+	assert.Equal(t, "dns-sync", commit.Get("X-Provenance-Tool"))
+	assert.Equal(t, "https://ci.example.com/jobs/42", commit.Get("X-Provenance-Job-URL"))
+	assert.NotContains(t, commit, "X-Provenance-Commit", "empty fields are omitted")
+
+	assert.Empty(t, headers[apiEndpointQuery].Get("X-Provenance-Tool"), "only commits carry provenance")
+}
+
+func TestProvenanceFromBuild(t *testing.T) {
+	t.Setenv("CI_JOB_URL", "")
+	t.Setenv("BUILD_URL", "")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "innogames/tool")
+	t.Setenv("GITHUB_RUN_ID", "7")
+
+	p := ProvenanceFromBuild("tool")
+	assert.Equal(t, "tool", p.Tool)
+	assert.Equal(t, "https://github.com/innogames/tool/actions/runs/7", p.JobURL)
+
+	t.Setenv("CI_JOB_URL", "https://gitlab.example.com/job/1")
+	assert.Equal(t, "https://gitlab.example.com/job/1", ProvenanceFromBuild("tool").JobURL)
+}
@@ -5,17 +5,28 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"reflect"
 	"slices"
+	"time"
 )
 
 // Query is a struct to build a query to the SA API
 type Query struct {
 	client               *Client
 	filters              Filters
+	alternatives         []Filters // objects match any of them, see NewUnionQuery
 	restrictedAttributes []string
-	orderBy              string
+	relatedAttributes    []relatedRestriction
+	orderBy              []sortKey
+	limit                int
+	offset               int
+	timeout              time.Duration
+	includeRetired       bool
 	loaded               bool
 	serverObjects        ServerObjects
+	warnings             []string
 }
 
 // Attributes is a map of attributes, indexed by attribute name
@@ -28,7 +39,11 @@ func (a Attributes) Has(key string) bool {
 }
 
 // FromQuery creates a new Query object from a query string, bound to this client.
-func (c *Client) FromQuery(query string) (Query, error) {
+// Alternatives that ParseQuery cannot combine are sent as separate requests,
+// see NewUnionQuery.
+func (c *Client) FromQuery(query string) (_ Query, err error) {
+	defer recoverPanic(c, &err)
+
 	return newQueryFromString(c, query)
 }
 
@@ -37,21 +52,131 @@ func (c *Client) NewQuery(filters Filters) Query {
 	return newQuery(c, filters)
 }
 
+// GetByHostname returns the SA object with the given hostname, fetching the
+// given attributes in addition to object_id and hostname. It returns
+// ErrNoResults if there is no such object. It uses the client attached to ctx
+// with WithClient, see Client.GetByHostname for a bound one.
+func GetByHostname(ctx context.Context, hostname string, attributes ...string) (*ServerObject, error) {
+	return getByHostname(ctx, nil, hostname, attributes)
+}
+
+// GetByHostname returns the SA object with the given hostname using this
+// client. See the package-level GetByHostname.
+func (c *Client) GetByHostname(ctx context.Context, hostname string, attributes ...string) (*ServerObject, error) {
+	return getByHostname(ctx, c, hostname, attributes)
+}
+
+func getByHostname(ctx context.Context, client *Client, hostname string, attributes []string) (*ServerObject, error) {
+	q := newQuery(client, Filters{"hostname": hostname})
+	q.AddAttributes(attributes...)
+	return q.One(ctx)
+}
+
 func newQuery(client *Client, filters Filters) Query {
 	return Query{
 		client:               client,
 		filters:              filters,
-		restrictedAttributes: []string{"object_id", "hostname"},
+		restrictedAttributes: defaultAttributes(filters),
 	}
 }
 
+// defaultAttributes returns the attributes queries fetch unless SetAttributes
+// is called: object_id and hostname, and servertype for queries that may
+// match several servertypes, e.g. with servertype=any(vm hypervisor) or
+// alternatives, so ServerType and FilterByType can split their results.
+func defaultAttributes(filters ...Filters) []string {
+	var serverTypes []string
+	for _, f := range filters {
+		value, ok := f["servertype"]
+		if !ok {
+			continue
+		}
+		serverType, isString := value.(string)
+		if !isString {
+			return []string{"object_id", "hostname", "servertype"}
+		}
+		if !slices.Contains(serverTypes, serverType) {
+			serverTypes = append(serverTypes, serverType)
+		}
+	}
+	if len(serverTypes) > 1 {
+		return []string{"object_id", "hostname", "servertype"}
+	}
+	return []string{"object_id", "hostname"}
+}
+
 func newQueryFromString(client *Client, query string) (Query, error) {
-	filters, err := ParseQuery(query)
+	alternatives, err := ParseUnion(query)
 	if err != nil {
 		return Query{}, fmt.Errorf("parsing query %s: %w", query, err)
 	}
 
-	return newQuery(client, filters), nil
+	q := newQuery(client, alternatives[0])
+	if len(alternatives) > 1 {
+		q.filters = Filters{}
+		q.alternatives = alternatives
+		q.restrictedAttributes = defaultAttributes(alternatives...)
+	}
+	return q, nil
+}
+
+// Clone returns an unloaded deep copy of the query: filters, attributes,
+// ordering and paging can be changed on either without affecting the other,
+// e.g. to fork a base query per environment or servertype.
+func (q *Query) Clone() Query {
+	clone := q.derive()
+	for attribute, filter := range clone.filters {
+		clone.filters[attribute] = cloneFilterValue(filter)
+	}
+	for i, alternative := range clone.alternatives {
+		clone.alternatives[i] = cloneFilterValue(alternative).(Filters)
+	}
+	return clone
+}
+
+// cloneFilterValue deep-copies a filter value, including the maps of nested
+// filters and the slices of Any, All and similar filters.
+func cloneFilterValue(value any) any {
+	switch value := value.(type) {
+	case Filters:
+		clone := make(Filters, len(value))
+		for key, v := range value {
+			clone[key] = cloneFilterValue(v)
+		}
+		return clone
+	case Filter:
+		clone := make(Filter, len(value))
+		for key, v := range value {
+			clone[key] = cloneFilterValue(v)
+		}
+		return clone
+	case map[string]any:
+		clone := make(map[string]any, len(value))
+		for key, v := range value {
+			clone[key] = cloneFilterValue(v)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(value))
+		for i, v := range value {
+			clone[i] = cloneFilterValue(v)
+		}
+		return clone
+	case []Filter:
+		clone := make([]Filter, len(value))
+		for i, v := range value {
+			clone[i] = cloneFilterValue(v).(Filter)
+		}
+		return clone
+	}
+
+	// slices of plain values, e.g. the []string of Any("a", "b")
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && !v.IsNil() {
+		clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(clone, v)
+		return clone.Interface()
+	}
+	return value
 }
 
 // SetAttributes replaces the list of attributes to fetch from the API
@@ -64,9 +189,16 @@ func (q *Query) AddAttributes(attributes ...string) {
 	q.restrictedAttributes = append(q.restrictedAttributes, attributes...)
 }
 
-// OrderBy sets the attribute to sort results by
-func (q *Query) OrderBy(attribute string) {
-	q.orderBy = attribute
+// Limit makes the query return at most n objects, e.g. to page through large
+// result sets together with Offset. Zero means no limit.
+func (q *Query) Limit(n int) {
+	q.limit = n
+}
+
+// Offset makes the query skip the first n matching objects. Combine it with
+// OrderBy so that pages are stable across requests.
+func (q *Query) Offset(n int) {
+	q.offset = n
 }
 
 // AddFilter adds or updates a filter for the specified attribute
@@ -74,19 +206,38 @@ func (q *Query) AddFilter(attribute string, filter any) {
 	q.filters[attribute] = filter
 }
 
-// Count matching SA objects
-func (q *Query) Count(ctx context.Context) (int, error) {
-	err := q.load(ctx)
+// Count matching SA objects. With Limit or Offset set, it counts the objects of
+// the requested page, not all matching ones. Unless the query is already
+// loaded, it requests only the object_id of the matches and doesn't keep them,
+// so counting large result sets doesn't download full objects.
+func (q *Query) Count(ctx context.Context) (_ int, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	if q.loaded {
+		return len(q.serverObjects), nil
+	}
+
+	sub := q.derive()
+	sub.restrictedAttributes = []string{"object_id"}
+	sub.relatedAttributes = nil
+	sub.orderBy = nil
+	count := 0
+	err = sub.stream(ctx, func(*ServerObject) error {
+		count++
+		return nil
+	})
+	q.warnings = sub.warnings
 	if err != nil {
 		return 0, err
 	}
-
-	return len(q.serverObjects), nil
+	return count, nil
 }
 
 // All returns all matching SA objects
-func (q *Query) All(ctx context.Context) (ServerObjects, error) {
-	err := q.load(ctx)
+func (q *Query) All(ctx context.Context) (_ ServerObjects, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	err = q.load(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -96,8 +247,10 @@ func (q *Query) All(ctx context.Context) (ServerObjects, error) {
 
 // One returns exactly one matching SA object. If there is none or more than one, an error is returned.
 // Returns ErrNoResults if no objects match, or a wrapped ErrMultipleResults if more than one matches.
-func (q *Query) One(ctx context.Context) (*ServerObject, error) {
-	err := q.load(ctx)
+func (q *Query) One(ctx context.Context) (_ *ServerObject, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	err = q.load(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -112,15 +265,181 @@ func (q *Query) One(ctx context.Context) (*ServerObject, error) {
 	}
 }
 
+// Invalidate drops the loaded results, so that the next call to All, One,
+// Count or any other method running the query sends it again. Objects
+// returned before stay valid.
+func (q *Query) Invalidate() {
+	q.loaded = false
+	q.serverObjects = nil
+}
+
+// Refresh runs the query again, replacing the loaded results, e.g. to reuse a
+// query in a monitoring loop instead of rebuilding it every iteration.
+func (q *Query) Refresh(ctx context.Context) (err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	q.Invalidate()
+	return q.load(ctx)
+}
+
+// OneOrNil is like One, but returns nil without an error if no object matches,
+// for the common create-if-missing pattern. More than one match is still a
+// wrapped ErrMultipleResults.
+func (q *Query) OneOrNil(ctx context.Context) (_ *ServerObject, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	obj, err := q.One(ctx)
+	if errors.Is(err, ErrNoResults) {
+		return nil, nil //nolint:nilnil // no match is not an error here
+	}
+	return obj, err
+}
+
+// First returns the first matching SA object, in the order set with OrderBy,
+// or ErrNoResults if none matches. Unlike One, more than one match is fine; only
+// one object is requested from the server unless the query is already loaded.
+func (q *Query) First(ctx context.Context) (_ *ServerObject, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	objects := q.serverObjects
+	if !q.loaded {
+		sub := q.derive()
+		sub.limit = 1
+		objects, err = sub.All(ctx)
+		q.warnings = sub.warnings
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(objects) == 0 {
+		return nil, ErrNoResults
+	}
+	return objects[0], nil
+}
+
+// Exists reports whether any SA object matches the query. Unless the query is
+// already loaded, it requests only the object_id of at most one object, so
+// existence checks don't download full objects.
+func (q *Query) Exists(ctx context.Context) (_ bool, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	if q.loaded {
+		return len(q.serverObjects) > 0, nil
+	}
+
+	sub := q.derive()
+	sub.restrictedAttributes = []string{"object_id"}
+	sub.relatedAttributes = nil
+	sub.orderBy = nil
+	sub.limit = 1
+	objects, err := sub.All(ctx)
+	q.warnings = sub.warnings
+	if err != nil {
+		return false, err
+	}
+	return len(objects) > 0, nil
+}
+
 func (q *Query) load(ctx context.Context) error {
 	if q.loaded {
 		return nil
 	}
 
-	client, err := q.resolveClient()
+	serverObjects := ServerObjects{}
+	err := q.stream(ctx, func(obj *ServerObject) error {
+		serverObjects = append(serverObjects, obj)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
+	q.serverObjects = serverObjects
+	q.loaded = true
+
+	return nil
+}
+
+// Stream runs the query and passes the matching objects to fn one at a time,
+// as they are decoded from the response, instead of collecting them all in
+// memory first. If fn returns an error, the remaining objects are skipped and
+// Stream returns that error. The results are not kept in the query; if it was
+// loaded before, fn is called with the loaded objects without a new request.
+func (q *Query) Stream(ctx context.Context, fn func(*ServerObject) error) (err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	if q.loaded {
+		for _, obj := range q.serverObjects {
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return q.stream(ctx, fn)
+}
+
+// errStopIteration ends a stream when the loop over Query.Iter breaks.
+var errStopIteration = errors.New("iteration stopped")
+
+// Iter runs the query and returns an iterator over the matching objects,
+// yielding each one as soon as it is decoded from the response:
+//
+//	for obj, err := range q.Iter(ctx) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// A failing request or response is yielded as a final error. Like Stream, it
+// keeps no results in the query and uses the loaded objects if there are any.
+// Panics are not recovered, as they may come from the loop body.
+func (q *Query) Iter(ctx context.Context) iter.Seq2[*ServerObject, error] {
+	return func(yield func(*ServerObject, error) bool) {
+		fn := func(obj *ServerObject) error {
+			if !yield(obj, nil) {
+				return errStopIteration
+			}
+			return nil
+		}
+
+		var err error
+		if q.loaded {
+			for _, obj := range q.serverObjects {
+				if err = fn(obj); err != nil {
+					break
+				}
+			}
+		} else {
+			err = q.stream(ctx, fn)
+		}
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(nil, err)
+		}
+	}
+}
+
+// stream sends the query and decodes the response incrementally, passing each
+// object to fn.
+func (q *Query) stream(ctx context.Context, fn func(*ServerObject) error) error {
+	ctx, cancel, wrapTimeout := q.withQueryTimeout(ctx)
+	defer cancel()
+	return wrapTimeout(q.streamResults(ctx, fn))
+}
+
+func (q *Query) streamResults(ctx context.Context, fn func(*ServerObject) error) error {
+	client, err := q.resolveClient(ctx)
+	if err != nil {
+		return err
+	}
+	q.warnings = nil
+	if split, err := q.streamAlternatives(ctx, fn); split {
+		return err
+	}
+	if split, err := q.streamIDChunks(ctx, client, fn); split {
+		return err
+	}
 
 	// always add "object_id" as attribute as we need it to modify the object
 	if !slices.Contains(q.restrictedAttributes, "object_id") {
@@ -128,54 +447,150 @@ func (q *Query) load(ctx context.Context) error {
 	}
 
 	request := queryRequest{
-		Filters:    q.filters,
-		Restricted: q.restrictedAttributes,
-		OrderBy:    q.orderBy, // todo fix serverside ordering in API or do it on client side
+		Filters:    q.requestFilters(client),
+		Restricted: q.restrictValue(),
+		OrderBy:    orderByValue(q.orderBy), // todo fix serverside ordering in API or do it on client side
+		Limit:      q.limit,
+		Offset:     q.offset,
+	}
+
+	// map attribute map into ServerObject objects, stamping the client so later
+	// Commit calls reuse the same configuration.
+	newObject := func(object Attributes) *ServerObject {
+		obj := &ServerObject{
+			client:     client,
+			attributes: object,
+			oldValues:  Attributes{},
+		}
+		obj.extractRelated(q.relatedAttributes)
+		return obj
+	}
+
+	var cacheKey string
+	if client.queryCache != nil {
+		key, err := json.Marshal(request)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		cacheKey = string(key)
+		if objects, ok := client.queryCache.get(cacheKey); ok {
+			for _, object := range objects {
+				if err := fn(newObject(cloneAttributes(object))); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
 	}
 
 	resp, err := client.sendRequest(ctx, apiEndpointQuery, request)
 	if err != nil {
 		return fmt.Errorf("querying %s: %w", apiEndpointQuery, err)
 	}
-	defer resp.Body.Close()
+	defer closeBody(resp.Body)
 
-	respServer := queryResponse{}
-	if err = json.NewDecoder(resp.Body).Decode(&respServer); err != nil {
-		return fmt.Errorf("decoding query response: %w", err)
+	var cached []Attributes
+	warnings, err := decodeQueryResult(resp.Body, func(object Attributes) error {
+		if cacheKey != "" {
+			cached = append(cached, cloneAttributes(object))
+		}
+		return fn(newObject(object))
+	})
+	if err != nil {
+		return err
 	}
+	if cacheKey != "" {
+		client.queryCache.put(cacheKey, cached)
+	}
+	q.warnings = warnings
+	client.reportQueryWarnings(q, warnings)
+	return nil
+}
 
-	// map attribute map into ServerObject objects, stamping the client so later
-	// Commit calls reuse the same configuration.
-	q.serverObjects = make(ServerObjects, len(respServer.Result))
-	for idx, object := range respServer.Result {
-		q.serverObjects[idx] = &ServerObject{
-			client:     client,
-			attributes: object,
-			oldValues:  Attributes{},
+// decodeQueryResult decodes a query response from r token by token, passing
+// each object of its "result" array to fn as soon as it is read, and returns
+// the response's warnings. Other fields of the response are skipped. A
+// response looks like
+// {"status": "success", "result": [{"object_id": 483903, "hostname": "foo.local"}]}
+func decodeQueryResult(r io.Reader, fn func(Attributes) error) (warnings []string, err error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, fmt.Errorf("decoding query response: %w", err)
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decoding query response: %w", err)
+		}
+		if key != "result" {
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return nil, fmt.Errorf("decoding query response: %w", err)
+			}
+			if key == "warnings" {
+				warnings = parseWarnings(skipped)
+			}
+			continue
+		}
+
+		start, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("decoding query result: %w", err)
+		}
+		if start == nil {
+			continue // "result": null
+		}
+		if start != json.Delim('[') {
+			return nil, fmt.Errorf("decoding query result: expected [, got %v", start)
+		}
+		for dec.More() {
+			var object Attributes
+			if err := dec.Decode(&object); err != nil {
+				return nil, fmt.Errorf("decoding query result: %w", err)
+			}
+			if err := fn(object); err != nil {
+				return nil, err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return nil, fmt.Errorf("decoding query result: %w", err)
 		}
 	}
-	q.loaded = true
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, fmt.Errorf("decoding query response: %w", err)
+	}
+	return warnings, nil
+}
 
+// expectDelim reads the next token from dec and fails unless it is delim.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %s, got %v", delim, token)
+	}
 	return nil
 }
 
-// resolveClient returns the query's bound client.
-func (q *Query) resolveClient() (*Client, error) {
-	if q.client == nil {
-		return nil, errors.New("query is not bound to a client; use Client.NewQuery or Client.FromQuery")
+// resolveClient returns the query's bound client, falling back to the client
+// attached to ctx with WithClient.
+func (q *Query) resolveClient(ctx context.Context) (*Client, error) {
+	if q.client != nil {
+		return q.client, nil
+	}
+	if client, ok := FromContext(ctx); ok {
+		return client, nil
 	}
-	return q.client, nil
+	return nil, errors.New("query is not bound to a client; use Client.NewQuery or Client.FromQuery, or use WithClient")
 }
 
 // like {"Filters": {"hostname": {"Regexp": "foo.local.*"}}, "restrict": ["hostname", "object_id"]}
 type queryRequest struct {
 	Filters    map[string]any `json:"filters"`
-	Restricted []string       `json:"restrict"`
-	OrderBy    string         `json:"order_by,omitempty"`
-}
-
-// like {"status": "success", "result": [{"object_id": 483903, "hostname": "foo.local"}]}
-type queryResponse struct {
-	Status string       `json:"status"`
-	Result []Attributes `json:"result"`
+	Restricted []any          `json:"restrict"`           // attribute names and related restrictions, see restrictValue
+	OrderBy    any            `json:"order_by,omitempty"` // string or []string, see orderByValue
+	Limit      int            `json:"limit,omitempty"`
+	Offset     int            `json:"offset,omitempty"`
 }
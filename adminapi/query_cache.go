@@ -0,0 +1,76 @@
+package adminapi
+
+import (
+	"sync"
+	"time"
+)
+
+// queryCache caches query results by the serialized query request for
+// Config.QueryCacheTTL. Commits and API calls through the client clear it, as
+// they may change which objects match any query.
+type queryCache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]queryCacheEntry
+}
+
+type queryCacheEntry struct {
+	objects []Attributes
+	fetched time.Time
+}
+
+func newQueryCache(ttl time.Duration) *queryCache {
+	return &queryCache{ttl: ttl, now: time.Now, entries: map[string]queryCacheEntry{}}
+}
+
+// get returns the cached, unexpired result of the query request key. The
+// objects must be copied before handing them out.
+func (c *queryCache) get(key string) ([]Attributes, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.now().Sub(entry.fetched) >= c.ttl {
+		return nil, false
+	}
+	return entry.objects, true
+}
+
+// put stores the result of the query request key, dropping expired entries.
+func (c *queryCache) put(key string, objects []Attributes) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.ttl {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = queryCacheEntry{objects: objects, fetched: now}
+}
+
+// clear drops all cached results.
+func (c *queryCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+// InvalidateQueryCache drops all query results cached for Config.QueryCacheTTL,
+// e.g. after the inventory was changed by another client. Commits and API
+// calls through this client do so automatically.
+func (c *Client) InvalidateQueryCache() {
+	c.queryCache.clear()
+}
@@ -0,0 +1,66 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCache(t *testing.T) {
+	var queries int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == apiEndpointCommit {
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}`))
+			return
+		}
+		queries++
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a","tags":["x"]}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", QueryCacheTTL: time.Minute})
+	require.NoError(t, err)
+	now := time.Now()
+	client.queryCache.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	query := func(hostname string) *ServerObject {
+		t.Helper()
+		q := client.NewQuery(Filters{"hostname": hostname})
+		q.AddAttributes("tags")
+		obj, err := q.One(ctx)
+		require.NoError(t, err)
+		return obj
+	}
+
+	obj := query("a")
+	query("a")
+	assert.Equal(t, 1, queries, "the same query is answered from the cache")
+
+	tags := obj.GetMulti("tags")
+	tags.Add("y")
+	require.NoError(t, obj.Set("tags", tags))
+	assert.Equal(t, []any{"x"}, query("a").Get("tags"), "cached objects are copies")
+
+	query("b")
+	assert.Equal(t, 2, queries, "other queries are sent")
+
+	now = now.Add(time.Minute)
+	query("a")
+	assert.Equal(t, 3, queries, "expired results are fetched again")
+
+	_, err = obj.Commit(ctx)
+	require.NoError(t, err)
+	query("a")
+	assert.Equal(t, 4, queries, "commits clear the cache")
+
+	client.InvalidateQueryCache()
+	query("a")
+	assert.Equal(t, 5, queries)
+}
@@ -1,6 +1,13 @@
 package adminapi
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -68,3 +75,370 @@ func TestFromQueryWithError(t *testing.T) {
 	assert.Contains(t, err.Error(), "unmatched ( found")
 	assert.Equal(t, Query{}, q, "query should be zero value on error")
 }
+
+func TestQueryStream(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a"},{"object_id":2,"hostname":"b"},{"object_id":3,"hostname":"c"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	q := mustClient(t, server.URL).NewQuery(Filters{})
+
+	var hostnames []string
+	require.NoError(t, q.Stream(ctx, func(obj *ServerObject) error {
+		hostnames = append(hostnames, obj.GetString("hostname"))
+		return nil
+	}))
+	assert.Equal(t, []string{"a", "b", "c"}, hostnames)
+	assert.False(t, q.loaded, "streamed results are not kept")
+
+	errStop := errors.New("stop")
+	hostnames = nil
+	err := q.Stream(ctx, func(obj *ServerObject) error {
+		hostnames = append(hostnames, obj.GetString("hostname"))
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+	assert.Equal(t, []string{"a"}, hostnames)
+
+	_, err = q.All(ctx)
+	require.NoError(t, err)
+	calls = 0
+	hostnames = nil
+	require.NoError(t, q.Stream(ctx, func(obj *ServerObject) error {
+		hostnames = append(hostnames, obj.GetString("hostname"))
+		return nil
+	}))
+	assert.Equal(t, []string{"a", "b", "c"}, hostnames)
+	assert.Zero(t, calls, "a loaded query is not sent again")
+}
+
+func TestDecodeQueryResult(t *testing.T) {
+	decode := func(body string) ([]Attributes, error) {
+		var objects []Attributes
+		_, err := decodeQueryResult(strings.NewReader(body), func(attrs Attributes) error {
+			objects = append(objects, attrs)
+			return nil
+		})
+		return objects, err
+	}
+
+	objects, err := decode(`{"result":[{"object_id":1,"tags":["a"]}],"status":"success","extra":{"nested":[1,2]}}`)
+	require.NoError(t, err)
+	assert.Equal(t, []Attributes{{"object_id": float64(1), "tags": []any{"a"}}}, objects)
+
+	objects, err = decode(`{"status":"success","result":null}`)
+	require.NoError(t, err)
+	assert.Empty(t, objects)
+
+	_, err = decode(`{"status":"success","result":{}}`)
+	require.Error(t, err)
+
+	_, err = decode(`{"status":"success","result":[{"object_id":1},`)
+	require.Error(t, err, "truncated response")
+
+	_, err = decode(`[]`)
+	require.Error(t, err)
+}
+
+func TestQueryLimitOffsetRequest(t *testing.T) {
+	var requests []queryRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{})
+	_, err := q.All(context.Background())
+	require.NoError(t, err)
+
+	q = client.NewQuery(Filters{})
+	q.Limit(50)
+	q.Offset(100)
+	_, err = q.All(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, requests, 2)
+	assert.Zero(t, requests[0].Limit, "no limit by default")
+	assert.Equal(t, 50, requests[1].Limit)
+	assert.Equal(t, 100, requests[1].Offset)
+}
+
+func TestQueryFirst(t *testing.T) {
+	var requests []queryRequest
+	result := `[{"object_id":2,"hostname":"b"},{"object_id":1,"hostname":"a"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":` + result + `}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"servertype": "hypervisor"})
+	q.OrderBy("hostname", Desc)
+
+	obj, err := q.First(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "b", obj.GetString("hostname"), "more than one match is fine")
+	require.Len(t, requests, 1)
+	assert.Equal(t, 1, requests[0].Limit)
+	assert.Equal(t, []any{"-hostname"}, requests[0].OrderBy)
+	assert.False(t, q.loaded, "the query itself is not loaded")
+
+	_, err = q.All(ctx)
+	require.NoError(t, err)
+	requests = nil
+	obj, err = q.First(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "b", obj.GetString("hostname"))
+	assert.Empty(t, requests, "a loaded query is not sent again")
+
+	result = `[]`
+	empty := client.NewQuery(Filters{})
+	_, err = empty.First(ctx)
+	require.ErrorIs(t, err, ErrNoResults)
+}
+
+func TestQueryExists(t *testing.T) {
+	var requests []queryRequest
+	result := `[{"object_id":1}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":` + result + `}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"hostname": "web1"})
+	q.SetAttributes("hostname", "memory")
+	q.OrderBy("hostname")
+
+	exists, err := q.Exists(ctx)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []any{"object_id"}, requests[0].Restricted)
+	assert.Equal(t, 1, requests[0].Limit)
+	assert.Nil(t, requests[0].OrderBy)
+	assert.Equal(t, []string{"hostname", "memory"}, q.restrictedAttributes, "the query itself is unchanged")
+
+	result = `[]`
+	exists, err = q.Exists(ctx)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestQueryCount(t *testing.T) {
+	var requests []queryRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1},{"object_id":2}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"servertype": "vm"})
+	q.SetAttributes("hostname", "memory")
+	q.OrderBy("hostname")
+
+	count, err := q.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	require.Len(t, requests, 1)
+	assert.Equal(t, []any{"object_id"}, requests[0].Restricted)
+	assert.Nil(t, requests[0].OrderBy)
+	assert.False(t, q.loaded, "the counted objects are not kept")
+	assert.Equal(t, []string{"hostname", "memory"}, q.restrictedAttributes, "the query itself is unchanged")
+
+	_, err = q.All(ctx)
+	require.NoError(t, err)
+	requests = nil
+	count, err = q.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Empty(t, requests, "a loaded query is not sent again")
+}
+
+func TestQueryOneOrNil(t *testing.T) {
+	result := `[]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":` + result + `}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := mustClient(t, server.URL)
+	query := func() (*ServerObject, error) {
+		q := client.NewQuery(Filters{"hostname": "web1"})
+		return q.OneOrNil(ctx)
+	}
+
+	obj, err := query()
+	require.NoError(t, err)
+	assert.Nil(t, obj)
+
+	result = `[{"object_id":1,"hostname":"web1"}]`
+	obj, err = query()
+	require.NoError(t, err)
+	assert.Equal(t, 1, obj.ObjectID())
+
+	result = `[{"object_id":1},{"object_id":2}]`
+	_, err = query()
+	require.ErrorIs(t, err, ErrMultipleResults)
+}
+
+func TestQueryIter(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a"},{"object_id":2,"hostname":"b"},{"object_id":3,"hostname":"c"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	q := mustClient(t, server.URL).NewQuery(Filters{})
+
+	var hostnames []string
+	for obj, err := range q.Iter(ctx) {
+		require.NoError(t, err)
+		hostnames = append(hostnames, obj.GetString("hostname"))
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, hostnames)
+
+	hostnames = nil
+	for obj, err := range q.Iter(ctx) {
+		require.NoError(t, err)
+		hostnames = append(hostnames, obj.GetString("hostname"))
+		if len(hostnames) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"a", "b"}, hostnames)
+
+	status = http.StatusInternalServerError
+	var errs []error
+	for obj, err := range q.Iter(ctx) {
+		assert.Nil(t, obj)
+		errs = append(errs, err)
+	}
+	require.Len(t, errs, 1)
+	var apiErr *APIError
+	require.ErrorAs(t, errs[0], &apiErr)
+}
+
+func TestQueryRefresh(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, `{"status":"success","result":[{"object_id":%d}]}`, calls)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	q := mustClient(t, server.URL).NewQuery(Filters{})
+
+	first, err := q.One(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.ObjectID())
+
+	_, err = q.One(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "results are cached")
+
+	require.NoError(t, q.Refresh(ctx))
+	obj, err := q.One(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, obj.ObjectID())
+	assert.Equal(t, 1, first.ObjectID(), "earlier objects stay valid")
+
+	q.Invalidate()
+	assert.Equal(t, 2, calls, "Invalidate sends no request")
+	obj, err = q.One(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, obj.ObjectID())
+}
+
+func TestQueryClone(t *testing.T) {
+	base := mustClient(t, "https://example.com").NewQuery(Filters{
+		"servertype": "vm",
+		"state":      Any("online", "maintenance"),
+		"hostname":   Not(Any(Regexp("^db"), Regexp("^lb"))),
+		"project":    Filter{"Any": []any{"a", Filter{"Regexp": "b.*"}}},
+	})
+	base.OrderBy("hostname")
+	base.loaded = true
+
+	clone := base.Clone()
+	assert.False(t, clone.loaded)
+	assert.Equal(t, base.filters, clone.filters)
+
+	clone.AddFilter("environment", "production")
+	clone.AddAttributes("memory")
+	clone.ThenBy("memory")
+	clone.filters["state"].(Filter)["Any"].([]string)[0] = "offline"
+	clone.filters["hostname"].(Filter)["Not"].(Filter)["Any"].([]Filter)[0]["Regexp"] = "^web"
+	clone.filters["project"].(Filter)["Any"].([]any)[1].(Filter)["Regexp"] = "c.*"
+
+	assert.Equal(t, Filters{
+		"servertype": "vm",
+		"state":      Any("online", "maintenance"),
+		"hostname":   Not(Any(Regexp("^db"), Regexp("^lb"))),
+		"project":    Filter{"Any": []any{"a", Filter{"Regexp": "b.*"}}},
+	}, base.filters)
+	assert.Equal(t, []string{"object_id", "hostname"}, base.restrictedAttributes)
+	assert.Equal(t, "hostname", orderByValue(base.orderBy))
+}
+
+func TestGetByHostname(t *testing.T) {
+	var requests []queryRequest
+	result := `[{"object_id":1,"hostname":"web1","os":"trixie"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		requests = append(requests, req)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":` + result + `}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := mustClient(t, server.URL)
+
+	obj, err := client.GetByHostname(ctx, "web1", "os")
+	require.NoError(t, err)
+	assert.Equal(t, "trixie", obj.GetString("os"))
+	require.Len(t, requests, 1)
+	assert.Equal(t, map[string]any{"hostname": "web1"}, requests[0].Filters)
+	assert.Equal(t, []any{"object_id", "hostname", "os"}, requests[0].Restricted)
+
+	obj, err = GetByHostname(WithClient(ctx, client), "web1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, obj.ObjectID())
+
+	result = `[]`
+	_, err = client.GetByHostname(ctx, "web2")
+	require.ErrorIs(t, err, ErrNoResults)
+}
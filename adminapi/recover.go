@@ -0,0 +1,30 @@
+package adminapi
+
+import (
+	"context"
+	"runtime/debug"
+)
+
+// recoverPanic converts a panic into a *PanicError stored in *err when client
+// has Config.RecoverPanics enabled; otherwise the panic keeps unwinding. It
+// must be deferred directly by the public entry point:
+//
+//	func (c *Client) Something(ctx context.Context) (_ int, err error) {
+//		defer recoverPanic(c, &err)
+func recoverPanic(client *Client, err *error) {
+	if client == nil || !client.recoverPanics {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = &PanicError{Value: r, Stack: debug.Stack()}
+	}
+}
+
+// clientOrContext returns client, or the client attached to ctx if it is nil.
+// It is used to pick the client deciding about panic recovery.
+func clientOrContext(ctx context.Context, client *Client) *Client {
+	if client == nil && ctx != nil {
+		client, _ = FromContext(ctx)
+	}
+	return client
+}
@@ -0,0 +1,101 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// panickingFilter blows up while the query is being serialized.
+type panickingFilter struct{}
+
+func (panickingFilter) MarshalJSON() ([]byte, error) {
+	panic(errPanicking)
+}
+
+var errPanicking = errors.New("boom")
+
+func TestRecoverPanics(t *testing.T) {
+	t.Run("query returns PanicError", func(t *testing.T) {
+		client, err := NewClient(Config{BaseURL: "http://127.0.0.1:1", Token: "tok", RecoverPanics: true})
+		require.NoError(t, err)
+
+		q := client.NewQuery(Filters{"hostname": panickingFilter{}})
+		_, err = q.All(context.Background())
+
+		var panicErr *PanicError
+		require.ErrorAs(t, err, &panicErr)
+		assert.ErrorIs(t, err, errPanicking)
+		assert.Contains(t, string(panicErr.Stack), "panickingFilter.MarshalJSON")
+	})
+
+	t.Run("context client enables recovery", func(t *testing.T) {
+		client, err := NewClient(Config{BaseURL: "http://127.0.0.1:1", Token: "tok", RecoverPanics: true})
+		require.NoError(t, err)
+
+		q := newQuery(nil, Filters{"hostname": panickingFilter{}})
+		_, err = q.Count(WithClient(context.Background(), client))
+
+		var panicErr *PanicError
+		assert.ErrorAs(t, err, &panicErr)
+	})
+
+	t.Run("Set on broken object", func(t *testing.T) {
+		client, err := NewClient(Config{BaseURL: "http://127.0.0.1:1", Token: "tok", RecoverPanics: true})
+		require.NoError(t, err)
+
+		obj := &ServerObject{client: client, attributes: Attributes{"hostname": "a"}}
+		err = obj.Set("hostname", "b")
+
+		var panicErr *PanicError
+		assert.ErrorAs(t, err, &panicErr)
+	})
+
+	t.Run("nil objects", func(t *testing.T) {
+		client, err := NewClient(Config{BaseURL: "http://127.0.0.1:1", Token: "tok", RecoverPanics: true})
+		require.NoError(t, err)
+		obj := &ServerObject{client: client, attributes: Attributes{"hostname": "a"}, oldValues: Attributes{}}
+
+		var panicErr *PanicError
+		_, err = ServerObjects{nil, obj}.Commit(context.Background())
+		require.ErrorAs(t, err, &panicErr, "Commit")
+
+		err = ServerObjects{obj, nil}.Set("hostname", "b")
+		require.ErrorAs(t, err, &panicErr, "Set")
+
+		var nilObj *ServerObject
+		ctx := WithClient(context.Background(), client)
+		_, err = nilObj.Commit(ctx)
+		require.ErrorIs(t, err, ErrNilObject, "single Commit")
+		_, err = nilObj.History(ctx)
+		require.ErrorIs(t, err, ErrNilObject, "History")
+		require.ErrorIs(t, nilObj.Refresh(ctx), ErrNilObject, "Refresh")
+		_, err = nilObj.Related(ctx, "hypervisor")
+		require.ErrorIs(t, err, ErrNilObject, "Related")
+	})
+
+	t.Run("nil object without client", func(t *testing.T) {
+		var obj *ServerObject
+		require.ErrorIs(t, obj.Set("hostname", "b"), ErrNilObject)
+		require.ErrorIs(t, obj.Touch("hostname"), ErrNilObject)
+		require.ErrorIs(t, obj.Unset("hostname"), ErrNilObject)
+		_, err := obj.SetIfChanged("hostname", "b")
+		require.ErrorIs(t, err, ErrNilObject)
+		require.ErrorIs(t, obj.AddToMulti("tags", "a"), ErrNilObject)
+		require.ErrorIs(t, obj.Multi("tags").Clear(), ErrNilObject)
+		require.ErrorIs(t, obj.ApplyStruct(struct{}{}), ErrNilObject)
+		require.ErrorIs(t, obj.Decode(&struct{}{}), ErrNilObject)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := mustClient(t, "http://127.0.0.1:1")
+
+		q := client.NewQuery(Filters{"hostname": panickingFilter{}})
+		assert.Panics(t, func() {
+			_, _ = q.All(context.Background())
+		})
+	})
+}
@@ -0,0 +1,69 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Refresh fetches the object again by object_id and replaces the values of
+// the given attributes, or of all attributes the object holds if none are
+// given, so long-lived objects don't operate on stale data.
+//
+// Pending local changes are kept: they are merged with the server values like
+// Merge does, so an attribute changed only on the server takes the new value
+// and multi attributes changed on both sides are merged as sets. If an
+// attribute was changed differently on both sides, Refresh fails with
+// ErrRefreshConflict naming the attributes and leaves the object unchanged;
+// Rollback and refresh again to drop the local changes. Objects deleted on
+// the server fail with ErrNoResults, uncommitted ones can't be refreshed.
+func (s *ServerObject) Refresh(ctx context.Context, attributes ...string) (err error) {
+	defer recoverPanic(clientOrContext(ctx, s.boundClient()), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
+	id := s.ObjectID()
+	if id == 0 {
+		return errors.New("refreshing object: not committed yet")
+	}
+	client, err := s.resolveClient(ctx)
+	if err != nil {
+		return err
+	}
+	if len(attributes) == 0 {
+		attributes = s.Keys()
+	}
+
+	q := newQuery(client, Filters{"object_id": id})
+	q.SetAttributes(attributes...)
+	fresh, err := q.One(ctx)
+	if err != nil {
+		return fmt.Errorf("refreshing object %d: %w", id, err)
+	}
+
+	base := &ServerObject{attributes: cloneAttributes(s.attributes)}
+	for key, value := range s.oldValues {
+		base.attributes[key] = value
+	}
+	theirs := &ServerObject{client: s.client, attributes: cloneAttributes(base.attributes)}
+	for key, value := range fresh.attributes {
+		theirs.attributes[key] = value
+	}
+
+	merged, conflicts := Merge(base, s, theirs)
+	if len(conflicts) > 0 {
+		names := make([]string, len(conflicts))
+		for i, conflict := range conflicts {
+			names[i] = conflict.Attribute
+		}
+		return fmt.Errorf("refreshing object %d: %w: %s", id, ErrRefreshConflict, strings.Join(names, ", "))
+	}
+	s.attributes, s.oldValues = merged.attributes, merged.oldValues
+	for key := range s.touched {
+		s.trackOldValue(key)
+	}
+	return nil
+}
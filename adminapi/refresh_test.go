@@ -0,0 +1,88 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshServer answers queries with the given objects, recording the
+// filters and restrict list of the last request.
+func refreshServer(t *testing.T, result ...Attributes) (*httptest.Server, *map[string]any) {
+	t.Helper()
+	var lastRequest map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&lastRequest))
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server, &lastRequest
+}
+
+func TestRefresh(t *testing.T) {
+	server, lastRequest := refreshServer(t, Attributes{"object_id": 42, "hostname": "web01", "state": "maintenance", "num_cpu": 8})
+	obj := &ServerObject{
+		client:     mustClient(t, server.URL),
+		attributes: Attributes{"object_id": float64(42), "hostname": "web01", "state": "online", "num_cpu": float64(4), "local": "x"},
+		oldValues:  Attributes{},
+	}
+
+	require.NoError(t, obj.Refresh(context.Background(), "state", "num_cpu"))
+	assert.Equal(t, map[string]any{"object_id": float64(42)}, (*lastRequest)["filters"])
+	assert.Equal(t, []any{"state", "num_cpu", "object_id"}, (*lastRequest)["restrict"])
+	assert.Equal(t, "maintenance", obj.Get("state"))
+	assert.Equal(t, float64(8), obj.attributes["num_cpu"])
+	assert.Equal(t, "x", obj.Get("local"), "attributes not refreshed are kept")
+	assert.Equal(t, StateConsistent, obj.CommitState())
+}
+
+func TestRefreshKeepsPendingChanges(t *testing.T) {
+	server, _ := refreshServer(t, Attributes{"object_id": 42, "state": "maintenance", "num_cpu": 4, "tags": []any{"web", "theirs"}})
+	obj := &ServerObject{
+		client:     mustClient(t, server.URL),
+		attributes: Attributes{"object_id": float64(42), "state": "online", "num_cpu": float64(4), "tags": []any{"web"}},
+		oldValues:  Attributes{},
+	}
+	require.NoError(t, obj.Set("num_cpu", 16))
+	require.NoError(t, obj.AddToMulti("tags", "ours"))
+
+	require.NoError(t, obj.Refresh(context.Background()))
+	assert.Equal(t, "maintenance", obj.Get("state"))
+	assert.Equal(t, 16, obj.Get("num_cpu"))
+	assert.Equal(t, []any{"web", "theirs", "ours"}, obj.Get("tags"))
+
+	changes := obj.serializeChanges()
+	assert.Equal(t, map[string]any{"action": "update", "old": float64(4), "new": 16}, changes["num_cpu"])
+	assert.Equal(t, map[string]any{"action": "multi", "add": []any{"ours"}, "remove": []any{}}, changes["tags"])
+	assert.NotContains(t, changes, "state")
+}
+
+func TestRefreshConflict(t *testing.T) {
+	server, _ := refreshServer(t, Attributes{"object_id": 42, "state": "maintenance"})
+	obj := &ServerObject{
+		client:     mustClient(t, server.URL),
+		attributes: Attributes{"object_id": float64(42), "state": "online"},
+		oldValues:  Attributes{},
+	}
+	require.NoError(t, obj.Set("state", "retired"))
+
+	err := obj.Refresh(context.Background())
+	require.ErrorIs(t, err, ErrRefreshConflict)
+	assert.ErrorContains(t, err, "state")
+	assert.Equal(t, "retired", obj.Get("state"), "object is unchanged")
+	assert.Equal(t, "online", obj.oldValues["state"])
+}
+
+func TestRefreshErrors(t *testing.T) {
+	server, _ := refreshServer(t)
+	obj := &ServerObject{client: mustClient(t, server.URL), attributes: Attributes{"object_id": float64(42)}, oldValues: Attributes{}}
+	require.ErrorIs(t, obj.Refresh(context.Background()), ErrNoResults)
+
+	created := &ServerObject{client: obj.client, attributes: Attributes{"object_id": nil, "hostname": "new"}, oldValues: Attributes{}}
+	require.Error(t, created.Refresh(context.Background()))
+}
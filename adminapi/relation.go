@@ -0,0 +1,346 @@
+package adminapi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// defaultMaxQueryValues is the number of values sent in a single Any(...)
+// filter unless the server announced its own limit (see Limits). Longer value
+// lists are split over several requests, so the request body stays well below
+// what the server and fronting proxies accept.
+const defaultMaxQueryValues = 500
+
+// Hostnames returns the hostnames of all objects, skipping objects without one.
+func (s ServerObjects) Hostnames() []string {
+	hostnames := make([]string, 0, len(s))
+	for _, obj := range s {
+		if hostname := obj.GetString("hostname"); hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
+
+// ByHostname indexes the objects by hostname for lookups after a bulk query,
+// skipping objects without one. If several objects share a hostname, the last
+// one wins.
+func (s ServerObjects) ByHostname() map[string]*ServerObject {
+	index := make(map[string]*ServerObject, len(s))
+	for _, obj := range s {
+		if hostname := obj.GetString("hostname"); hostname != "" {
+			index[hostname] = obj
+		}
+	}
+	return index
+}
+
+// ByObjectID indexes the objects by object_id for lookups after a bulk query,
+// skipping objects without one, e.g. created but not yet committed ones.
+func (s ServerObjects) ByObjectID() map[int]*ServerObject {
+	index := make(map[int]*ServerObject, len(s))
+	for _, obj := range s {
+		if id := obj.ObjectID(); id != 0 {
+			index[id] = obj
+		}
+	}
+	return index
+}
+
+// AllRelated returns all objects matching the query whose relation attribute
+// points at one of the related objects, e.g. all VMs whose hypervisor is part
+// of a previously fetched set of hypervisors:
+//
+//	hypervisors, _ := hvQuery.All(ctx)
+//	vms, err := vmQuery.AllRelated(ctx, "hypervisor", hypervisors)
+//
+// The hostnames of the related objects are sent as an Any(...) filter on the
+// attribute, replacing any filter the query already has on it. Long hostname
+// lists are split over several requests and the results merged by object_id.
+// The query itself is not modified.
+func (q *Query) AllRelated(ctx context.Context, attribute string, related ServerObjects) (_ ServerObjects, err error) {
+	defer recoverPanic(clientOrContext(ctx, q.client), &err)
+
+	hostnames := related.Hostnames()
+	values := make([]any, len(hostnames))
+	for i, hostname := range hostnames {
+		values[i] = hostname
+	}
+	return q.allIn(ctx, attribute, values)
+}
+
+// allIn runs the query once per chunk of values with an additional
+// Any(values...) filter on attribute and merges the results by object_id.
+func (q *Query) allIn(ctx context.Context, attribute string, values []any) (ServerObjects, error) {
+	result := ServerObjects{}
+	seen := make(map[int]bool)
+	for chunk := range slices.Chunk(values, clientOrContext(ctx, q.client).maxQueryValues()) {
+		sub := q.derive()
+		sub.filters[attribute] = createFilter("Any", chunk)
+
+		objects, err := sub.All(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			if id := obj.ObjectID(); !seen[id] {
+				seen[id] = true
+				result = append(result, obj)
+			}
+		}
+	}
+	return result, nil
+}
+
+// derive returns an unloaded copy of the query that can be modified without
+// affecting the original's filters or attributes.
+func (q *Query) derive() Query {
+	filters := make(Filters, len(q.filters)+1)
+	maps.Copy(filters, q.filters)
+	return Query{
+		client:               q.client,
+		filters:              filters,
+		alternatives:         slices.Clone(q.alternatives),
+		restrictedAttributes: slices.Clone(q.restrictedAttributes),
+		relatedAttributes:    cloneRelatedRestrictions(q.relatedAttributes),
+		orderBy:              slices.Clone(q.orderBy),
+		limit:                q.limit,
+		offset:               q.offset,
+		timeout:              q.timeout,
+		includeRetired:       q.includeRetired,
+	}
+}
+
+// relatedRestriction is a nested restriction fetching attributes of the
+// objects a relation attribute points at.
+type relatedRestriction struct {
+	attribute  string
+	attributes []string
+}
+
+// AddRelatedAttributes makes the query fetch the given attributes of the
+// objects the relation attribute points at in the same request, e.g. the os
+// and datacenter of each VM's hypervisor:
+//
+//	q.AddRelatedAttributes("hypervisor", "os", "datacenter")
+//
+// The related objects are available with GetRelated and GetRelatedMulti and
+// always include their object_id and hostname; the relation attribute itself
+// keeps holding the hostname(s) as without the join.
+func (q *Query) AddRelatedAttributes(attribute string, attributes ...string) {
+	for i, related := range q.relatedAttributes {
+		if related.attribute == attribute {
+			q.relatedAttributes[i].attributes = append(related.attributes, attributes...)
+			return
+		}
+	}
+	q.relatedAttributes = append(q.relatedAttributes, relatedRestriction{
+		attribute:  attribute,
+		attributes: append([]string{"object_id", "hostname"}, attributes...),
+	})
+}
+
+// restrictValue returns the restrict list of the request: the attribute
+// names, followed by Serveradmin's nested {"hypervisor": ["hostname", "os"]}
+// restrictions for related attributes.
+func (q *Query) restrictValue() []any {
+	restrict := make([]any, 0, len(q.restrictedAttributes)+len(q.relatedAttributes))
+	for _, attribute := range q.restrictedAttributes {
+		if !slices.ContainsFunc(q.relatedAttributes, func(r relatedRestriction) bool { return r.attribute == attribute }) {
+			restrict = append(restrict, attribute)
+		}
+	}
+	for _, related := range q.relatedAttributes {
+		restrict = append(restrict, map[string][]string{related.attribute: related.attributes})
+	}
+	return restrict
+}
+
+func cloneRelatedRestrictions(restrictions []relatedRestriction) []relatedRestriction {
+	if restrictions == nil {
+		return nil
+	}
+	clone := make([]relatedRestriction, len(restrictions))
+	for i, related := range restrictions {
+		clone[i] = relatedRestriction{attribute: related.attribute, attributes: slices.Clone(related.attributes)}
+	}
+	return clone
+}
+
+// extractRelated moves the nested objects of joined relation attributes into
+// related ServerObjects, leaving their hostnames as the attribute values.
+func (s *ServerObject) extractRelated(restrictions []relatedRestriction) {
+	for _, related := range restrictions {
+		var objects ServerObjects
+		switch value := s.attributes[related.attribute].(type) {
+		case map[string]any:
+			objects = ServerObjects{s.relatedObject(value)}
+			s.attributes[related.attribute] = value["hostname"]
+		case []any:
+			hostnames := make([]any, 0, len(value))
+			for _, elem := range value {
+				if nested, ok := elem.(map[string]any); ok {
+					objects = append(objects, s.relatedObject(nested))
+					hostnames = append(hostnames, nested["hostname"])
+				} else {
+					hostnames = append(hostnames, elem)
+				}
+			}
+			s.attributes[related.attribute] = hostnames
+		default:
+			continue // empty, or not joined by the server
+		}
+		if s.related == nil {
+			s.related = make(map[string]ServerObjects)
+		}
+		s.related[related.attribute] = objects
+	}
+}
+
+// relatedObject wraps the attributes of a joined object into a ServerObject
+// bound to the same client.
+func (s *ServerObject) relatedObject(attributes map[string]any) *ServerObject {
+	return &ServerObject{client: s.client, attributes: attributes, oldValues: Attributes{}}
+}
+
+// GetRelated returns the object a relation attribute points at, with the
+// attributes fetched by Query.AddRelatedAttributes, or nil if the attribute is
+// empty or was not joined. For relations to several objects it returns the
+// first one, see GetRelatedMulti.
+func (s *ServerObject) GetRelated(attribute string) *ServerObject {
+	if objects := s.related[attribute]; len(objects) > 0 {
+		return objects[0]
+	}
+	return nil
+}
+
+// GetRelatedMulti returns the objects a multi relation attribute points at,
+// with the attributes fetched by Query.AddRelatedAttributes, or nil if the
+// attribute was not joined.
+func (s *ServerObject) GetRelatedMulti(attribute string) ServerObjects {
+	return s.related[attribute]
+}
+
+// Related resolves a relation attribute into the object it points at,
+// fetching the given attributes in addition to object_id and hostname:
+//
+//	hv, err := vm.Related(ctx, "hypervisor", "os", "datacenter")
+//
+// An object already joined with Query.AddRelatedAttributes is returned
+// without a request if it holds all the attributes. It returns nil if the
+// attribute is empty and ErrNoResults if the related object does not exist.
+// For relations to several objects it returns the first one, see
+// ServerObjects.Related for all.
+func (s *ServerObject) Related(ctx context.Context, attribute string, attributes ...string) (_ *ServerObject, err error) {
+	defer recoverPanic(clientOrContext(ctx, s.boundClient()), &err)
+
+	if s == nil {
+		return nil, ErrNilObject
+	}
+
+	hostnames := relationHostnames(s.attributes[attribute])
+	if len(hostnames) == 0 {
+		return nil, nil //nolint:nilnil // an empty relation is not an error
+	}
+	related, err := ServerObjects{s}.related(ctx, attribute, hostnames[:1], attributes)
+	if err != nil {
+		return nil, err
+	}
+	if len(related) == 0 {
+		return nil, fmt.Errorf("%s %q: %w", attribute, hostnames[0], ErrNoResults)
+	}
+	return related[0], nil
+}
+
+// Related resolves a relation attribute of all objects into the objects it
+// points at, e.g. the hypervisors of a set of VMs, fetching the given
+// attributes in addition to object_id and hostname. Each related object is
+// returned once, in the order they are first referenced, and looked up once,
+// with long hostname lists split over several requests. Objects already
+// joined with Query.AddRelatedAttributes are reused if they hold all the
+// attributes; related objects that don't exist are skipped.
+func (s ServerObjects) Related(ctx context.Context, attribute string, attributes ...string) (_ ServerObjects, err error) {
+	defer recoverPanic(clientOrContext(ctx, nil), &err)
+
+	var hostnames []string
+	seen := make(map[string]bool)
+	for _, obj := range s {
+		if obj == nil {
+			continue
+		}
+		for _, hostname := range relationHostnames(obj.attributes[attribute]) {
+			if !seen[hostname] {
+				seen[hostname] = true
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+	return s.related(ctx, attribute, hostnames, attributes)
+}
+
+// related returns the objects with the given hostnames, taking joined ones
+// holding all attributes from s and querying the others.
+func (s ServerObjects) related(ctx context.Context, attribute string, hostnames, attributes []string) (ServerObjects, error) {
+	found := make(map[string]*ServerObject, len(hostnames))
+	for _, obj := range s {
+		if obj == nil {
+			continue
+		}
+		for _, joined := range obj.related[attribute] {
+			if slices.ContainsFunc(attributes, func(a string) bool { return !joined.Has(a) }) {
+				continue
+			}
+			found[joined.GetString("hostname")] = joined
+		}
+	}
+
+	var missing []any
+	for _, hostname := range hostnames {
+		if found[hostname] == nil {
+			missing = append(missing, hostname)
+		}
+	}
+	if len(missing) > 0 {
+		client, err := resolveObjectsClient(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		q := newQuery(client, Filters{})
+		q.AddAttributes(attributes...)
+		objects, err := q.allIn(ctx, "hostname", missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			found[obj.GetString("hostname")] = obj
+		}
+	}
+
+	result := make(ServerObjects, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		if obj := found[hostname]; obj != nil {
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// relationHostnames returns the hostnames a relation attribute holds: none
+// if it is empty, one for single relations and any number for multi ones.
+func relationHostnames(value any) []string {
+	if hostname, ok := value.(string); ok {
+		if hostname == "" {
+			return nil
+		}
+		return []string{hostname}
+	}
+	var hostnames []string
+	for _, elem := range toAnySlice(value) {
+		if hostname, ok := elem.(string); ok && hostname != "" {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames
+}
@@ -0,0 +1,205 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerObjectsHostnames(t *testing.T) {
+	objects := ServerObjects{
+		{attributes: Attributes{"hostname": "hv1.local"}},
+		{attributes: Attributes{"object_id": float64(2)}},
+		{attributes: Attributes{"hostname": "hv2.local"}},
+	}
+
+	assert.Equal(t, []string{"hv1.local", "hv2.local"}, objects.Hostnames())
+	assert.Empty(t, ServerObjects{}.Hostnames())
+}
+
+func TestServerObjectsIndex(t *testing.T) {
+	objects := ServerObjects{
+		{attributes: Attributes{"object_id": 1, "hostname": "hv1.local"}},
+		{attributes: Attributes{"object_id": 2}},
+		{attributes: Attributes{"hostname": "new.local"}},
+	}
+
+	byHostname := objects.ByHostname()
+	assert.Len(t, byHostname, 2)
+	assert.Same(t, objects[0], byHostname["hv1.local"])
+	assert.Same(t, objects[2], byHostname["new.local"])
+
+	byID := objects.ByObjectID()
+	assert.Len(t, byID, 2)
+	assert.Same(t, objects[0], byID[1])
+	assert.Same(t, objects[1], byID[2])
+
+	assert.Empty(t, ServerObjects{}.ByHostname())
+	assert.Empty(t, ServerObjects{}.ByObjectID())
+}
+
+func TestAllRelatedChunks(t *testing.T) {
+	const hypervisors = defaultMaxQueryValues*2 + 10
+
+	var chunkSizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Filters struct {
+				Hypervisor map[string][]string `json:"hypervisor"`
+			} `json:"filters"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		hosts := req.Filters.Hypervisor["Any"]
+		chunkSizes = append(chunkSizes, len(hosts))
+
+		// Every chunk returns the same VM once to verify de-duplication, plus
+		// one VM per hypervisor in the chunk.
+		result := []Attributes{{"object_id": 1, "hostname": "shared-vm"}}
+		for _, host := range hosts {
+			result = append(result, Attributes{"object_id": len(result) + 1000*len(chunkSizes), "hostname": "vm-on-" + host})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "result": result})
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+
+	related := make(ServerObjects, hypervisors)
+	for i := range related {
+		related[i] = &ServerObject{attributes: Attributes{"hostname": fmt.Sprintf("hv%d.local", i)}}
+	}
+
+	q := client.NewQuery(Filters{"servertype": "vm"})
+	vms, err := q.AllRelated(context.Background(), "hypervisor", related)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{defaultMaxQueryValues, defaultMaxQueryValues, 10}, chunkSizes)
+	assert.Len(t, vms, hypervisors+1)
+	assert.Equal(t, Filters{"servertype": "vm"}, q.filters, "original query must not be modified")
+}
+
+func TestAllRelatedEmpty(t *testing.T) {
+	client := mustClient(t, "https://example.com")
+	q := client.NewQuery(Filters{"servertype": "vm"})
+
+	vms, err := q.AllRelated(context.Background(), "hypervisor", ServerObjects{})
+	require.NoError(t, err)
+	assert.Empty(t, vms)
+}
+
+func TestAddRelatedAttributes(t *testing.T) {
+	var req struct {
+		Restrict []any `json:"restrict"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[
+			{"object_id":1,"hostname":"vm1","hypervisor":{"object_id":2,"hostname":"hv1","os":"trixie"}},
+			{"object_id":3,"hostname":"vm2","hypervisor":null}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"servertype": "vm"})
+	q.AddAttributes("hypervisor")
+	q.AddRelatedAttributes("hypervisor", "os")
+	q.AddRelatedAttributes("hypervisor", "datacenter")
+
+	clone := q.Clone()
+	clone.AddRelatedAttributes("hypervisor", "num_cpu")
+
+	objects, err := q.All(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []any{
+		"object_id", "hostname",
+		map[string]any{"hypervisor": []any{"object_id", "hostname", "os", "datacenter"}},
+	}, req.Restrict, "the relation is only requested nested, the clone's attributes not at all")
+
+	assert.Equal(t, "hv1", objects[0].GetString("hypervisor"))
+	assert.Equal(t, "trixie", objects[0].GetRelated("hypervisor").GetString("os"))
+	assert.Nil(t, objects[1].GetRelated("hypervisor"))
+	assert.Nil(t, objects[1].GetRelatedMulti("hypervisor"))
+}
+
+// relatedServer answers hostname queries with an object per requested
+// hostname, except "gone", recording the hostnames of each request.
+func relatedServer(t *testing.T) (*httptest.Server, *[][]string) {
+	t.Helper()
+	var requests [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Filters struct {
+				Hostname map[string][]string `json:"hostname"`
+			} `json:"filters"`
+			Restrict []string `json:"restrict"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Subset(t, req.Restrict, []string{"object_id", "hostname", "os"})
+		hostnames := req.Filters.Hostname["Any"]
+		requests = append(requests, hostnames)
+
+		result := []Attributes{}
+		for i, hostname := range hostnames {
+			if hostname != "gone" {
+				result = append(result, Attributes{"object_id": 100 + i, "hostname": hostname, "os": "trixie"})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRelated(t *testing.T) {
+	server, requests := relatedServer(t)
+	client := mustClient(t, server.URL)
+	vm := func(hypervisor any) *ServerObject {
+		return &ServerObject{client: client, attributes: Attributes{"object_id": 1, "hypervisor": hypervisor}, oldValues: Attributes{}}
+	}
+
+	hv, err := vm("hv1").Related(context.Background(), "hypervisor", "os")
+	require.NoError(t, err)
+	assert.Equal(t, "hv1", hv.GetString("hostname"))
+	assert.Equal(t, "trixie", hv.GetString("os"))
+
+	hv, err = vm(nil).Related(context.Background(), "hypervisor", "os")
+	require.NoError(t, err)
+	assert.Nil(t, hv)
+
+	_, err = vm("gone").Related(context.Background(), "hypervisor", "os")
+	require.ErrorIs(t, err, ErrNoResults)
+
+	vms := ServerObjects{vm("hv1"), vm("hv2"), vm("hv1"), vm([]any{"hv3", "hv2"}), vm("gone"), nil}
+	hypervisors, err := vms.Related(context.Background(), "hypervisor", "os")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hv1", "hv2", "hv3"}, hypervisors.Hostnames())
+	assert.Equal(t, [][]string{{"hv1"}, {"gone"}, {"hv1", "hv2", "hv3", "gone"}}, *requests, "each hostname is looked up once")
+}
+
+func TestRelatedReusesJoinedObjects(t *testing.T) {
+	server, requests := relatedServer(t)
+	vm := &ServerObject{
+		client:     mustClient(t, server.URL),
+		attributes: Attributes{"object_id": 1, "hypervisor": map[string]any{"object_id": 2, "hostname": "hv1", "os": "bookworm"}},
+		oldValues:  Attributes{},
+	}
+	vm.extractRelated([]relatedRestriction{{attribute: "hypervisor", attributes: []string{"object_id", "hostname", "os"}}})
+
+	hv, err := vm.Related(context.Background(), "hypervisor", "os")
+	require.NoError(t, err)
+	assert.Same(t, vm.GetRelated("hypervisor"), hv)
+	assert.Empty(t, *requests)
+
+	hv, err = vm.Related(context.Background(), "hypervisor", "os", "datacenter")
+	require.NoError(t, err)
+	assert.Equal(t, "trixie", hv.GetString("os"), "joined objects lacking attributes are fetched")
+	assert.Len(t, *requests, 1)
+}
@@ -0,0 +1,171 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// RenamePlan describes renaming an object together with all relation values
+// referencing its hostname. Nothing is sent to the server until Commit, so a
+// plan can be inspected (or shown to a user) first.
+type RenamePlan struct {
+	Object      *ServerObject
+	OldHostname string
+	NewHostname string
+	References  []Reference
+}
+
+// Reference is a relation attribute of another object that points at the
+// renamed object.
+type Reference struct {
+	Object    *ServerObject
+	Attribute string
+}
+
+// PlanRename prepares renaming obj to newHostname. It looks up every object
+// whose (non-reversed) relation attributes contain obj's current hostname,
+// including retired ones, and updates those values in place. obj and the
+// referencing objects carry the changes, which are applied by Commit. As
+// Commit sends all changes of obj, planning fails with ErrUncommittedChanges
+// if obj has any besides the rename.
+func (c *Client) PlanRename(ctx context.Context, obj *ServerObject, newHostname string) (_ *RenamePlan, err error) {
+	defer recoverPanic(c, &err)
+
+	oldHostname := obj.GetString("hostname")
+	if oldHostname == "" {
+		return nil, errors.New("rename: object has no hostname")
+	}
+	if newHostname == "" || newHostname == oldHostname {
+		return nil, fmt.Errorf("rename: invalid new hostname %q", newHostname)
+	}
+	if obj.CommitState() != StateConsistent {
+		return nil, fmt.Errorf("rename: %w", ErrUncommittedChanges)
+	}
+
+	attributes, err := c.FetchAttributes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// find the referencing objects first, so that those referencing via
+	// several attributes are loaded once with all of them and committed once
+	// with all their changes
+	type reference struct {
+		objectID  int
+		attribute string
+	}
+	var references []reference
+	var ids []int
+	var relations []string
+	for _, attr := range attributes {
+		if attr.Type != "relation" || attr.ReversedAttribute != "" || attr.Readonly {
+			continue
+		}
+
+		q := c.NewQuery(Filters{attr.AttributeID: oldHostname})
+		q.SetAttributes("object_id")
+		q.IncludeRetired()
+		referencing, err := q.All(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rename: finding references via %s: %w", attr.AttributeID, err)
+		}
+		for _, ref := range referencing {
+			references = append(references, reference{objectID: ref.ObjectID(), attribute: attr.AttributeID})
+			if !slices.Contains(ids, ref.ObjectID()) {
+				ids = append(ids, ref.ObjectID())
+			}
+		}
+		if len(referencing) > 0 {
+			relations = append(relations, attr.AttributeID)
+		}
+	}
+
+	byID := map[int]*ServerObject{}
+	if len(ids) > 0 {
+		q := c.ByIDs(ids...)
+		q.SetAttributes(append([]string{"hostname"}, relations...)...)
+		q.IncludeRetired()
+		referencing, err := q.All(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rename: loading references: %w", err)
+		}
+		byID = referencing.ByObjectID()
+	}
+
+	plan := &RenamePlan{Object: obj, OldHostname: oldHostname, NewHostname: newHostname}
+	for _, ref := range references {
+		refObj, ok := byID[ref.objectID]
+		if !ok {
+			continue // deleted in the meantime
+		}
+		value := renameRelationValue(refObj.Get(ref.attribute), oldHostname, newHostname)
+		if err := refObj.Set(ref.attribute, value); err != nil {
+			return nil, err
+		}
+		plan.References = append(plan.References, Reference{Object: refObj, Attribute: ref.attribute})
+	}
+
+	if err := obj.Set("hostname", newHostname); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Commit renames the object and updates all references in a single commit,
+// so no dangling references remain if it fails.
+func (p *RenamePlan) Commit(ctx context.Context) (int, error) {
+	objects := ServerObjects{p.Object}
+	for _, ref := range p.References {
+		if !slices.Contains(objects, ref.Object) {
+			objects = append(objects, ref.Object)
+		}
+	}
+	return objects.Commit(ctx)
+}
+
+// Rollback discards the planned changes on all objects. As planning requires
+// obj to have no other changes, only the rename and the reference updates are
+// discarded.
+func (p *RenamePlan) Rollback() {
+	p.Object.Rollback()
+	for _, ref := range p.References {
+		ref.Object.Rollback()
+	}
+}
+
+// Rename renames obj to newHostname and updates all relation values referencing
+// it in a single commit. Use PlanRename to review the changes first.
+func (c *Client) Rename(ctx context.Context, obj *ServerObject, newHostname string) (*RenamePlan, error) {
+	plan, err := c.PlanRename(ctx, obj, newHostname)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := plan.Commit(ctx); err != nil {
+		plan.Rollback()
+		return nil, err
+	}
+	return plan, nil
+}
+
+// renameRelationValue replaces oldHostname by newHostname in a single or multi
+// relation value.
+func renameRelationValue(value any, oldHostname, newHostname string) any {
+	values := toAnySlice(value)
+	if values == nil {
+		if value == oldHostname {
+			return newHostname
+		}
+		return value
+	}
+
+	renamed := make([]any, len(values))
+	for i, v := range values {
+		if v == oldHostname {
+			v = newHostname
+		}
+		renamed[i] = v
+	}
+	return renamed
+}
@@ -0,0 +1,118 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRename(t *testing.T) {
+	var commits []commitRequest
+	failCommits := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		switch r.URL.Path {
+		case apiEndpointAttributes:
+			_, _ = w.Write([]byte(`{"status":"success","result":[
+				{"attribute_id":"hostname","type":"string"},
+				{"attribute_id":"hypervisor","type":"relation"},
+				{"attribute_id":"backup_hosts","type":"relation","multi":true},
+				{"attribute_id":"vms","type":"reverse","reversed_attribute":"hypervisor"}]}`))
+		case apiEndpointCommit:
+			if failCommits {
+				_, _ = w.Write([]byte(`{"status":"error","message":"hostname taken"}`))
+				return
+			}
+			var commit commitRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&commit))
+			commits = append(commits, commit)
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}`))
+		case apiEndpointQuery:
+			var req queryRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			switch {
+			case req.Filters["object_id"] != nil:
+				assert.ElementsMatch(t, []any{float64(10), float64(11)}, req.Filters["object_id"].(map[string]any)["Any"])
+				_, _ = w.Write([]byte(`{"status":"success","result":[
+					{"object_id":10,"hostname":"vm1","hypervisor":"hv1","backup_hosts":[]},
+					{"object_id":11,"hostname":"vm2","hypervisor":"hv1","backup_hosts":["hv0","hv1"]}]}`))
+			case req.Filters["hypervisor"] == "hv1":
+				_, _ = w.Write([]byte(`{"status":"success","result":[
+					{"object_id":10,"hostname":"vm1","hypervisor":"hv1"},
+					{"object_id":11,"hostname":"vm2","hypervisor":"hv1"}]}`))
+			case req.Filters["backup_hosts"] == "hv1":
+				_, _ = w.Write([]byte(`{"status":"success","result":[
+					{"object_id":11,"hostname":"vm2","backup_hosts":["hv0","hv1"]}]}`))
+			default:
+				t.Errorf("unexpected query %v", req.Filters)
+			}
+		}
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	ctx := context.Background()
+	hypervisor := func() *ServerObject {
+		return &ServerObject{client: client, attributes: Attributes{"object_id": float64(1), "hostname": "hv1"}, oldValues: Attributes{}}
+	}
+
+	t.Run("plan", func(t *testing.T) {
+		commits = nil
+		obj := hypervisor()
+		plan, err := client.PlanRename(ctx, obj, "hv1-new")
+		require.NoError(t, err)
+
+		require.Len(t, plan.References, 3)
+		assert.Equal(t, "hypervisor", plan.References[0].Attribute)
+		assert.Equal(t, "hv1-new", plan.References[0].Object.GetString("hypervisor"))
+		assert.Same(t, plan.References[1].Object, plan.References[2].Object, "objects referencing twice are merged")
+		assert.Equal(t, []any{"hv0", "hv1-new"}, plan.References[2].Object.Get("backup_hosts"))
+		assert.Equal(t, "hv1-new", obj.GetString("hostname"))
+		assert.Empty(t, commits, "planning sends no commit")
+
+		plan.Rollback()
+		assert.Equal(t, "hv1", obj.GetString("hostname"))
+	})
+
+	t.Run("rename commits everything at once", func(t *testing.T) {
+		commits = nil
+		_, err := client.Rename(ctx, hypervisor(), "hv1-new")
+		require.NoError(t, err)
+
+		require.Len(t, commits, 1)
+		assert.Len(t, commits[0].Changed, 3, "hypervisor, vm1 and vm2")
+	})
+
+	t.Run("uncommitted changes are refused", func(t *testing.T) {
+		commits = nil
+		obj := hypervisor()
+		obj.attributes["state"] = "online"
+		require.NoError(t, obj.Set("state", "maintenance"))
+
+		_, err := client.Rename(ctx, obj, "hv1-new")
+		require.ErrorIs(t, err, ErrUncommittedChanges)
+		assert.Equal(t, "maintenance", obj.GetString("state"), "pending edits are kept")
+		assert.Equal(t, "hv1", obj.GetString("hostname"))
+		assert.Empty(t, commits)
+	})
+
+	t.Run("failed rename rolls back only the rename", func(t *testing.T) {
+		failCommits = true
+		defer func() { failCommits = false }()
+		obj := hypervisor()
+		_, err := client.Rename(ctx, obj, "hv1-new")
+		require.Error(t, err)
+		assert.Equal(t, "hv1", obj.GetString("hostname"))
+		assert.Equal(t, StateConsistent, obj.CommitState())
+	})
+
+	t.Run("invalid hostname", func(t *testing.T) {
+		_, err := client.PlanRename(ctx, hypervisor(), "hv1")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,71 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		BaseURL: server.URL,
+		Token:   "tok",
+		Retry:   RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	q := client.NewQuery(Filters{})
+	_, err = q.All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+	assert.Len(t, ids[0], 32)
+	assert.Equal(t, ids[0], ids[1], "retries keep the request ID")
+
+	ids = nil
+	q = client.NewQuery(Filters{})
+	_, err = q.All(WithRequestID(context.Background(), "incoming-42"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"incoming-42", "incoming-42"}, ids)
+}
+
+func TestRequestIDInErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == apiEndpointCommit {
+			_, _ = w.Write([]byte(`{"status":"error","message":"hostname is taken"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"message": "invalid filter"}}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	ctx := WithRequestID(context.Background(), "abc123")
+
+	q := client.NewQuery(Filters{})
+	_, err := q.All(ctx)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "abc123", apiErr.RequestID)
+	assert.Equal(t, "HTTP error 400 Bad Request: invalid filter (request id abc123)", apiErr.Error())
+
+	_, err = client.sendCommit(ctx, commitRequest{})
+	assert.EqualError(t, err, "commit failed (request id abc123): hostname is taken")
+}
@@ -0,0 +1,164 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures automatic retries of idempotent requests (queries,
+// attribute listings, new_object defaults and change logs) on transient
+// failures. Commits are only retried with RetryCommits, API calls never, as
+// they may have been applied already. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per request, including the
+	// first one. Values below 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry, doubled for every
+	// further one. Defaults to 100ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. Defaults to 5s.
+	MaxBackoff time.Duration
+
+	// Jitter randomizes each delay by up to this fraction in either direction,
+	// e.g. 0.2 for ±20%, so that many clients don't retry in lockstep.
+	Jitter float64
+
+	// RetryableStatusCodes are the HTTP status codes worth retrying. Defaults
+	// to 502, 503 and 504. Timeouts and refused or reset connections are
+	// always retried; TLS, authentication and signing errors never are.
+	RetryableStatusCodes []int
+
+	// RetryCommits retries commits as well. Every commit carries an
+	// Idempotency-Key header that stays the same across attempts, so this is
+	// safe only if the server applies each key at most once.
+	RetryCommits bool
+}
+
+const (
+	defaultRetryInitialBackoff = 100 * time.Millisecond
+	defaultRetryMaxBackoff     = 5 * time.Second
+)
+
+var defaultRetryableStatusCodes = []int{502, 503, 504}
+
+// attempts returns the number of attempts for requests to endpoint.
+func (p RetryPolicy) attempts(endpoint string) int {
+	if isIdempotent(endpoint) || (p.RetryCommits && endpoint == apiEndpointCommit) {
+		return max(p.MaxAttempts, 1)
+	}
+	return 1
+}
+
+// isIdempotent reports whether requests to endpoint can safely be repeated.
+func isIdempotent(endpoint string) bool {
+	path, _, _ := strings.Cut(endpoint, "?")
+	switch path {
+	case apiEndpointQuery, apiEndpointAttributes, apiEndpointNewObject, apiEndpointChanges:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryable reports whether a request that failed with err should be retried.
+func (p RetryPolicy) retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		codes := p.RetryableStatusCodes
+		if codes == nil {
+			codes = defaultRetryableStatusCodes
+		}
+		return slices.Contains(codes, apiErr.StatusCode)
+	}
+
+	return isTransient(err)
+}
+
+// backoff returns the delay before the retry following the given attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	initial, maxBackoff := p.InitialBackoff, p.MaxBackoff
+	if initial <= 0 {
+		initial = defaultRetryInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultRetryMaxBackoff
+	}
+
+	delay := initial
+	for range attempt - 1 {
+		delay *= 2
+		if delay >= maxBackoff {
+			delay = maxBackoff
+			break
+		}
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(delay)) //nolint:gosec // jitter needs no crypto randomness
+	}
+	return max(delay, 0)
+}
+
+const (
+	// maxRateLimitRetries bounds how often a request rejected with 429 Too
+	// Many Requests is repeated before failing with ErrRateLimited.
+	maxRateLimitRetries = 3
+
+	// maxRetryAfter is the longest Retry-After delay the client waits for.
+	maxRetryAfter = time.Minute
+)
+
+// rateLimitWait returns how long to wait before repeating a request that
+// failed with err, if the server rejected it with 429 Too Many Requests. Such
+// requests were not processed, so all of them can be repeated, commits and API
+// calls included. Without a Retry-After header the retry policy's backoff for
+// the given attempt is used.
+func (p RetryPolicy) rateLimitWait(err error, attempt int) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+	return p.backoff(attempt), true
+}
+
+// parseRetryAfter parses a Retry-After header value, given either in seconds
+// or as an HTTP date. It returns zero for missing or malformed values and
+// dates in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,212 @@
+package adminapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	var calls int
+	failures := 0
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == apiEndpointCommit {
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{
+		BaseURL: server.URL,
+		Token:   "tok",
+		Retry:   RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	query := func() error {
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err := q.All(ctx)
+		return err
+	}
+
+	t.Run("query succeeds after transient failures", func(t *testing.T) {
+		calls, failures, status = 0, 2, http.StatusServiceUnavailable
+		require.NoError(t, query())
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("gives up after MaxAttempts", func(t *testing.T) {
+		calls, failures, status = 0, 5, http.StatusBadGateway
+		var apiErr *APIError
+		require.ErrorAs(t, query(), &apiErr)
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("non-retryable status", func(t *testing.T) {
+		calls, failures, status = 0, 1, http.StatusForbidden
+		require.Error(t, query())
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("commits are not retried", func(t *testing.T) {
+		calls, failures, status = 0, 1, http.StatusServiceUnavailable
+		obj := &ServerObject{client: client, attributes: Attributes{"object_id": float64(1), "hostname": "a"}, oldValues: Attributes{}}
+		require.NoError(t, obj.Set("hostname", "b"))
+		_, err := obj.Commit(ctx)
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	url := server.URL
+	server.Close() // connections are refused from now on
+
+	client, err := NewClient(Config{BaseURL: url, Token: "tok", Retry: RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}})
+	require.NoError(t, err)
+	assert.True(t, client.retry.retryable(context.Background(), func() error {
+		q := client.NewQuery(Filters{})
+		_, err := q.All(context.Background())
+		return err
+	}()))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, p.backoff(2))
+	assert.Equal(t, 400*time.Millisecond, p.backoff(3))
+	assert.Equal(t, time.Second, p.backoff(10))
+
+	p.Jitter = 0.5
+	for range 100 {
+		delay := p.backoff(2)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 300*time.Millisecond)
+	}
+}
+
+func TestRetryOnlyTransientErrors(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // expected handshake errors
+	server.StartTLS()
+	defer server.Close()
+
+	policy := RetryPolicy{MaxAttempts: 3}
+	query := func(cfg Config) error {
+		cfg.BaseURL = server.URL
+		cfg.Retry = policy
+		client, err := NewClient(cfg)
+		require.NoError(t, err)
+		q := client.NewQuery(Filters{})
+		_, err = q.All(context.Background())
+		require.Error(t, err)
+		return err
+	}
+
+	// the certificate of the test server is not trusted
+	assert.False(t, policy.retryable(context.Background(), query(Config{Token: "tok"})), "TLS errors")
+
+	vaultDown := TokenProviderFunc(func(context.Context) ([]byte, error) {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	})
+	assert.False(t, policy.retryable(context.Background(), query(Config{TokenProvider: vaultDown})), "token provider errors")
+
+	touchTimeout := fmt.Errorf("%w: %w", ErrSecurityKeyTouch, context.DeadlineExceeded)
+	assert.False(t, policy.retryable(context.Background(), touchTimeout), "signing errors")
+}
+
+func TestRetryRateLimited(t *testing.T) {
+	var calls int
+	limited := 0
+	retryAfter := "0"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= limited {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == apiEndpointCommit {
+			_, _ = w.Write([]byte(`{"status":"success","commit_id":1}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	ctx := context.Background()
+	query := func() error {
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err := q.All(ctx)
+		return err
+	}
+
+	t.Run("waits and retries without a retry policy", func(t *testing.T) {
+		calls, limited, retryAfter = 0, 2, "0"
+		require.NoError(t, query())
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("commits are retried", func(t *testing.T) {
+		calls, limited, retryAfter = 0, 1, "0"
+		obj := &ServerObject{client: client, attributes: Attributes{"object_id": float64(1), "hostname": "a"}, oldValues: Attributes{}}
+		require.NoError(t, obj.Set("hostname", "b"))
+		_, err := obj.Commit(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("gives up after maxRateLimitRetries", func(t *testing.T) {
+		calls, limited, retryAfter = 0, 10, "0"
+		err := query()
+		require.ErrorIs(t, err, ErrRateLimited)
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+		assert.Equal(t, maxRateLimitRetries+1, calls)
+	})
+
+	t.Run("too long Retry-After fails at once", func(t *testing.T) {
+		calls, limited, retryAfter = 0, 10, "3600"
+		err := query()
+		require.ErrorIs(t, err, ErrRateLimited)
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, time.Hour, apiErr.RetryAfter)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now), "date in the past")
+	assert.Zero(t, parseRetryAfter("-5", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter("", now))
+}
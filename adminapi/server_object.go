@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"maps"
 	"reflect"
+	"slices"
 )
 
 // ServerObjects is a slice of ServerObject pointers
@@ -12,10 +13,13 @@ type ServerObjects []*ServerObject
 
 // ServerObject is a map of key-value attributes of a SA object
 type ServerObject struct {
-	client     *Client // client used to commit this object; nil falls back to the env default
+	client     *Client // client used to commit this object; nil falls back to the context client
 	attributes Attributes
 	oldValues  Attributes // tracks original values before first modification
 	deleted    bool
+	related    map[string]ServerObjects // joined objects, see Query.AddRelatedAttributes
+	validator  Validator                // checks values passed to Set, see SetValidator
+	touched    map[string]bool          // attributes committed even if unchanged, see Touch
 }
 
 // Get safely retrieves an attribute, converting JSON float64 numbers to int when needed
@@ -116,6 +120,49 @@ func (s *ServerObject) ObjectID() int {
 	return 0
 }
 
+// Has reports whether the object holds the attribute at all, even with a
+// null value. Only such attributes can be changed with Set.
+func (s *ServerObject) Has(attribute string) bool {
+	_, ok := s.attributes[attribute]
+	return ok
+}
+
+// IsSet reports whether the object holds the attribute with a value: not
+// null, not an empty string and not an empty multi attribute.
+func (s *ServerObject) IsSet(attribute string) bool {
+	switch v := s.attributes[attribute].(type) {
+	case nil:
+		return false
+	case string:
+		return v != ""
+	default:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+			return rv.Len() > 0
+		}
+		return true
+	}
+}
+
+// Keys returns the names of all attributes the object holds, sorted. For
+// queried objects these are the restricted attributes, or all of them.
+func (s *ServerObject) Keys() []string {
+	return slices.Sorted(maps.Keys(s.attributes))
+}
+
+// Each calls fn for every attribute in the order of Keys, with the value as
+// received from the server: unlike Get, JSON numbers stay float64.
+func (s *ServerObject) Each(fn func(key string, value any)) {
+	for _, key := range s.Keys() {
+		fn(key, s.attributes[key])
+	}
+}
+
+// ServerType returns the "servertype" attribute of the object, or "" if it
+// was not fetched. Queries filtering on servertype always fetch it.
+func (s *ServerObject) ServerType() string {
+	return s.GetString("servertype")
+}
+
 // CommitState represents the state of a ServerObject with respect to pending changes.
 type CommitState string
 
@@ -131,28 +178,167 @@ const (
 )
 
 // Set modifies an attribute value and tracks the change for commit.
-func (s *ServerObject) Set(key string, value any) error {
+func (s *ServerObject) Set(key string, value any) (err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
 	if _, exists := s.attributes[key]; !exists {
 		return fmt.Errorf("attribute %q: %w", key, ErrUnknownAttribute)
 	}
-
-	// Save the original value on first modification only
-	if _, tracked := s.oldValues[key]; !tracked {
-		old := s.attributes[key]
-		// Deep copy slices to prevent aliasing (handle any slice type)
-		if oldSlice := toAnySlice(old); oldSlice != nil {
-			cp := make([]any, len(oldSlice))
-			copy(cp, oldSlice)
-			s.oldValues[key] = cp
-		} else {
-			s.oldValues[key] = old
+	if s.validator != nil {
+		if err := s.validator(key, value); err != nil {
+			return fmt.Errorf("attribute %q: %w", key, err)
 		}
 	}
 
+	s.trackOldValue(key)
 	s.attributes[key] = value
 	return nil
 }
 
+// trackOldValue saves the original value of an attribute on its first
+// modification only.
+func (s *ServerObject) trackOldValue(key string) {
+	if _, tracked := s.oldValues[key]; tracked {
+		return
+	}
+	old := s.attributes[key]
+	// Deep copy slices to prevent aliasing (handle any slice type)
+	if oldSlice := toAnySlice(old); oldSlice != nil {
+		cp := make([]any, len(oldSlice))
+		copy(cp, oldSlice)
+		s.oldValues[key] = cp
+	} else {
+		s.oldValues[key] = old
+	}
+}
+
+// Touch marks an attribute as changed even if its value stays the same, so
+// committing the object sends it and triggers server-side hooks that only
+// fire on committed changes, e.g. a DNS re-sync. An unchanged single-valued
+// attribute is sent as an update to the same value, an unchanged multi
+// attribute as adding its current values. Like Set, it fails with
+// ErrUnknownAttribute for attributes the object does not hold.
+func (s *ServerObject) Touch(key string) (err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
+	if _, exists := s.attributes[key]; !exists {
+		return fmt.Errorf("attribute %q: %w", key, ErrUnknownAttribute)
+	}
+	s.trackOldValue(key)
+	if s.touched == nil {
+		s.touched = make(map[string]bool)
+	}
+	s.touched[key] = true
+	return nil
+}
+
+// SetIfChanged is like Set, but leaves the object untouched if value equals
+// the current value the way change tracking compares them, reporting whether
+// a change was recorded. Reconciliation loops can count actual drift with it.
+func (s *ServerObject) SetIfChanged(key string, value any) (changed bool, err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return false, ErrNilObject
+	}
+
+	current, exists := s.attributes[key]
+	if !exists {
+		return false, fmt.Errorf("attribute %q: %w", key, ErrUnknownAttribute)
+	}
+	if jsonEqual(current, value) {
+		return false, nil
+	}
+	if err := s.Set(key, value); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Unset clears an attribute and tracks the change for commit: multi
+// attributes become empty and commit as removal of all their values, others
+// become null. Like Set, it fails with ErrUnknownAttribute for attributes the
+// object does not hold.
+func (s *ServerObject) Unset(key string) (err error) {
+	defer recoverPanic(s.boundClient(), &err)
+
+	if s == nil {
+		return ErrNilObject
+	}
+
+	if rv := reflect.ValueOf(s.attributes[key]); rv.Kind() == reflect.Slice {
+		return s.Set(key, reflect.MakeSlice(rv.Type(), 0, 0).Interface())
+	}
+	return s.Set(key, nil)
+}
+
+// Clone returns a deep copy of the object including its pending changes:
+// attributes, multi attribute values and change tracking can be modified on
+// either without affecting the other, e.g. to hand objects to worker
+// goroutines or to compute what-if changes. The copy commits with the same
+// client. Cloning a nil object returns nil.
+func (s *ServerObject) Clone() *ServerObject {
+	if s == nil {
+		return nil
+	}
+	clone := &ServerObject{
+		client:     s.client,
+		attributes: cloneAttributeValues(s.attributes),
+		oldValues:  cloneAttributeValues(s.oldValues),
+		deleted:    s.deleted,
+		validator:  s.validator,
+		touched:    maps.Clone(s.touched),
+	}
+	if s.related != nil {
+		clone.related = make(map[string]ServerObjects, len(s.related))
+		for attribute, objects := range s.related {
+			clone.related[attribute] = objects.Clone()
+		}
+	}
+	return clone
+}
+
+// Clone returns deep copies of the objects, see ServerObject.Clone.
+func (s ServerObjects) Clone() ServerObjects {
+	if s == nil {
+		return nil
+	}
+	clone := make(ServerObjects, len(s))
+	for i, obj := range s {
+		clone[i] = obj.Clone()
+	}
+	return clone
+}
+
+// cloneAttributeValues deep-copies attrs including slice and map values.
+func cloneAttributeValues(attrs Attributes) Attributes {
+	if attrs == nil {
+		return nil
+	}
+	clone := make(Attributes, len(attrs))
+	for key, value := range attrs {
+		clone[key] = cloneFilterValue(value)
+	}
+	return clone
+}
+
+// boundClient returns the object's client, or nil for a nil object, so panic
+// recovery can be set up before the object is dereferenced.
+func (s *ServerObject) boundClient() *Client {
+	if s == nil {
+		return nil
+	}
+	return s.client
+}
+
 // Delete marks the object for deletion on the next commit.
 func (s *ServerObject) Delete() {
 	s.deleted = true
@@ -163,6 +349,7 @@ func (s *ServerObject) Rollback() {
 	s.deleted = false
 	maps.Copy(s.attributes, s.oldValues)
 	s.oldValues = Attributes{}
+	s.touched = nil
 }
 
 // CommitState returns the current state of the object with respect to pending changes.
@@ -173,6 +360,9 @@ func (s *ServerObject) CommitState() CommitState {
 	if s.deleted {
 		return StateDeleted
 	}
+	if len(s.touched) > 0 {
+		return StateChanged
+	}
 	for key, oldVal := range s.oldValues {
 		newVal := s.attributes[key]
 		if !jsonEqual(oldVal, newVal) {
@@ -188,7 +378,7 @@ func (s *ServerObject) serializeChanges() Attributes {
 
 	for key, oldVal := range s.oldValues {
 		newVal := s.attributes[key]
-		if jsonEqual(oldVal, newVal) {
+		if jsonEqual(oldVal, newVal) && !s.touched[key] {
 			continue
 		}
 
@@ -199,6 +389,9 @@ func (s *ServerObject) serializeChanges() Attributes {
 		if oldSlice != nil && newSlice != nil {
 			// Multi-attribute: compute add/remove sets
 			add, remove := sliceDiff(oldSlice, newSlice)
+			if len(add) == 0 && len(remove) == 0 {
+				add = newSlice // touched: re-add the current values
+			}
 			changes[key] = map[string]any{
 				"action": "multi",
 				"add":    add,
@@ -218,12 +411,33 @@ func (s *ServerObject) serializeChanges() Attributes {
 
 func (s *ServerObject) confirmChanges() {
 	s.oldValues = Attributes{}
+	s.touched = nil
 	if s.deleted {
 		s.attributes["object_id"] = nil
 		s.deleted = false
 	}
 }
 
+// Equal reports whether both objects hold the same attributes with the same
+// values, compared like change tracking compares them, so 4 and 4.0 are
+// equal but multi attributes with differently ordered values are not.
+// Pending changes are not considered, only the current values.
+func (s *ServerObject) Equal(other *ServerObject) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	if len(s.attributes) != len(other.attributes) {
+		return false
+	}
+	for key, value := range s.attributes {
+		otherValue, ok := other.attributes[key]
+		if !ok || !jsonEqual(value, otherValue) {
+			return false
+		}
+	}
+	return true
+}
+
 // jsonEqual compares two values using JSON serialization for consistency with the Python client.
 func jsonEqual(a, b any) bool {
 	aj, _ := json.Marshal(a)
@@ -0,0 +1,62 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// serverObjectJSON is the JSON encoding of a ServerObject.
+type serverObjectJSON struct {
+	Attributes Attributes `json:"attributes"`
+	OldValues  Attributes `json:"old_values,omitempty"`
+	Deleted    bool       `json:"deleted,omitempty"`
+	Touched    []string   `json:"touched,omitempty"`
+}
+
+// MarshalJSON encodes the object's attributes along with its pending changes,
+// e.g. {"attributes":{"hostname":"web01","object_id":42}} for an unchanged
+// object, so results can be cached to disk, inspected with jq or passed to
+// other processes. Pending changes are encoded as the original values of the
+// changed attributes in "old_values", attributes marked with Touch in
+// "touched" and a pending deletion as "deleted":true. Joined related objects
+// are not included.
+func (s *ServerObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(serverObjectJSON{
+		Attributes: s.attributes,
+		OldValues:  s.oldValues,
+		Deleted:    s.deleted,
+		Touched:    slices.Sorted(maps.Keys(s.touched)),
+	})
+}
+
+// UnmarshalJSON decodes an object encoded by MarshalJSON into a working
+// object with the same pending changes, which can be modified and committed
+// like a queried one. It is not bound to a client, so Commit needs one
+// attached to the context with WithClient. Numbers decode as float64 like
+// query results.
+func (s *ServerObject) UnmarshalJSON(data []byte) error {
+	var decoded serverObjectJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Attributes == nil {
+		return errors.New("decoding server object: missing attributes")
+	}
+	if decoded.OldValues == nil {
+		decoded.OldValues = Attributes{}
+	}
+	*s = ServerObject{
+		attributes: decoded.Attributes,
+		oldValues:  decoded.OldValues,
+		deleted:    decoded.Deleted,
+	}
+	for _, key := range decoded.Touched {
+		if err := s.Touch(key); err != nil {
+			return fmt.Errorf("decoding server object: %w", err)
+		}
+	}
+	return nil
+}
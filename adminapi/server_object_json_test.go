@@ -0,0 +1,59 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerObjectJSON(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"object_id": float64(42), "hostname": "web01", "tags": []any{"web"}},
+		oldValues:  Attributes{},
+	}
+
+	data, err := json.Marshal(obj)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"attributes":{"object_id":42,"hostname":"web01","tags":["web"]}}`, string(data))
+
+	require.NoError(t, obj.Set("hostname", "web02"))
+	require.NoError(t, obj.AddToMulti("tags", "prod"))
+	data, err = json.Marshal(ServerObjects{obj})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{
+		"attributes":{"object_id":42,"hostname":"web02","tags":["web","prod"]},
+		"old_values":{"hostname":"web01","tags":["web"]}
+	}]`, string(data))
+
+	var decoded ServerObjects
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded, 1)
+	assert.Equal(t, obj.attributes, decoded[0].attributes)
+	assert.Equal(t, StateChanged, decoded[0].CommitState())
+	assert.Equal(t, obj.serializeChanges(), decoded[0].serializeChanges())
+
+	obj.Delete()
+	data, err = json.Marshal(obj)
+	require.NoError(t, err)
+	var deleted ServerObject
+	require.NoError(t, json.Unmarshal(data, &deleted))
+	assert.Equal(t, StateDeleted, deleted.CommitState())
+}
+
+func TestServerObjectJSONUnchanged(t *testing.T) {
+	var obj ServerObject
+	require.NoError(t, json.Unmarshal([]byte(`{"attributes":{"object_id":1,"hostname":"web01"}}`), &obj))
+	assert.Equal(t, StateConsistent, obj.CommitState())
+	assert.Equal(t, 1, obj.ObjectID())
+
+	require.NoError(t, obj.Set("hostname", "web02"))
+	assert.Equal(t, StateChanged, obj.CommitState())
+}
+
+func TestServerObjectJSONInvalid(t *testing.T) {
+	var obj ServerObject
+	require.ErrorContains(t, json.Unmarshal([]byte(`{"hostname":"web01"}`), &obj), "missing attributes")
+	require.Error(t, json.Unmarshal([]byte(`[1]`), &obj))
+}
@@ -1,6 +1,7 @@
 package adminapi
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -442,3 +443,199 @@ func TestToAnySlice_VariousTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestKeysEach(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"hostname": "web01", "object_id": float64(1), "tags": []any{"a"}},
+		oldValues:  Attributes{},
+	}
+	assert.Equal(t, []string{"hostname", "object_id", "tags"}, obj.Keys())
+
+	var keys []string
+	values := Attributes{}
+	obj.Each(func(key string, value any) {
+		keys = append(keys, key)
+		values[key] = value
+	})
+	assert.Equal(t, obj.Keys(), keys)
+	assert.Equal(t, obj.attributes, values)
+
+	assert.Empty(t, (&ServerObject{}).Keys())
+}
+
+func TestHasIsSet(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"hostname":    "web01",
+			"description": "",
+			"backup":      nil,
+			"tags":        []any{},
+			"nil_tags":    MultiAttr(nil),
+			"services":    MultiAttr{"nginx"},
+			"active":      false,
+			"num_cpu":     float64(0),
+		},
+		oldValues: Attributes{},
+	}
+
+	for attribute, isSet := range map[string]bool{
+		"hostname":    true,
+		"description": false,
+		"backup":      false,
+		"tags":        false,
+		"nil_tags":    false,
+		"services":    true,
+		"active":      true,
+		"num_cpu":     true,
+	} {
+		assert.True(t, obj.Has(attribute), attribute)
+		assert.Equal(t, isSet, obj.IsSet(attribute), attribute)
+	}
+	assert.False(t, obj.Has("unknown"))
+	assert.False(t, obj.IsSet("unknown"))
+}
+
+func TestUnset(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id":   float64(42),
+			"description": "old",
+			"tags":        []any{"web", "prod"},
+			"services":    MultiAttr{"nginx"},
+		},
+		oldValues: Attributes{},
+	}
+
+	require.NoError(t, obj.Unset("description"))
+	require.NoError(t, obj.Unset("tags"))
+	require.NoError(t, obj.Unset("services"))
+	require.ErrorIs(t, obj.Unset("unknown"), ErrUnknownAttribute)
+
+	assert.True(t, obj.Has("description"))
+	assert.False(t, obj.IsSet("description"))
+	assert.Equal(t, MultiAttr{}, obj.Get("services"))
+
+	changes := obj.serializeChanges()
+	assert.Equal(t, map[string]any{"action": "update", "old": "old", "new": nil}, changes["description"])
+	tagChange := changes["tags"].(map[string]any)
+	assert.Equal(t, "multi", tagChange["action"])
+	assert.Empty(t, tagChange["add"])
+	assert.ElementsMatch(t, []any{"web", "prod"}, tagChange["remove"])
+	assert.ElementsMatch(t, []any{"nginx"}, changes["services"].(map[string]any)["remove"])
+}
+
+func TestClone(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id": float64(42),
+			"hostname":  "new.local",
+			"tags":      []any{"web"},
+			"services":  MultiAttr{"nginx"},
+			"limits":    map[string]any{"cpu": []any{1.0}},
+		},
+		oldValues: Attributes{"hostname": "old.local", "tags": []any{"web", "old"}},
+		related: map[string]ServerObjects{
+			"hypervisor": {{attributes: Attributes{"hostname": "hv1"}, oldValues: Attributes{}}},
+		},
+	}
+
+	clone := obj.Clone()
+	assert.Equal(t, obj, clone)
+
+	require.NoError(t, clone.AddToMulti("tags", "prod"))
+	require.NoError(t, clone.Set("hostname", "clone.local"))
+	clone.attributes["services"].(MultiAttr)[0] = "apache"
+	clone.attributes["limits"].(map[string]any)["cpu"].([]any)[0] = 2.0
+	clone.oldValues["tags"].([]any)[0] = "changed"
+	require.NoError(t, clone.GetRelated("hypervisor").Set("hostname", "hv2"))
+	clone.Delete()
+
+	assert.Equal(t, []any{"web"}, obj.Get("tags"))
+	assert.Equal(t, "new.local", obj.Get("hostname"))
+	assert.Equal(t, MultiAttr{"nginx"}, obj.Get("services"))
+	assert.Equal(t, map[string]any{"cpu": []any{1.0}}, obj.Get("limits"))
+	assert.Equal(t, Attributes{"hostname": "old.local", "tags": []any{"web", "old"}}, obj.oldValues)
+	assert.Equal(t, "hv1", obj.GetRelated("hypervisor").GetString("hostname"))
+	assert.Equal(t, StateChanged, obj.CommitState())
+
+	assert.Nil(t, (*ServerObject)(nil).Clone())
+	assert.Equal(t, ServerObjects{nil, clone}, ServerObjects{nil, clone}.Clone())
+}
+
+func TestEqual(t *testing.T) {
+	a := &ServerObject{attributes: Attributes{"object_id": float64(1), "num_cpu": float64(4), "tags": []any{"a", "b"}}}
+	b := &ServerObject{attributes: Attributes{"object_id": 1, "num_cpu": 4, "tags": MultiAttr{"a", "b"}}}
+	assert.True(t, a.Equal(b))
+	assert.True(t, b.Equal(a))
+
+	b.attributes["tags"] = []any{"b", "a"}
+	assert.False(t, a.Equal(b), "multi values are compared in order")
+
+	c := a.Clone()
+	c.attributes["extra"] = nil
+	assert.False(t, a.Equal(c))
+	assert.False(t, c.Equal(a))
+
+	assert.False(t, a.Equal(nil))
+	assert.True(t, (*ServerObject)(nil).Equal(nil))
+}
+
+func TestSetIfChanged(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"object_id": float64(42), "num_cpu": float64(4), "tags": []any{"web"}},
+		oldValues:  Attributes{},
+	}
+
+	changed, err := obj.SetIfChanged("num_cpu", 4)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	changed, err = obj.SetIfChanged("tags", MultiAttr{"web"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, obj.oldValues, "non-changes are not tracked")
+
+	changed, err = obj.SetIfChanged("num_cpu", 8)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, StateChanged, obj.CommitState())
+
+	_, err = obj.SetIfChanged("unknown", 1)
+	require.ErrorIs(t, err, ErrUnknownAttribute)
+
+	obj.SetValidator(func(string, any) error { return ErrInvalidValue })
+	changed, err = obj.SetIfChanged("num_cpu", 16)
+	require.ErrorIs(t, err, ErrInvalidValue)
+	assert.False(t, changed)
+}
+
+func TestTouch(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"object_id": float64(42), "hostname": "web01", "tags": []any{"web"}, "state": "online"},
+		oldValues:  Attributes{},
+	}
+	require.NoError(t, obj.Touch("hostname"))
+	require.NoError(t, obj.Touch("tags"))
+	require.ErrorIs(t, obj.Touch("unknown"), ErrUnknownAttribute)
+	assert.Equal(t, StateChanged, obj.CommitState())
+
+	changes := obj.serializeChanges()
+	assert.Equal(t, map[string]any{"action": "update", "old": "web01", "new": "web01"}, changes["hostname"])
+	assert.Equal(t, map[string]any{"action": "multi", "add": []any{"web"}, "remove": []any{}}, changes["tags"])
+	assert.NotContains(t, changes, "state")
+	assert.Len(t, obj.Diff().Changes, 2)
+
+	clone := obj.Clone()
+	data, err := json.Marshal(obj)
+	require.NoError(t, err)
+	var decoded ServerObject
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, changes, decoded.serializeChanges())
+
+	obj.Rollback()
+	assert.Equal(t, StateConsistent, obj.CommitState())
+	assert.Equal(t, StateChanged, clone.CommitState(), "clones keep their own touched attributes")
+
+	require.NoError(t, clone.Touch("state"))
+	clone.confirmChanges()
+	assert.Equal(t, StateConsistent, clone.CommitState())
+}
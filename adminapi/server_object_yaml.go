@@ -0,0 +1,34 @@
+package adminapi
+
+import "gopkg.in/yaml.v3"
+
+// MarshalYAML makes yaml.v3 encode the object as a mapping of its
+// attributes, sorted by name.
+func (s *ServerObject) MarshalYAML() (any, error) {
+	return s.attributes, nil
+}
+
+// ToYAML encodes the object's attributes as a YAML mapping sorted by name,
+// with multi attributes as block sequences, for reviewing inventory data in
+// diffs:
+//
+//	hostname: web01
+//	object_id: 42
+//	tags:
+//	    - prod
+//	    - web
+//
+// Pending changes are not marked, the current values are encoded.
+func (s *ServerObject) ToYAML() ([]byte, error) {
+	return yaml.Marshal(s)
+}
+
+// ToYAML encodes the objects as a YAML sequence of attribute mappings, in the
+// order of the slice. Sort them first, e.g. with SortByAttribute, for stable
+// diffs.
+func (s ServerObjects) ToYAML() ([]byte, error) {
+	if s == nil {
+		s = ServerObjects{}
+	}
+	return yaml.Marshal(s)
+}
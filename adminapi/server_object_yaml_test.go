@@ -0,0 +1,60 @@
+package adminapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestServerObjectToYAML(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{
+			"object_id":   float64(42),
+			"hostname":    "web01",
+			"load":        0.5,
+			"tags":        []any{"web", "prod"},
+			"description": "yes",
+			"backup":      nil,
+			"services":    MultiAttr{},
+		},
+		oldValues: Attributes{},
+	}
+
+	data, err := obj.ToYAML()
+	require.NoError(t, err)
+	assert.Equal(t, `backup: null
+description: "yes"
+hostname: web01
+load: 0.5
+object_id: 42
+services: []
+tags:
+    - web
+    - prod
+`, string(data))
+
+	var decoded Attributes
+	require.NoError(t, yaml.Unmarshal(data, &decoded))
+	assert.Equal(t, "yes", decoded["description"], "strings YAML reads as bools stay strings")
+}
+
+func TestServerObjectsToYAML(t *testing.T) {
+	objects := ServerObjects{
+		{attributes: Attributes{"hostname": "web01", "object_id": float64(1)}},
+		{attributes: Attributes{"hostname": "db01", "object_id": float64(2)}},
+	}
+
+	data, err := objects.ToYAML()
+	require.NoError(t, err)
+	assert.Equal(t, `- hostname: web01
+  object_id: 1
+- hostname: db01
+  object_id: 2
+`, string(data))
+
+	data, err = ServerObjects(nil).ToYAML()
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", string(data))
+}
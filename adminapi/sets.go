@@ -0,0 +1,137 @@
+package adminapi
+
+import "slices"
+
+// Set operations combine the results of several queries by object_id, e.g.
+// "online and in these projects, minus decommissioned ones". Objects without
+// an object_id, i.e. created but not yet committed ones, are only equal to
+// themselves. The results keep the order of the receiver, followed by the
+// order of the arguments, and refer to the same objects rather than copies.
+
+// Union returns the objects in s or any of others, each only once.
+func (s ServerObjects) Union(others ...ServerObjects) ServerObjects {
+	seen := make(map[any]bool, len(s))
+	result := make(ServerObjects, 0, len(s))
+	for _, objects := range append([]ServerObjects{s}, others...) {
+		for _, obj := range objects {
+			if key := obj.setKey(); !seen[key] {
+				seen[key] = true
+				result = append(result, obj)
+			}
+		}
+	}
+	return result
+}
+
+// Intersect returns the objects of s that are also in all of others.
+func (s ServerObjects) Intersect(others ...ServerObjects) ServerObjects {
+	keys := make([]map[any]bool, len(others))
+	for i, objects := range others {
+		keys[i] = objects.setKeys()
+	}
+
+	result := ServerObjects{}
+	seen := make(map[any]bool, len(s))
+outer:
+	for _, obj := range s {
+		key := obj.setKey()
+		if seen[key] {
+			continue
+		}
+		for _, other := range keys {
+			if !other[key] {
+				continue outer
+			}
+		}
+		seen[key] = true
+		result = append(result, obj)
+	}
+	return result
+}
+
+// Difference returns the objects of s that are in none of others.
+func (s ServerObjects) Difference(others ...ServerObjects) ServerObjects {
+	excluded := make(map[any]bool)
+	for _, objects := range others {
+		for _, obj := range objects {
+			excluded[obj.setKey()] = true
+		}
+	}
+
+	result := ServerObjects{}
+	for _, obj := range s {
+		if key := obj.setKey(); !excluded[key] {
+			excluded[key] = true // each object only once
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// FilterByType returns the objects of any of the given servertypes, e.g. to
+// split a mixed result by type:
+//
+//	vms := servers.FilterByType("vm")
+func (s ServerObjects) FilterByType(serverTypes ...string) ServerObjects {
+	result := ServerObjects{}
+	for _, obj := range s {
+		if slices.Contains(serverTypes, obj.ServerType()) {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// EqualTo reports whether s and other hold equal objects, see
+// ServerObject.Equal, regardless of their order, e.g. to compare a desired
+// with the actual state. Objects are paired by object_id, or by hostname if
+// they have none.
+func (s ServerObjects) EqualTo(other ServerObjects) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	byKey := make(map[any]*ServerObject, len(other))
+	for _, obj := range other {
+		byKey[obj.equalKey()] = obj
+	}
+	if len(byKey) != len(other) {
+		return false // duplicates can't be paired
+	}
+	for _, obj := range s {
+		match, ok := byKey[obj.equalKey()]
+		if !ok || !obj.Equal(match) {
+			return false
+		}
+		delete(byKey, obj.equalKey())
+	}
+	return true
+}
+
+// equalKey pairs objects in EqualTo: the object_id, or the hostname if the
+// object has none.
+func (s *ServerObject) equalKey() any {
+	if s == nil {
+		return nil
+	}
+	if id := s.ObjectID(); id != 0 {
+		return id
+	}
+	return "hostname:" + s.GetString("hostname")
+}
+
+// setKey identifies the object in set operations: its object_id, or the
+// object itself if it has none.
+func (s *ServerObject) setKey() any {
+	if id := s.ObjectID(); id != 0 {
+		return id
+	}
+	return s
+}
+
+func (s ServerObjects) setKeys() map[any]bool {
+	keys := make(map[any]bool, len(s))
+	for _, obj := range s {
+		keys[obj.setKey()] = true
+	}
+	return keys
+}
@@ -0,0 +1,85 @@
+package adminapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerObjectsSetOperations(t *testing.T) {
+	object := func(id int, hostname string) *ServerObject {
+		return &ServerObject{attributes: Attributes{"object_id": id, "hostname": hostname}}
+	}
+	created := &ServerObject{attributes: Attributes{"hostname": "new"}}
+
+	online := ServerObjects{object(1, "a"), object(2, "b"), object(3, "c"), created}
+	projects := ServerObjects{object(3, "c"), object(2, "b"), object(4, "d")}
+	decommissioned := ServerObjects{object(3, "c")}
+
+	assert.Equal(t, []string{"a", "b", "c", "new", "d"}, online.Union(projects).Hostnames())
+	assert.Equal(t, []string{"b", "c"}, online.Intersect(projects).Hostnames())
+	assert.Equal(t, []string{"b"}, online.Intersect(projects).Difference(decommissioned).Hostnames())
+	assert.Equal(t, []string{"a", "new"}, online.Difference(projects, decommissioned).Hostnames())
+	assert.Same(t, online[1], online.Intersect(projects)[0], "the receiver's objects are returned")
+
+	assert.Equal(t, []string{"new"}, ServerObjects{created}.Intersect(ServerObjects{created}).Hostnames())
+	assert.Empty(t, ServerObjects{created}.Intersect(ServerObjects{{attributes: Attributes{"hostname": "new"}}}))
+	assert.Equal(t, online.Hostnames(), online.Intersect().Hostnames(), "no others keep all objects")
+	assert.Empty(t, ServerObjects{}.Union())
+	assert.Equal(t, []string{"a"}, ServerObjects{object(1, "a"), object(1, "a")}.Difference().Hostnames())
+}
+
+func TestEqualTo(t *testing.T) {
+	object := func(id any, hostname string, state string) *ServerObject {
+		return &ServerObject{attributes: Attributes{"object_id": id, "hostname": hostname, "state": state}}
+	}
+	actual := ServerObjects{object(float64(1), "web01", "online"), object(float64(2), "web02", "online"), object(nil, "new01", "online")}
+	desired := ServerObjects{object(nil, "new01", "online"), object(2, "web02", "online"), object(1, "web01", "online")}
+	assert.True(t, actual.EqualTo(desired))
+	assert.True(t, desired.EqualTo(actual))
+
+	desired[1].attributes["state"] = "maintenance"
+	assert.False(t, actual.EqualTo(desired))
+
+	assert.False(t, actual.EqualTo(actual[:2]))
+	assert.False(t, ServerObjects{actual[0], actual[0]}.EqualTo(ServerObjects{actual[0], actual[1]}))
+	assert.False(t, ServerObjects{actual[0], actual[1]}.EqualTo(ServerObjects{actual[0], actual[0]}))
+	assert.True(t, ServerObjects{}.EqualTo(nil))
+}
+
+func TestFilterByType(t *testing.T) {
+	vm := &ServerObject{attributes: Attributes{"object_id": 1, "servertype": "vm"}}
+	hv := &ServerObject{attributes: Attributes{"object_id": 2, "servertype": "hypervisor"}}
+	unknown := &ServerObject{attributes: Attributes{"object_id": 3}}
+	objects := ServerObjects{vm, hv, unknown}
+
+	assert.Equal(t, "vm", vm.ServerType())
+	assert.Empty(t, unknown.ServerType())
+	assert.Equal(t, ServerObjects{vm}, objects.FilterByType("vm"))
+	assert.Equal(t, ServerObjects{vm, hv}, objects.FilterByType("hypervisor", "vm"))
+	assert.Equal(t, ServerObjects{}, objects.FilterByType("switch"))
+}
+
+func TestServerTypeDefaultAttribute(t *testing.T) {
+	client := mustClient(t, "https://example.com")
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"hostname=web01", []string{"object_id", "hostname"}},
+		{"servertype=vm", []string{"object_id", "hostname"}},
+		{"servertype=any(vm hypervisor)", []string{"object_id", "hostname", "servertype"}},
+		{"servertype=vm state=online; servertype=vm state=maintenance", []string{"object_id", "hostname"}},
+		{"servertype=vm state=online; servertype=hypervisor hostname=hv01", []string{"object_id", "hostname", "servertype"}},
+	} {
+		q, err := client.FromQuery(tt.query)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, q.restrictedAttributes, tt.query)
+	}
+
+	q := client.NewUnionQuery(Filters{"servertype": "vm", "state": "online"}, Filters{"servertype": "hypervisor", "hostname": "hv01"})
+	assert.Contains(t, q.restrictedAttributes, "servertype")
+	q = client.NewQuery(Filters{"servertype": Regexp("^(vm|hypervisor)$")})
+	assert.Contains(t, q.restrictedAttributes, "servertype")
+}
@@ -0,0 +1,164 @@
+package adminapi
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of the attributes of all objects matched by
+// a query. Snapshots are plain JSON documents, so they can be archived and
+// later compared with DiffSnapshots, e.g. for compliance reporting.
+type Snapshot struct {
+	TakenAt time.Time    `json:"taken_at"`
+	Query   string       `json:"query,omitempty"` // informational, set by the caller
+	Objects []Attributes `json:"objects"`         // ordered by object_id
+}
+
+// Snapshot runs the query and captures the attributes of all matching objects.
+func (q *Query) Snapshot(ctx context.Context) (*Snapshot, error) {
+	objects, err := q.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &Snapshot{
+		TakenAt: time.Now().UTC(),
+		Objects: make([]Attributes, len(objects)),
+	}
+	for i, obj := range objects {
+		snapshot.Objects[i] = maps.Clone(obj.attributes)
+	}
+	slices.SortFunc(snapshot.Objects, func(a, b Attributes) int {
+		return cmp.Compare(attributesObjectID(a), attributesObjectID(b))
+	})
+
+	return snapshot, nil
+}
+
+// ChangeSet describes how the inventory changed between two snapshots.
+type ChangeSet struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Created []Attributes    `json:"created"`
+	Deleted []Attributes    `json:"deleted"`
+	Changed []ObjectChanges `json:"changed"`
+}
+
+// ObjectChanges lists the changed attributes of an object present in both
+// snapshots.
+type ObjectChanges struct {
+	ObjectID   int                        `json:"object_id"`
+	Hostname   string                     `json:"hostname"`
+	Attributes map[string]AttributeChange `json:"attributes"`
+}
+
+// AttributeChange holds the old and new value of a changed attribute.
+type AttributeChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Empty reports whether the change set contains no changes.
+func (c *ChangeSet) Empty() bool {
+	return len(c.Created) == 0 && len(c.Deleted) == 0 && len(c.Changed) == 0
+}
+
+// DiffSnapshots compares two snapshots by object_id. Objects only in newer are
+// reported as created, objects only in older as deleted. Attributes missing
+// from one side compare as nil, and multi attributes are compared ignoring
+// the order of their values. All lists are ordered by object_id. Objects
+// without an object_id cannot be matched between snapshots and are skipped.
+func DiffSnapshots(older, newer *Snapshot) *ChangeSet {
+	changes := &ChangeSet{
+		From:    older.TakenAt,
+		To:      newer.TakenAt,
+		Created: []Attributes{},
+		Deleted: []Attributes{},
+		Changed: []ObjectChanges{},
+	}
+
+	oldByID := objectsByID(older.Objects)
+	newByID := objectsByID(newer.Objects)
+
+	for _, id := range slices.Sorted(maps.Keys(oldByID)) {
+		if _, ok := newByID[id]; !ok {
+			changes.Deleted = append(changes.Deleted, oldByID[id])
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(newByID)) {
+		newObj := newByID[id]
+		oldObj, ok := oldByID[id]
+		if !ok {
+			changes.Created = append(changes.Created, newObj)
+			continue
+		}
+
+		attrs := map[string]AttributeChange{}
+		for key := range attributeKeys(oldObj, newObj) {
+			if !sameValue(oldObj[key], newObj[key]) {
+				attrs[key] = AttributeChange{Old: oldObj[key], New: newObj[key]}
+			}
+		}
+		if len(attrs) > 0 {
+			hostname, _ := newObj["hostname"].(string)
+			changes.Changed = append(changes.Changed, ObjectChanges{ObjectID: id, Hostname: hostname, Attributes: attrs})
+		}
+	}
+
+	return changes
+}
+
+// attributesObjectID returns the object_id of a raw attribute map, or 0.
+func attributesObjectID(attrs Attributes) int {
+	obj := ServerObject{attributes: attrs}
+	return obj.ObjectID()
+}
+
+// objectsByID indexes raw attribute maps by object_id, skipping objects
+// without one so they don't overwrite each other under id 0.
+func objectsByID(objects []Attributes) map[int]Attributes {
+	byID := make(map[int]Attributes, len(objects))
+	for _, obj := range objects {
+		if id := attributesObjectID(obj); id != 0 {
+			byID[id] = obj
+		}
+	}
+	return byID
+}
+
+// attributeKeys returns the union of the keys of a and b.
+func attributeKeys(a, b Attributes) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a))
+	for key := range a {
+		keys[key] = struct{}{}
+	}
+	for key := range b {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
+
+// sameValue compares two attribute values by their JSON encoding, treating
+// slices as unordered sets as Serveradmin does for multi attributes.
+func sameValue(a, b any) bool {
+	aSlice, bSlice := toAnySlice(a), toAnySlice(b)
+	if aSlice == nil || bSlice == nil {
+		return jsonEqual(a, b)
+	}
+	return slices.Equal(sortedJSON(aSlice), sortedJSON(bSlice))
+}
+
+func sortedJSON(values []any) []string {
+	encoded := make([]string, len(values))
+	for i, v := range values {
+		b, _ := json.Marshal(v)
+		encoded[i] = string(b)
+	}
+	slices.Sort(encoded)
+	return encoded
+}
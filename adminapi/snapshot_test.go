@@ -0,0 +1,90 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuerySnapshot(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[
+			{"object_id":2,"hostname":"b.local"},
+			{"object_id":1,"hostname":"a.local"}]}`))
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q := client.NewQuery(Filters{"hostname": Regexp(".*")})
+	snapshot, err := q.Snapshot(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, snapshot.Objects, 2)
+	assert.Equal(t, "a.local", snapshot.Objects[0]["hostname"], "objects are ordered by object_id")
+	assert.WithinDuration(t, time.Now(), snapshot.TakenAt, time.Minute)
+
+	// a snapshot survives a JSON round trip unchanged
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var decoded Snapshot
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Empty(t, DiffSnapshots(snapshot, &decoded).Changed)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	older := &Snapshot{Objects: []Attributes{
+		{"object_id": float64(1), "hostname": "a.local", "state": "online", "tags": []any{"x", "y"}},
+		{"object_id": float64(2), "hostname": "b.local", "state": "online"},
+		{"object_id": float64(3), "hostname": "c.local", "state": "online", "tags": []any{"x", "y"}},
+	}}
+	newer := &Snapshot{Objects: []Attributes{
+		{"object_id": float64(1), "hostname": "a.local", "state": "online", "tags": []any{"y", "x"}},
+		{"object_id": float64(3), "hostname": "c.local", "state": "retired", "tags": []any{"x"}},
+		{"object_id": float64(4), "hostname": "d.local", "state": "online"},
+	}}
+
+	changes := DiffSnapshots(older, newer)
+
+	require.Len(t, changes.Created, 1)
+	assert.Equal(t, "d.local", changes.Created[0]["hostname"])
+	require.Len(t, changes.Deleted, 1)
+	assert.Equal(t, "b.local", changes.Deleted[0]["hostname"])
+
+	require.Len(t, changes.Changed, 1, "reordered multi attribute values are no change")
+	assert.Equal(t, ObjectChanges{
+		ObjectID: 3,
+		Hostname: "c.local",
+		Attributes: map[string]AttributeChange{
+			"state": {Old: "online", New: "retired"},
+			"tags":  {Old: []any{"x", "y"}, New: []any{"x"}},
+		},
+	}, changes.Changed[0])
+	assert.False(t, changes.Empty())
+
+	assert.True(t, DiffSnapshots(newer, newer).Empty())
+}
+
+func TestDiffSnapshotsSkipsObjectsWithoutID(t *testing.T) {
+	older := &Snapshot{Objects: []Attributes{
+		{"hostname": "a.local"},
+		{"hostname": "b.local"},
+	}}
+	newer := &Snapshot{Objects: []Attributes{
+		{"hostname": "c.local"},
+		{"object_id": float64(1), "hostname": "d.local"},
+	}}
+
+	changes := DiffSnapshots(older, newer)
+
+	assert.Empty(t, changes.Deleted)
+	assert.Empty(t, changes.Changed)
+	require.Len(t, changes.Created, 1)
+	assert.Equal(t, "d.local", changes.Created[0]["hostname"])
+}
@@ -0,0 +1,83 @@
+package adminapi
+
+import (
+	"fmt"
+	"maps"
+)
+
+// SoftDelete describes an instance's convention for retiring objects instead
+// of deleting them, e.g. setting "state" to "retired". When configured, queries
+// exclude retired objects unless Query.IncludeRetired is called.
+type SoftDelete struct {
+	// Attribute marks an object as retired, e.g. "state".
+	Attribute string
+
+	// RetiredValue is the value of Attribute for retired objects, e.g. "retired".
+	RetiredValue string
+
+	// ActiveValue is the value Unretire restores, e.g. "online". Unretire
+	// fails when it is empty.
+	ActiveValue string
+}
+
+// IncludeRetired makes the query also return retired objects. It has no
+// effect when the client has no SoftDelete convention configured.
+func (q *Query) IncludeRetired() {
+	q.includeRetired = true
+}
+
+// requestFilters returns the filters sent to the server, excluding retired
+// objects unless they were asked for. An explicit filter on the soft-delete
+// attribute is left untouched, so "state=retired" still finds retired objects.
+func (q *Query) requestFilters(client *Client) Filters {
+	sd := client.softDelete
+	if sd == nil || q.includeRetired {
+		return q.filters
+	}
+	if _, ok := q.filters[sd.Attribute]; ok {
+		return q.filters
+	}
+
+	filters := make(Filters, len(q.filters)+1)
+	maps.Copy(filters, q.filters)
+	filters[sd.Attribute] = Not(sd.RetiredValue)
+	return filters
+}
+
+// Retire marks the object as retired according to the client's SoftDelete
+// convention. Unlike Delete, the object is kept on the server; the change is
+// applied on the next Commit.
+func (s *ServerObject) Retire() error {
+	sd, err := s.softDelete()
+	if err != nil {
+		return err
+	}
+	return s.Set(sd.Attribute, sd.RetiredValue)
+}
+
+// Unretire sets the soft-delete attribute back to SoftDelete.ActiveValue. The
+// change is applied on the next Commit.
+func (s *ServerObject) Unretire() error {
+	sd, err := s.softDelete()
+	if err != nil {
+		return err
+	}
+	if sd.ActiveValue == "" {
+		return fmt.Errorf("unretire: %w: ActiveValue is not set", ErrSoftDeleteNotConfigured)
+	}
+	return s.Set(sd.Attribute, sd.ActiveValue)
+}
+
+// IsRetired reports whether the object is retired according to the client's
+// SoftDelete convention. It is always false without one.
+func (s *ServerObject) IsRetired() bool {
+	sd, err := s.softDelete()
+	return err == nil && s.GetString(sd.Attribute) == sd.RetiredValue
+}
+
+func (s *ServerObject) softDelete() (*SoftDelete, error) {
+	if s.client == nil || s.client.softDelete == nil {
+		return nil, ErrSoftDeleteNotConfigured
+	}
+	return s.client.softDelete, nil
+}
@@ -0,0 +1,87 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftDelete(t *testing.T) {
+	var gotFilters map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Filters map[string]any `json:"filters"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		gotFilters = req.Filters
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a.local","state":"online"}]}`))
+	}))
+	defer server.Close()
+
+	softDelete := &SoftDelete{Attribute: "state", RetiredValue: "retired", ActiveValue: "online"}
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", SoftDelete: softDelete})
+	require.NoError(t, err)
+
+	t.Run("retired objects are excluded by default", func(t *testing.T) {
+		filters := Filters{"hostname": "a.local"}
+		q := client.NewQuery(filters)
+		_, err := q.All(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{"hostname": "a.local", "state": map[string]any{"Not": "retired"}}, gotFilters)
+		assert.Len(t, filters, 1, "caller's filters must not be modified")
+	})
+
+	t.Run("IncludeRetired", func(t *testing.T) {
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		q.IncludeRetired()
+		_, err := q.All(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{"hostname": "a.local"}, gotFilters)
+	})
+
+	t.Run("explicit filter on the attribute wins", func(t *testing.T) {
+		q := client.NewQuery(Filters{"state": "retired"})
+		_, err := q.All(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]any{"state": "retired"}, gotFilters)
+	})
+
+	t.Run("Retire and Unretire", func(t *testing.T) {
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		obj, err := q.One(context.Background())
+		require.NoError(t, err)
+		assert.False(t, obj.IsRetired())
+
+		require.NoError(t, obj.Retire())
+		assert.True(t, obj.IsRetired())
+		assert.Equal(t, StateChanged, obj.CommitState())
+
+		require.NoError(t, obj.Unretire())
+		assert.Equal(t, "online", obj.GetString("state"))
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		plain := mustClient(t, server.URL)
+		q := plain.NewQuery(Filters{"hostname": "a.local"})
+		obj, err := q.One(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"hostname": "a.local"}, gotFilters)
+
+		require.ErrorIs(t, obj.Retire(), ErrSoftDeleteNotConfigured)
+		assert.False(t, obj.IsRetired())
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := NewClient(Config{BaseURL: server.URL, Token: "tok", SoftDelete: &SoftDelete{Attribute: "state"}})
+		require.Error(t, err)
+	})
+}
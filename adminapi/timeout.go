@@ -0,0 +1,56 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type queryTimeoutKey struct{}
+
+// WithTimeout limits how long the query may take, including retries and
+// reading the response, overriding the client's Config.Timeout for this query
+// only, e.g. to allow a single heavy query more time. If the query runs out of
+// time, the error wraps ErrQueryTimeout as well as ErrTimeout. Zero restores
+// the client's timeout.
+func (q *Query) WithTimeout(timeout time.Duration) {
+	q.timeout = timeout
+}
+
+// withQueryTimeout returns a context ending after the query's timeout, whose
+// requests are sent without the client's own timeout, and a function wrapping
+// errors caused by the timeout. Without a timeout ctx is returned unchanged.
+func (q *Query) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc, func(error) error) {
+	if q.timeout <= 0 {
+		return ctx, func() {}, func(err error) error { return err }
+	}
+
+	cause := fmt.Errorf("%w after %s", ErrQueryTimeout, q.timeout)
+	ctx, cancel := context.WithTimeoutCause(context.WithValue(ctx, queryTimeoutKey{}, true), q.timeout, cause)
+	wrap := func(err error) error {
+		if err == nil || !errors.Is(context.Cause(ctx), cause) {
+			return err
+		}
+		if !errors.Is(err, ErrQueryTimeout) {
+			err = fmt.Errorf("%w: %w", cause, err) // e.g. while reading the response
+		}
+		if !errors.Is(err, ErrTimeout) {
+			err = fmt.Errorf("%w: %w", ErrTimeout, err)
+		}
+		return err
+	}
+	return ctx, cancel, wrap
+}
+
+// httpClientFor returns the HTTP client to send requests with ctx: the
+// client's own one, or a copy without its timeout for queries with their own.
+func (c *Client) httpClientFor(ctx context.Context) *http.Client {
+	if ctx.Value(queryTimeoutKey{}) == nil || c.httpClient.Timeout == 0 {
+		return c.httpClient
+	}
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	return &httpClient
+}
@@ -0,0 +1,47 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryWithTimeout(t *testing.T) {
+	delay := 100 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", Timeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	q := client.NewQuery(Filters{"hostname": "web1"})
+	_, err = q.All(ctx)
+	require.ErrorIs(t, err, ErrTimeout, "the client's timeout applies by default")
+	require.NotErrorIs(t, err, ErrQueryTimeout)
+
+	q.WithTimeout(time.Second)
+	objects, err := q.All(ctx)
+	require.NoError(t, err, "the query's timeout overrides the client's")
+	assert.Len(t, objects, 1)
+
+	short := client.NewQuery(Filters{"hostname": "web1"})
+	short.WithTimeout(10 * time.Millisecond)
+	_, err = short.Count(ctx)
+	require.ErrorIs(t, err, ErrQueryTimeout, "derived queries keep the timeout")
+	require.ErrorIs(t, err, ErrTimeout)
+	assert.Contains(t, err.Error(), "after 10ms")
+}
@@ -0,0 +1,129 @@
+package adminapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenProvider(t *testing.T) {
+	var gotAppIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAppIDs = append(gotAppIDs, r.Header.Get("X-Application"))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[{"object_id":1,"hostname":"a.local"}]}`))
+	}))
+	defer server.Close()
+
+	t.Run("token is fetched for every request", func(t *testing.T) {
+		gotAppIDs = nil
+		var calls int
+		provider := TokenProviderFunc(func(context.Context) ([]byte, error) {
+			calls++
+			return []byte("token-" + strconv.Itoa(calls)), nil
+		})
+
+		client, err := NewClient(Config{BaseURL: server.URL, Token: "ignored", TokenProvider: provider})
+		require.NoError(t, err)
+
+		for range 2 {
+			q := client.NewQuery(Filters{"hostname": "a.local"})
+			_, err = q.All(context.Background())
+			require.NoError(t, err)
+		}
+
+		assert.Equal(t, []string{calcAppID([]byte("token-1")), calcAppID([]byte("token-2"))}, gotAppIDs)
+	})
+
+	t.Run("provider error aborts the request", func(t *testing.T) {
+		gotAppIDs = nil
+		errVault := errors.New("vault sealed")
+		provider := TokenProviderFunc(func(context.Context) ([]byte, error) {
+			return nil, errVault
+		})
+
+		client, err := NewClient(Config{BaseURL: server.URL, TokenProvider: provider})
+		require.NoError(t, err)
+
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err = q.All(context.Background())
+		require.ErrorIs(t, err, errVault)
+		assert.Empty(t, gotAppIDs, "no request must be sent without a token")
+	})
+}
+
+func TestTokenAlgorithmNegotiation(t *testing.T) {
+	var gotTokens, gotAlgorithms []string
+	advertise := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTokens = append(gotTokens, r.Header.Get("X-SecurityToken"))
+		gotAlgorithms = append(gotAlgorithms, r.Header.Get("X-SecurityToken-Algorithm"))
+		if advertise != "" {
+			w.Header().Set(tokenAlgorithmsHeader, advertise)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	query := func(client *Client) {
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err := q.All(context.Background())
+		require.NoError(t, err)
+	}
+
+	t.Run("auto switches after the server advertises sha256", func(t *testing.T) {
+		gotTokens, gotAlgorithms = nil, nil
+		advertise = "sha1, sha256"
+		client := mustClient(t, server.URL)
+
+		query(client)
+		query(client)
+
+		assert.Len(t, gotTokens[0], 40, "first request uses SHA-1")
+		assert.Empty(t, gotAlgorithms[0])
+		assert.Len(t, gotTokens[1], 64, "second request uses SHA-256")
+		assert.Equal(t, "sha256", gotAlgorithms[1])
+	})
+
+	t.Run("auto stays on sha1 without the header", func(t *testing.T) {
+		gotTokens, gotAlgorithms = nil, nil
+		advertise = ""
+		client := mustClient(t, server.URL)
+
+		query(client)
+		query(client)
+
+		assert.Len(t, gotTokens[1], 40)
+	})
+
+	t.Run("explicit sha1 ignores the server", func(t *testing.T) {
+		gotTokens, gotAlgorithms = nil, nil
+		advertise = "sha256"
+		client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", TokenAlgorithm: TokenAlgorithmSHA1})
+		require.NoError(t, err)
+
+		query(client)
+		query(client)
+
+		assert.Len(t, gotTokens[1], 40)
+	})
+
+	t.Run("explicit sha256", func(t *testing.T) {
+		gotTokens, gotAlgorithms = nil, nil
+		advertise = ""
+		client, err := NewClient(Config{BaseURL: server.URL, Token: "tok", TokenAlgorithm: TokenAlgorithmSHA256})
+		require.NoError(t, err)
+
+		query(client)
+
+		assert.Len(t, gotTokens[0], 64)
+		assert.Equal(t, "sha256", gotAlgorithms[0])
+	})
+}
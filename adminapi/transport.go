@@ -6,13 +6,16 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha1" //nolint:gosec // SHA1 is required by the protocol
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -29,45 +32,151 @@ func (c *Client) sendRequest(ctx context.Context, endpoint string, postData any)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request data: %w", err)
 	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+endpoint, bytes.NewBuffer(postStr))
+
+	compressed, err := c.compressBody(postStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress request data: %w", err)
+	}
+
+	ctx, _ = ensureRequestID(ctx)
+	ctx = ensureIdempotencyKey(ctx, endpoint)
+
+	attempts := c.retry.attempts(endpoint)
+	rateLimited := 0
+	skewAdjusted := false
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		trial, err := c.breaker.allow()
+		if err != nil {
+			if lastErr != nil {
+				// the circuit opened during our own retries: report why
+				return nil, lastErr
+			}
+			return nil, err
+		}
+		if err := c.limits.throttle(ctx); err != nil {
+			c.breaker.record(trial, err)
+			return nil, err
+		}
+		start := time.Now()
+		resp, err := c.send(ctx, endpoint, postStr, compressed)
+		lastErr = err
+		c.logRequest(ctx, endpoint, len(postStr), attempt, time.Since(start), resp, err)
+		c.breaker.record(trial, err)
+		if wait, ok := c.retry.rateLimitWait(err, attempt); ok {
+			rateLimited++
+			if rateLimited > maxRateLimitRetries || wait > maxRetryAfter {
+				return nil, fmt.Errorf("%w: %w", ErrRateLimited, err)
+			}
+			c.limits.pause(wait)
+			if waitErr := sleepCtx(ctx, wait); waitErr != nil {
+				return nil, fmt.Errorf("%w: %w", ErrRateLimited, err)
+			}
+			// the request was not processed: this attempt doesn't count
+			attempts++
+			continue
+		}
+		if !skewAdjusted && c.clock.adjust(err) {
+			// rejected for its timestamp: repeat it once with the server's time
+			skewAdjusted = true
+			attempts++
+			continue
+		}
+		if attempt >= attempts || !c.retry.retryable(ctx, err) {
+			return resp, err
+		}
+		if waitErr := sleepCtx(ctx, c.retry.backoff(attempt)); waitErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends the request once, to the client's base URL or, with
+// Config.Endpoints, to the first endpoint that can be reached. Failing over is
+// safe for all requests, as an unreachable endpoint never received them.
+func (c *Client) send(ctx context.Context, endpoint string, postStr, compressed []byte) (*http.Response, error) {
+	if c.endpoints == nil {
+		return c.doRequest(ctx, c.baseURL, endpoint, postStr, compressed)
+	}
+
+	var err error
+	for _, i := range c.endpoints.order() {
+		var resp *http.Response
+		resp, err = c.doRequest(ctx, c.endpoints.urls[i], endpoint, postStr, compressed)
+		c.endpoints.record(i, err)
+		if !errors.Is(err, ErrUnreachable) || ctx.Err() != nil {
+			return resp, err
+		}
+	}
+	return nil, err
+}
+
+// doRequest sends a single, freshly signed request to baseURL. If compressed is not nil,
+// it is sent gzip-encoded in place of postStr; the signature always covers the
+// uncompressed postStr, which is what the server verifies after decoding.
+func (c *Client) doRequest(ctx context.Context, baseURL, endpoint string, postStr, compressed []byte) (*http.Response, error) {
+	body := postStr
+	if compressed != nil {
+		body = compressed
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+endpoint, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	now := time.Now().Unix()
 	req.Header.Set("Content-Type", "application/x-json")
-	req.Header.Set("X-Timestamp", strconv.FormatInt(now, 10))
+	if compressed != nil {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.Header.Set("X-Timestamp", strconv.FormatInt(c.clock.timestamp(), 10))
 	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(requestIDHeader, RequestIDFromContext(ctx))
+	if key := idempotencyKeyFromContext(ctx); key != "" && endpoint == apiEndpointCommit {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	if c.provenance != nil && endpoint == apiEndpointCommit {
+		c.provenance.setHeaders(req.Header)
+	}
 
-	if c.sshSigner != nil {
-		// sign with private key or SSH agent
-		messageToSign := calcMessage(now, postStr)
-		signature, sigErr := c.sshSigner.Sign(rand.Reader, messageToSign)
-		if sigErr != nil {
-			return nil, fmt.Errorf("failed to sign request: %w", sigErr)
-		}
-		publicKey := base64.StdEncoding.EncodeToString(c.sshSigner.PublicKey().Marshal())
-		sshSignature := base64.StdEncoding.EncodeToString(ssh.Marshal(signature))
+	if err := c.auth.Apply(req, postStr); err != nil {
+		return nil, err
+	}
 
-		req.Header.Set("X-PublicKeys", publicKey)
-		req.Header.Set("X-Signatures", sshSignature)
-	} else if len(c.authToken) > 0 {
-		req.Header.Set("X-SecurityToken", calcSecurityToken(c.authToken, now, postStr))
-		req.Header.Set("X-Application", calcAppID(c.authToken))
+	var etags *etagCache
+	if endpoint == apiEndpointQuery {
+		etags = c.etags
 	}
+	etags.prepare(req, postStr)
 
-	resp, err := c.httpClient.Do(req)
+	c.debug.dumpRequest(req, postStr)
+	start := time.Now()
+	resp, err := c.httpClientFor(ctx).Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("sending request to %s: %w", endpoint, err)
+		c.debug.dumpError(endpoint, err)
+		if kind := classifyNetworkError(err); kind != nil {
+			err = fmt.Errorf("%w: %w", kind, err)
+		}
+		return nil, &sendError{fmt.Errorf("sending request to %s (request id %s): %w", endpoint, RequestIDFromContext(ctx), err)}
+	}
+	c.debug.dumpResponse(resp, endpoint, time.Since(start))
+	resp = etags.response(resp, postStr)
+	c.limits.update(resp.Header)
+	c.clock.observe(resp.Header)
+	if observer, ok := c.auth.(responseObserver); ok {
+		observer.observeResponse(resp)
 	}
 
 	// special error handling
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		defer resp.Body.Close()
+		defer closeBody(resp.Body)
 
 		apiErr := &APIError{
 			StatusCode: resp.StatusCode,
 			Status:     http.StatusText(resp.StatusCode),
+			RequestID:  RequestIDFromContext(ctx),
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), c.limits.now())
 		}
 
 		bodyBytes, readErr := io.ReadAll(resp.Body)
@@ -90,6 +199,101 @@ func (c *Client) sendRequest(ctx context.Context, endpoint string, postData any)
 	return resp, nil
 }
 
+// maxDrainBytes bounds how much of an unread response body is discarded to
+// keep its connection; connections with larger remainders are closed instead.
+const maxDrainBytes = 64 << 10
+
+// closeBody discards what is left of a response body and closes it. HTTP/1.1
+// connections are only reused after their response was read to the end, which
+// a JSON decoder stopping at the closing brace does not guarantee.
+func closeBody(body io.ReadCloser) {
+	_, _ = io.CopyN(io.Discard, body, maxDrainBytes)
+	_ = body.Close()
+}
+
+// signMessage signs message and returns the comma-separated, base64-encoded
+// public keys and signatures expected in the X-PublicKeys and X-Signatures
+// headers. Serveradmin accepts a request if it knows any one of the keys, so
+// signers that fail are skipped, and FIDO2 security keys, which need a touch
+// for every signature, are only used when no other key can sign. It fails
+// only if no signer produced a signature.
+func signMessage(ctx context.Context, signers []ssh.Signer, message []byte) (publicKeys, signatures string, err error) {
+	var keys, sigs []string
+	var errs []error
+	signWith := func(securityKeys bool) {
+		for _, signer := range signers {
+			if isSecurityKey(signer.PublicKey()) != securityKeys {
+				continue
+			}
+			signature, err := sign(ctx, signer, message)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to sign request with %s key: %w", signer.PublicKey().Type(), err))
+				continue
+			}
+			keys = append(keys, base64.StdEncoding.EncodeToString(signer.PublicKey().Marshal()))
+			sigs = append(sigs, base64.StdEncoding.EncodeToString(ssh.Marshal(signature)))
+		}
+	}
+
+	signWith(false)
+	if len(sigs) == 0 {
+		signWith(true)
+	}
+	if len(sigs) == 0 {
+		return "", "", errors.Join(errs...)
+	}
+	return strings.Join(keys, ","), strings.Join(sigs, ","), nil
+}
+
+// securityKeyTouchTimeout bounds how long signing with a FIDO2 security key
+// waits for the user to touch the token.
+const securityKeyTouchTimeout = 30 * time.Second
+
+// sign signs message with signer. Signing with a FIDO2 security key (sk-* key
+// types) blocks until the user touches the token, so it is bounded by ctx and
+// securityKeyTouchTimeout and fails with ErrSecurityKeyTouch if the touch is
+// not given in time. The returned signature keeps the authenticator flags and
+// counter in Signature.Rest, which ssh.Marshal includes as the server expects
+// for sk signatures.
+func sign(ctx context.Context, signer ssh.Signer, message []byte) (*ssh.Signature, error) {
+	if !isSecurityKey(signer.PublicKey()) {
+		return signer.Sign(rand.Reader, message)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, securityKeyTouchTimeout)
+	defer cancel()
+
+	type result struct {
+		signature *ssh.Signature
+		err       error
+	}
+	done := make(chan result, 1)
+	go func() {
+		signature, err := signer.Sign(rand.Reader, message)
+		done <- result{signature, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.signature, res.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %w", ErrSecurityKeyTouch, ctx.Err())
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// isSecurityKey reports whether key is backed by a FIDO2 security key.
+func isSecurityKey(key ssh.PublicKey) bool {
+	switch key.Type() {
+	case ssh.KeyAlgoSKED25519, ssh.KeyAlgoSKECDSA256, ssh.CertAlgoSKED25519v01, ssh.CertAlgoSKECDSA256v01:
+		return true
+	default:
+		return false
+	}
+}
+
 // calcSecurityToken calculates HMAC-SHA1 of timestamp:data
 func calcSecurityToken(authToken []byte, timestamp int64, data []byte) string {
 	mac := hmac.New(sha1.New, authToken)
@@ -98,6 +302,14 @@ func calcSecurityToken(authToken []byte, timestamp int64, data []byte) string {
 	return hex.EncodeToString(mac.Sum(nil))
 }
 
+// calcSecurityTokenSHA256 calculates HMAC-SHA256 of timestamp:data
+func calcSecurityTokenSHA256(authToken []byte, timestamp int64, data []byte) string {
+	mac := hmac.New(sha256.New, authToken)
+	mac.Write(calcMessage(timestamp, data))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // calcMessage efficiently concatenates timestamp:data without redundant allocations
 func calcMessage(timestamp int64, data []byte) []byte {
 	return append(append(strconv.AppendInt(nil, timestamp, 10), ':'), data...)
@@ -2,13 +2,19 @@ package adminapi
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
 )
 
 func TestFakeServer(t *testing.T) {
@@ -113,6 +119,140 @@ func TestHTTPErrorHandling(t *testing.T) {
 	}
 }
 
+// securityKeySigner mimics an SSH agent signer for an sk-ssh-ed25519 key: it
+// blocks until touched and returns signatures carrying flags and a counter.
+type securityKeySigner struct {
+	publicKey ssh.PublicKey
+	touch     chan struct{}
+	err       error
+}
+
+func newSecurityKeySigner(t *testing.T) *securityKeySigner {
+	t.Helper()
+	keyBlob := ssh.Marshal(struct {
+		Name        string
+		KeyBytes    []byte
+		Application string
+	}{ssh.KeyAlgoSKED25519, make([]byte, 32), "ssh:"})
+	publicKey, err := ssh.ParsePublicKey(keyBlob)
+	require.NoError(t, err)
+	return &securityKeySigner{publicKey: publicKey, touch: make(chan struct{}, 1)}
+}
+
+func (s *securityKeySigner) PublicKey() ssh.PublicKey {
+	return s.publicKey
+}
+
+func (s *securityKeySigner) Sign(_ io.Reader, _ []byte) (*ssh.Signature, error) {
+	<-s.touch
+	if s.err != nil {
+		return nil, s.err
+	}
+	// flags: user presence; counter: 7
+	return &ssh.Signature{Format: ssh.KeyAlgoSKED25519, Blob: []byte("sig"), Rest: []byte{0x01, 0, 0, 0, 7}}, nil
+}
+
+func TestSecurityKeySigning(t *testing.T) {
+	var gotSignatures string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignatures = r.Header.Get("X-Signatures")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"success","result":[]}`))
+	}))
+	defer server.Close()
+
+	t.Run("touched", func(t *testing.T) {
+		signer := newSecurityKeySigner(t)
+		signer.touch <- struct{}{}
+		client, err := NewClient(Config{BaseURL: server.URL, SSHSigner: signer})
+		require.NoError(t, err)
+
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err = q.All(context.Background())
+		require.NoError(t, err)
+
+		// The flags and counter must reach the server along with the signature.
+		sigBytes, err := base64.StdEncoding.DecodeString(gotSignatures)
+		require.NoError(t, err)
+		var signature ssh.Signature
+		require.NoError(t, ssh.Unmarshal(sigBytes, &signature))
+		assert.Equal(t, ssh.KeyAlgoSKED25519, signature.Format)
+		assert.Equal(t, []byte{0x01, 0, 0, 0, 7}, signature.Rest)
+	})
+
+	t.Run("touch timeout", func(t *testing.T) {
+		signer := newSecurityKeySigner(t)
+		client, err := NewClient(Config{BaseURL: server.URL, SSHSigner: signer})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err = q.All(ctx)
+		require.ErrorIs(t, err, ErrSecurityKeyTouch)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		signer.touch <- struct{}{} // release the abandoned signing goroutine
+	})
+
+	t.Run("agent failure", func(t *testing.T) {
+		signer := newSecurityKeySigner(t)
+		signer.err = errors.New("agent: failed to sign challenge")
+		signer.touch <- struct{}{}
+		client, err := NewClient(Config{BaseURL: server.URL, SSHSigner: signer})
+		require.NoError(t, err)
+
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err = q.All(context.Background())
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrSecurityKeyTouch, "only timeouts are touch errors")
+		assert.Contains(t, err.Error(), "failed to sign challenge")
+	})
+
+	t.Run("other keys first", func(t *testing.T) {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		plain, err := ssh.NewSignerFromKey(privateKey)
+		require.NoError(t, err)
+		untouched := newSecurityKeySigner(t)
+
+		client, err := NewClient(Config{BaseURL: server.URL, SSHSigners: []ssh.Signer{untouched, plain}})
+		require.NoError(t, err)
+
+		q := client.NewQuery(Filters{"hostname": "a.local"})
+		_, err = q.All(context.Background())
+		require.NoError(t, err, "the security key is not asked for a touch")
+		assert.NotContains(t, gotSignatures, ",")
+	})
+
+	t.Run("failing signers are skipped", func(t *testing.T) {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		plain, err := ssh.NewSignerFromKey(privateKey)
+		require.NoError(t, err)
+		broken := newSecurityKeySigner(t)
+		broken.err = errors.New("agent: failed to sign challenge")
+		broken.touch <- struct{}{}
+
+		publicKeys, signatures, err := signMessage(context.Background(), []ssh.Signer{plain, failingSigner{plain}}, []byte("msg"))
+		require.NoError(t, err)
+		assert.NotContains(t, publicKeys, ",")
+		assert.NotContains(t, signatures, ",")
+
+		_, _, err = signMessage(context.Background(), []ssh.Signer{broken}, []byte("msg"))
+		require.ErrorContains(t, err, "failed to sign challenge")
+	})
+}
+
+// failingSigner is an ordinary signer whose agent refuses to sign.
+type failingSigner struct {
+	ssh.Signer
+}
+
+func (failingSigner) Sign(io.Reader, []byte) (*ssh.Signature, error) {
+	return nil, errors.New("agent refused operation")
+}
+
 // just some simple example tests, e2e tests might make much more sense here for full coverage
 func TestAppId(t *testing.T) {
 	testCases := []struct {
@@ -156,6 +296,16 @@ func TestSecurityToken(t *testing.T) {
 	}
 }
 
+func TestSecurityTokenSHA256(t *testing.T) {
+	now := int64(123456789)
+	assert.Equal(t,
+		"c8077c49de8ba1a9bc1c1909f250150ddf6c56f7c3f5bfbcc4ea34549e0f4df5",
+		calcSecurityTokenSHA256([]byte("1234567898"), now, []byte("")))
+	assert.Equal(t,
+		"d1c28fa63b7fcaac7acd708a0fa9dbee7eb2ba50a800abe28cf5958bad4e2036",
+		calcSecurityTokenSHA256([]byte("1234567898"), now, []byte("foobar")))
+}
+
 func BenchmarkCalcSecurityToken(b *testing.B) {
 	now := int64(123456789)
 	message := []byte("foobar")
@@ -0,0 +1,159 @@
+package adminapi
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// NewUnionQuery initializes a new query bound to this client, matching the
+// objects that match any of the alternatives. Alternatives differing in only
+// one attribute are combined into an Any filter on it; the others are sent as
+// separate requests, whose results are merged by object_id. Ordering, Limit
+// and Offset then apply to each request separately.
+func (c *Client) NewUnionQuery(alternatives ...Filters) Query {
+	alternatives = mergeAlternatives(alternatives)
+	if len(alternatives) == 1 {
+		return newQuery(c, alternatives[0])
+	}
+	q := newQuery(c, Filters{})
+	q.alternatives = alternatives
+	q.restrictedAttributes = defaultAttributes(alternatives...)
+	return q
+}
+
+// alternativeFilters returns the filters of each request the query is sent
+// as: its alternatives, each combined with the filters added with AddFilter,
+// or just the filters if it has no alternatives.
+func (q *Query) alternativeFilters() []Filters {
+	if len(q.alternatives) == 0 {
+		return []Filters{q.filters}
+	}
+	result := make([]Filters, len(q.alternatives))
+	for i, alternative := range q.alternatives {
+		filters := maps.Clone(alternative)
+		maps.Copy(filters, q.filters)
+		result[i] = filters
+	}
+	return result
+}
+
+// streamAlternatives runs the query once per alternative if it has several,
+// passing each matching object to fn only once. It reports false without
+// sending anything if the query has no alternatives.
+func (q *Query) streamAlternatives(ctx context.Context, fn func(*ServerObject) error) (bool, error) {
+	if len(q.alternatives) == 0 {
+		return false, nil
+	}
+
+	seen := make(map[any]bool)
+	for _, filters := range q.alternativeFilters() {
+		sub := q.derive()
+		sub.filters = filters
+		sub.alternatives = nil
+		err := sub.stream(ctx, func(obj *ServerObject) error {
+			if key := obj.setKey(); !seen[key] {
+				seen[key] = true
+				return fn(obj)
+			}
+			return nil
+		})
+		q.warnings = append(q.warnings, sub.warnings...)
+		if err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// unionGroup collects alternatives that differ only in the value of one
+// attribute.
+type unionGroup struct {
+	filters   Filters
+	attribute string // the attribute they differ in, "" while there is one alternative
+	values    []any  // the values of attribute
+}
+
+// mergeAlternatives combines alternatives that differ in only one attribute
+// into one with an Any filter on it and drops duplicates.
+func mergeAlternatives(alternatives []Filters) []Filters {
+	var groups []*unionGroup
+	for _, alternative := range alternatives {
+		if !slices.ContainsFunc(groups, func(g *unionGroup) bool { return g.add(alternative) }) {
+			groups = append(groups, &unionGroup{filters: alternative})
+		}
+	}
+
+	result := make([]Filters, len(groups))
+	for i, g := range groups {
+		result[i] = g.filters
+		if g.attribute != "" {
+			result[i] = maps.Clone(g.filters)
+			result[i][g.attribute] = anyFilter(g.values)
+		}
+	}
+	return result
+}
+
+// add adds alternative to the group if it differs from it in no attribute
+// but the group's one, or any one while the group has a single alternative.
+func (g *unionGroup) add(alternative Filters) bool {
+	if len(alternative) != len(g.filters) {
+		return false
+	}
+	var differing string
+	for attribute, value := range alternative {
+		existing, ok := g.filters[attribute]
+		if !ok {
+			return false
+		}
+		if attribute == g.attribute || reflect.DeepEqual(existing, value) {
+			continue
+		}
+		if differing != "" {
+			return false
+		}
+		differing = attribute
+	}
+
+	switch {
+	case differing != "" && g.attribute != "":
+		return false
+	case differing != "":
+		g.attribute = differing
+		g.values = anyValues(g.filters[differing])
+		fallthrough
+	case g.attribute != "":
+		for _, value := range anyValues(alternative[g.attribute]) {
+			if !slices.ContainsFunc(g.values, func(v any) bool { return reflect.DeepEqual(v, value) }) {
+				g.values = append(g.values, value)
+			}
+		}
+	}
+	return true
+}
+
+// anyFilter returns an Any filter on values, or the value if there is only
+// one.
+func anyFilter(values []any) any {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return createFilter("Any", values)
+}
+
+// anyValues returns the values an Any filter on value has to match: the
+// arguments if it is an Any filter itself, or value.
+func anyValues(value any) []any {
+	if filter, ok := value.(Filter); ok && len(filter) == 1 {
+		switch args := filter["Any"].(type) {
+		case []any:
+			return slices.Clone(args)
+		case nil:
+		default:
+			return []any{args}
+		}
+	}
+	return []any{value}
+}
@@ -0,0 +1,149 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUnion(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []Filters
+	}{
+		{
+			name:  "single alternative",
+			query: "env=staging state=online",
+			want:  []Filters{{"env": "staging", "state": "online"}},
+		},
+		{
+			name:  "alternatives differing in one attribute",
+			query: "env=staging state=online; env=dev state=online",
+			want:  []Filters{{"env": Filter{"Any": []any{"staging", "dev"}}, "state": "online"}},
+		},
+		{
+			name:  "or keyword",
+			query: "env=staging or env=dev OR env=any(dev test)",
+			want:  []Filters{{"env": Filter{"Any": []any{"staging", "dev", "test"}}}},
+		},
+		{
+			name:  "duplicate alternatives",
+			query: "env=staging;env=staging",
+			want:  []Filters{{"env": "staging"}},
+		},
+		{
+			name:  "alternatives differing in several attributes",
+			query: "env=staging; env=dev state=online; env=prod state=online",
+			want: []Filters{
+				{"env": "staging"},
+				{"env": Filter{"Any": []any{"dev", "prod"}}, "state": "online"},
+			},
+		},
+		{
+			name:  "semicolons in values",
+			query: `description="a;b" comment=c\;d`,
+			want:  []Filters{{"description": "a;b", "comment": "c;d"}},
+		},
+		{
+			name:  "hostname shorthands",
+			query: "web01 or web02; db01 state=online",
+			want: []Filters{
+				{"hostname": Filter{"Any": []any{"web01", "web02"}}},
+				{"hostname": "db01", "state": "online"},
+			},
+		},
+		{
+			name:  "or as attribute and value",
+			query: "or=or",
+			want:  []Filters{{"or": "or"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUnion(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseUnionError(t *testing.T) {
+	for query, offset := range map[string]int{
+		"; env=dev":         0,
+		"env=staging;;":     12,
+		"env=staging or":    14,
+		"env=any(a;b)":      9,
+		"env=staging or ; ": 15,
+	} {
+		_, err := ParseUnion(query)
+		var parseErr *ParseError
+		require.ErrorAs(t, err, &parseErr, query)
+		assert.Equal(t, offset, parseErr.Offset, query)
+	}
+}
+
+func TestParseQueryUnion(t *testing.T) {
+	filters, err := ParseQuery("env=staging; env=dev")
+	require.NoError(t, err)
+	assert.Equal(t, Filters{"env": Filter{"Any": []any{"staging", "dev"}}}, filters)
+
+	_, err = ParseQuery("env=staging; state=online")
+	require.ErrorIs(t, err, ErrQueryUnion)
+}
+
+func TestUnionQuery(t *testing.T) {
+	objects := []Attributes{
+		{"object_id": 1, "hostname": "web1", "env": "staging", "state": "online"},
+		{"object_id": 2, "hostname": "web2", "env": "dev", "state": "online"},
+		{"object_id": 3, "hostname": "web3", "env": "dev", "state": "offline"},
+	}
+	var requests []Filters
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req queryRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req.Filters)
+
+		result := []Attributes{}
+		for _, object := range objects {
+			if (req.Filters["env"] == nil || req.Filters["env"] == object["env"]) &&
+				(req.Filters["state"] == nil || req.Filters["state"] == object["state"]) {
+				result = append(result, object)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "result": result})
+	}))
+	defer server.Close()
+
+	client := mustClient(t, server.URL)
+	q, err := client.FromQuery("env=staging; state=online")
+	require.NoError(t, err)
+	q.AddFilter("hostname", Regexp("web.*"))
+	assert.Equal(t, "env=staging hostname=regexp(web.*); hostname=regexp(web.*) state=online", q.QueryString())
+
+	found, err := q.All(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, requests, 2, "one request per alternative")
+	assert.Equal(t, []string{"web1", "web2"}, hostnames(found), "objects matching both alternatives are returned once")
+
+	count, err := q.Count(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	q = client.NewUnionQuery(Filters{"env": "staging"}, Filters{"env": "dev"})
+	assert.Equal(t, "env=any(staging dev)", q.QueryString(), "alternatives differing in one attribute are combined")
+}
+
+// hostnames returns the hostnames of objects.
+func hostnames(objects ServerObjects) []string {
+	names := make([]string, len(objects))
+	for i, obj := range objects {
+		names[i] = obj.GetString("hostname")
+	}
+	return names
+}
@@ -0,0 +1,144 @@
+package adminapi
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// Validator checks a value before Set records it, returning an error to
+// reject it. It also runs for the helpers built on Set, like AddToMulti and
+// Unset.
+type Validator func(attribute string, value any) error
+
+// SetValidator makes Set check values with validate before recording them,
+// so bad values are rejected locally with a precise error instead of failing
+// the whole commit later. A nil validator removes the check.
+//
+//	attributes, _ := client.FetchAttributes(ctx)
+//	obj.SetValidator(adminapi.SchemaValidator(attributes))
+func (s *ServerObject) SetValidator(validate Validator) {
+	s.validator = validate
+}
+
+// SetValidator sets the validator of all objects, see
+// ServerObject.SetValidator.
+func (s ServerObjects) SetValidator(validate Validator) {
+	for _, obj := range s {
+		obj.SetValidator(validate)
+	}
+}
+
+// SchemaValidator returns a Validator checking values against the attribute
+// definitions returned by Client.FetchAttributes: read-only and reverse
+// attributes can't be set, multi attributes take lists and others single
+// values, values must match the attribute type and, for strings, the
+// attribute's regexp. Errors wrap ErrInvalidValue. Attributes missing from
+// attributes are not checked.
+func SchemaValidator(attributes []Attribute) Validator {
+	schema := make(map[string]Attribute, len(attributes))
+	patterns := make(map[string]*regexp.Regexp)
+	for _, attribute := range attributes {
+		schema[attribute.AttributeID] = attribute
+		if attribute.Regexp == "" {
+			continue
+		}
+		// patterns Go can't compile are left to the server
+		if re, err := regexp.Compile(attribute.Regexp); err == nil {
+			patterns[attribute.AttributeID] = re
+		}
+	}
+
+	return func(name string, value any) error {
+		attribute, ok := schema[name]
+		if !ok {
+			return nil
+		}
+		if attribute.Readonly || attribute.ReversedAttribute != "" {
+			return fmt.Errorf("%w: attribute is read-only", ErrInvalidValue)
+		}
+		if value == nil {
+			return nil
+		}
+
+		values := []any{value}
+		isList := reflect.ValueOf(value).Kind() == reflect.Slice
+		switch {
+		case attribute.Multi && !isList:
+			return fmt.Errorf("%w: multi attribute needs a list, got %T", ErrInvalidValue, value)
+		case !attribute.Multi && isList:
+			return fmt.Errorf("%w: single-valued attribute got a list", ErrInvalidValue)
+		case isList:
+			values = toAnySlice(value)
+		}
+
+		for _, v := range values {
+			if !matchesAttributeType(attribute.Type, v) {
+				return fmt.Errorf("%w: %T %v is no %s", ErrInvalidValue, v, v, attribute.Type)
+			}
+			if str, ok := v.(string); ok && patterns[name] != nil && !patterns[name].MatchString(str) {
+				return fmt.Errorf("%w: %q does not match %s", ErrInvalidValue, str, attribute.Regexp)
+			}
+		}
+		return nil
+	}
+}
+
+// matchesAttributeType reports whether value can be stored in an attribute
+// of Serveradmin's type typ. Unknown types accept everything.
+func matchesAttributeType(typ string, value any) bool {
+	str, isString := value.(string)
+	switch typ {
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := exactFloat(value)
+		return ok || isNumber(value)
+	case "string", "relation", "domain":
+		return isString
+	case "inet":
+		switch value.(type) {
+		case netip.Addr, netip.Prefix:
+			return true
+		}
+		if _, err := netip.ParseAddr(str); isString && err == nil {
+			return true
+		}
+		_, err := netip.ParsePrefix(str)
+		return isString && err == nil
+	case "macaddr":
+		_, err := net.ParseMAC(str)
+		return isString && err == nil
+	case "date":
+		if _, ok := value.(time.Time); ok {
+			return true
+		}
+		_, err := time.Parse(time.DateOnly, str)
+		return isString && err == nil
+	case "datetime":
+		if _, ok := value.(time.Time); ok {
+			return true
+		}
+		_, err := parseTime(str)
+		return isString && err == nil
+	default:
+		return true
+	}
+}
+
+// isNumber reports whether value is of a numeric kind, including integers
+// too large to be represented exactly as float64.
+func isNumber(value any) bool {
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
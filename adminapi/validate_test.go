@@ -0,0 +1,107 @@
+package adminapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaValidator(t *testing.T) {
+	validate := SchemaValidator([]Attribute{
+		{AttributeID: "hostname", Type: "string", Regexp: `^[a-z0-9.-]+$`},
+		{AttributeID: "num_cpu", Type: "number"},
+		{AttributeID: "active", Type: "boolean"},
+		{AttributeID: "tags", Type: "string", Multi: true},
+		{AttributeID: "intern_ip", Type: "inet"},
+		{AttributeID: "mac", Type: "macaddr"},
+		{AttributeID: "last_verified", Type: "date"},
+		{AttributeID: "created", Type: "datetime"},
+		{AttributeID: "hypervisor", Type: "relation"},
+		{AttributeID: "object_id", Type: "number", Readonly: true},
+		{AttributeID: "vms", Type: "reverse", Multi: true, ReversedAttribute: "hypervisor"},
+		{AttributeID: "custom", Type: "string", Regexp: `(?P<broken`},
+	})
+
+	for _, tt := range []struct {
+		attribute string
+		value     any
+	}{
+		{"hostname", "web01.example.com"},
+		{"hostname", nil},
+		{"num_cpu", 4},
+		{"num_cpu", 4.5},
+		{"num_cpu", json.Number("8")},
+		{"active", false},
+		{"tags", []any{"web"}},
+		{"tags", MultiAttr{}},
+		{"intern_ip", "10.0.0.1"},
+		{"intern_ip", "10.0.0.0/16"},
+		{"intern_ip", netip.MustParseAddr("::1")},
+		{"mac", "00:11:22:33:44:55"},
+		{"last_verified", "2024-05-01"},
+		{"last_verified", time.Now()},
+		{"created", "2024-05-01T12:00:00+00:00"},
+		{"hypervisor", "hv01"},
+		{"custom", "anything"},
+		{"unknown", []int{1}},
+	} {
+		assert.NoError(t, validate(tt.attribute, tt.value), "%s=%v", tt.attribute, tt.value)
+	}
+
+	for _, tt := range []struct {
+		attribute string
+		value     any
+		message   string
+	}{
+		{"hostname", "Web 01", "does not match"},
+		{"hostname", 1, "is no string"},
+		{"num_cpu", "4", "is no number"},
+		{"active", "true", "is no boolean"},
+		{"tags", "web", "needs a list"},
+		{"tags", []any{"web", 1}, "is no string"},
+		{"hostname", []string{"a"}, "got a list"},
+		{"intern_ip", "10.0.0.300", "is no inet"},
+		{"mac", "00:11", "is no macaddr"},
+		{"last_verified", "2024-05-01T12:00:00Z", "is no date"},
+		{"created", "yesterday", "is no datetime"},
+		{"object_id", 1, "read-only"},
+		{"vms", []any{}, "read-only"},
+	} {
+		err := validate(tt.attribute, tt.value)
+		require.ErrorIs(t, err, ErrInvalidValue, "%s=%v", tt.attribute, tt.value)
+		assert.ErrorContains(t, err, tt.message)
+	}
+}
+
+func TestSetValidator(t *testing.T) {
+	obj := &ServerObject{
+		attributes: Attributes{"object_id": float64(1), "num_cpu": float64(4), "tags": []any{"web"}},
+		oldValues:  Attributes{},
+	}
+	ServerObjects{obj}.SetValidator(SchemaValidator([]Attribute{
+		{AttributeID: "num_cpu", Type: "number"},
+		{AttributeID: "tags", Type: "string", Multi: true},
+	}))
+
+	err := obj.Set("num_cpu", "eight")
+	require.ErrorIs(t, err, ErrInvalidValue)
+	assert.ErrorContains(t, err, `attribute "num_cpu"`)
+	assert.Equal(t, StateConsistent, obj.CommitState(), "rejected values are not recorded")
+
+	require.ErrorIs(t, obj.AddToMulti("tags", 1), ErrInvalidValue)
+	require.NoError(t, obj.AddToMulti("tags", "prod"))
+	require.NoError(t, obj.Unset("num_cpu"))
+	assert.NotNil(t, obj.Clone().validator)
+
+	custom := errors.New("frozen")
+	obj.SetValidator(func(string, any) error { return custom })
+	require.ErrorIs(t, obj.Set("num_cpu", 8), custom)
+
+	obj.SetValidator(nil)
+	require.NoError(t, obj.Set("num_cpu", "unchecked"))
+}
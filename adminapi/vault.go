@@ -0,0 +1,197 @@
+package adminapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultVaultRefresh is how long a secret without a lease is used before it
+// is read again.
+const defaultVaultRefresh = 5 * time.Minute
+
+// VaultConfig configures a VaultTokenProvider.
+type VaultConfig struct {
+	// Address is the Vault server URL. Defaults to VAULT_ADDR.
+	Address string
+
+	// Token authenticates against Vault. Defaults to VAULT_TOKEN.
+	Token string
+
+	// Path is the API path of the secret below /v1/, e.g.
+	// "secret/data/serveradmin" for a KV v2 or "kv/serveradmin" for a KV v1
+	// mount (required).
+	Path string
+
+	// Field is the key of the Serveradmin token within the secret. Defaults to
+	// "token".
+	Field string
+
+	// Refresh is how long a secret without a lease is cached before it is read
+	// again. Defaults to 5 minutes.
+	Refresh time.Duration
+
+	// HTTPClient is used for requests to Vault. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// VaultTokenProvider is a TokenProvider reading the Serveradmin token from a
+// HashiCorp Vault KV secret. The token is cached; renewable leases are renewed
+// once two thirds of their duration have passed, and the secret is read again
+// when a lease cannot be renewed or has no lease at all.
+type VaultTokenProvider struct {
+	cfg VaultConfig
+
+	mu        sync.Mutex
+	token     []byte
+	leaseID   string
+	renewable bool
+	refreshAt time.Time
+	now       func() time.Time
+}
+
+// NewVaultTokenProvider returns a TokenProvider for the secret described by cfg.
+// The secret is not read until the first request.
+func NewVaultTokenProvider(cfg VaultConfig) (*VaultTokenProvider, error) {
+	if cfg.Address == "" {
+		cfg.Address = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.Token == "" {
+		cfg.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if cfg.Field == "" {
+		cfg.Field = "token"
+	}
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = defaultVaultRefresh
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	switch {
+	case cfg.Address == "":
+		return nil, errors.New("vault: Address or VAULT_ADDR is required")
+	case cfg.Token == "":
+		return nil, errors.New("vault: Token or VAULT_TOKEN is required")
+	case cfg.Path == "":
+		return nil, errors.New("vault: Path is required")
+	}
+	cfg.Address = strings.TrimSuffix(cfg.Address, "/")
+	cfg.Path = strings.Trim(cfg.Path, "/")
+
+	return &VaultTokenProvider{cfg: cfg, now: time.Now}, nil
+}
+
+// vaultSecret is the relevant part of Vault's response to reading a secret or
+// renewing its lease.
+type vaultSecret struct {
+	LeaseID       string          `json:"lease_id"`
+	LeaseDuration int             `json:"lease_duration"`
+	Renewable     bool            `json:"renewable"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Token returns the cached token, renewing its lease or reading the secret
+// again when it is due.
+func (v *VaultTokenProvider) Token(ctx context.Context) ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.token != nil && v.now().Before(v.refreshAt) {
+		return v.token, nil
+	}
+
+	if v.token != nil && v.renewable {
+		secret, err := v.request(ctx, http.MethodPut, "sys/leases/renew", map[string]string{"lease_id": v.leaseID})
+		if err == nil && secret.LeaseDuration > 0 {
+			v.scheduleRefresh(secret)
+			return v.token, nil
+		}
+	}
+
+	secret, err := v.request(ctx, http.MethodGet, v.cfg.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := v.extractToken(secret.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	v.token = token
+	v.leaseID = secret.LeaseID
+	v.renewable = secret.Renewable && secret.LeaseID != ""
+	v.scheduleRefresh(secret)
+	return v.token, nil
+}
+
+// scheduleRefresh sets when the token is renewed or read again next.
+func (v *VaultTokenProvider) scheduleRefresh(secret *vaultSecret) {
+	refresh := v.cfg.Refresh
+	if secret.LeaseDuration > 0 {
+		refresh = time.Duration(secret.LeaseDuration) * time.Second * 2 / 3
+	}
+	v.refreshAt = v.now().Add(refresh)
+}
+
+// extractToken returns the configured field of a KV v1 or v2 secret.
+func (v *VaultTokenProvider) extractToken(data json.RawMessage) ([]byte, error) {
+	var secret map[string]any
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return nil, fmt.Errorf("vault: decoding secret %s: %w", v.cfg.Path, err)
+	}
+	// KV v2 nests the secret in data.data, next to data.metadata
+	if nested, ok := secret["data"].(map[string]any); ok {
+		if _, hasMetadata := secret["metadata"]; hasMetadata {
+			secret = nested
+		}
+	}
+
+	token, ok := secret[v.cfg.Field].(string)
+	if !ok || token == "" {
+		return nil, fmt.Errorf("vault: secret %s has no field %q", v.cfg.Path, v.cfg.Field)
+	}
+	return []byte(token), nil
+}
+
+// request sends a request to the Vault API and decodes the response.
+func (v *VaultTokenProvider) request(ctx context.Context, method, path string, body any) (*vaultSecret, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("vault: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.cfg.Address+"/v1/"+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := v.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %s %s: %w", method, path, err)
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("vault: %s %s: %s", method, path, resp.Status)
+	}
+
+	secret := &vaultSecret{}
+	if err := json.NewDecoder(resp.Body).Decode(secret); err != nil {
+		return nil, fmt.Errorf("vault: decoding response of %s: %w", path, err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,110 @@
+package adminapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultTokenProvider(t *testing.T) {
+	var reads, renewals int
+	renewFails := false
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		switch r.URL.Path {
+		case "/v1/secret/data/serveradmin":
+			reads++
+			_, _ = w.Write([]byte(`{"data":{"data":{"token":"sa-token"},"metadata":{"version":1}},"lease_duration":0}`))
+		case "/v1/kv/serveradmin":
+			reads++
+			_, _ = w.Write([]byte(`{"lease_id":"kv/serveradmin/abc","renewable":true,"lease_duration":60,"data":{"token":"kv1-token"}}`))
+		case "/v1/sys/leases/renew":
+			renewals++
+			var body map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "kv/serveradmin/abc", body["lease_id"])
+			if renewFails {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"lease_id":"kv/serveradmin/abc","renewable":true,"lease_duration":60}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	ctx := context.Background()
+
+	t.Run("KV v2 secret is cached for the refresh interval", func(t *testing.T) {
+		reads = 0
+		provider, err := NewVaultTokenProvider(VaultConfig{Address: vault.URL, Token: "vault-token", Path: "secret/data/serveradmin"})
+		require.NoError(t, err)
+		now := time.Now()
+		provider.now = func() time.Time { return now }
+
+		for range 2 {
+			token, err := provider.Token(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "sa-token", string(token))
+		}
+		assert.Equal(t, 1, reads)
+
+		now = now.Add(defaultVaultRefresh)
+		_, err = provider.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, reads)
+	})
+
+	t.Run("leases are renewed", func(t *testing.T) {
+		reads, renewals = 0, 0
+		renewFails = false
+		provider, err := NewVaultTokenProvider(VaultConfig{Address: vault.URL, Token: "vault-token", Path: "kv/serveradmin"})
+		require.NoError(t, err)
+		now := time.Now()
+		provider.now = func() time.Time { return now }
+
+		token, err := provider.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "kv1-token", string(token))
+
+		now = now.Add(40 * time.Second) // two thirds of the lease
+		_, err = provider.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, reads)
+		assert.Equal(t, 1, renewals)
+
+		now = now.Add(40 * time.Second)
+		renewFails = true
+		_, err = provider.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, reads, "secret is read again when renewal fails")
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		provider, err := NewVaultTokenProvider(VaultConfig{Address: vault.URL, Token: "vault-token", Path: "secret/data/serveradmin", Field: "nope"})
+		require.NoError(t, err)
+		_, err = provider.Token(ctx)
+		require.ErrorContains(t, err, `no field "nope"`)
+	})
+
+	t.Run("configuration from environment", func(t *testing.T) {
+		t.Setenv("VAULT_ADDR", "")
+		_, err := NewVaultTokenProvider(VaultConfig{Path: "secret/data/serveradmin"})
+		require.ErrorContains(t, err, "VAULT_ADDR")
+
+		t.Setenv("VAULT_ADDR", vault.URL)
+		t.Setenv("VAULT_TOKEN", "vault-token")
+		provider, err := NewVaultTokenProvider(VaultConfig{Path: "secret/data/serveradmin"})
+		require.NoError(t, err)
+		token, err := provider.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "sa-token", string(token))
+	})
+}
@@ -0,0 +1,49 @@
+package adminapi
+
+import "encoding/json"
+
+// Warnings returns the warnings of the query's last run, e.g. about
+// deprecated attributes or truncated results, or nil if the server sent none.
+// Results served from the query cache carry no warnings.
+func (q *Query) Warnings() []string {
+	return q.warnings
+}
+
+// parseWarnings reads the "warnings" field of a query response, a list of
+// messages or of objects with a "message", or a single message.
+func parseWarnings(raw json.RawMessage) []string {
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err != nil {
+		list = []json.RawMessage{raw}
+	}
+
+	var warnings []string
+	for _, item := range list {
+		var message string
+		if err := json.Unmarshal(item, &message); err != nil {
+			var object struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(item, &object); err != nil {
+				continue
+			}
+			message = object.Message
+		}
+		if message != "" {
+			warnings = append(warnings, message)
+		}
+	}
+	return warnings
+}
+
+// reportQueryWarnings passes the warnings of a query response to the
+// configured OnQueryWarning callback.
+func (c *Client) reportQueryWarnings(q *Query, warnings []string) {
+	if c.onQueryWarning == nil || len(warnings) == 0 {
+		return
+	}
+	query := q.QueryString()
+	for _, warning := range warnings {
+		c.onQueryWarning(query, warning)
+	}
+}
@@ -0,0 +1,63 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryWarnings(t *testing.T) {
+	body := `{"status":"success","result":[{"object_id":1}],"warnings":["attribute os is deprecated"]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var reported []string
+	client, err := NewClient(Config{
+		BaseURL: server.URL,
+		Token:   "tok",
+		OnQueryWarning: func(query, warning string) {
+			reported = append(reported, query+": "+warning)
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	q := client.NewQuery(Filters{"hostname": "web1"})
+	_, err = q.All(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"attribute os is deprecated"}, q.Warnings())
+	assert.Equal(t, []string{"hostname=web1: attribute os is deprecated"}, reported)
+
+	body = `{"status":"success","result":[{"object_id":1}]}`
+	count, err := q.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"attribute os is deprecated"}, q.Warnings(), "loaded queries are not sent again")
+
+	require.NoError(t, q.Refresh(ctx))
+	assert.Nil(t, q.Warnings())
+
+	body = `{"status":"success","result":[],"warnings":[{"message":"result truncated"}]}`
+	exists, err := q.Exists(ctx)
+	require.NoError(t, err)
+	assert.True(t, exists, "the loaded result is used")
+	other := client.NewQuery(Filters{"hostname": "web2"})
+	exists, err = other.Exists(ctx)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	assert.Equal(t, []string{"result truncated"}, other.Warnings())
+}
+
+func TestParseWarnings(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, parseWarnings([]byte(`["a", {"message": "b"}, 3, ""]`)))
+	assert.Equal(t, []string{"single"}, parseWarnings([]byte(`"single"`)))
+	assert.Nil(t, parseWarnings([]byte(`null`)))
+	assert.Nil(t, parseWarnings([]byte(`[]`)))
+}
@@ -0,0 +1,46 @@
+// Command fakeserveradmin serves an in-memory Serveradmin dataset API for
+// integration tests and docker-compose setups:
+//
+//	fakeserveradmin -listen :8000 -fixtures testdata/fixtures.json
+//
+// Point clients at it with SERVERADMIN_BASE_URL=http://localhost:8000 and any
+// SERVERADMIN_TOKEN; requests are not authenticated.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/innogames/serveradmin-go-client/adminapi/adminapitest"
+)
+
+func main() {
+	listen := flag.String("listen", ":8000", "Address to listen on")
+	fixtures := flag.String("fixtures", "", "JSON file with attributes, servertypes and objects to seed")
+	flag.Parse()
+
+	server := adminapitest.NewServer()
+	if *fixtures != "" {
+		file, err := os.Open(*fixtures)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = server.LoadFixtures(file)
+		file.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("serving fake Serveradmin with %d objects on %s", len(server.Objects()), *listen)
+	log.Fatal(http.ListenAndServe(*listen, logRequests(server)))
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s", r.Method, r.URL)
+		next.ServeHTTP(w, r)
+	})
+}
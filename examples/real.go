@@ -31,8 +31,9 @@ func main() {
 	checkErr(err)
 	q.AddAttributes("dns_txt")
 
-	publicURL, err := q.One(ctx)
-	if err != nil {
+	publicURL, err := q.OneOrNil(ctx)
+	checkErr(err)
+	if publicURL == nil {
 		// Object doesn't exist, create it
 		log.Println("=== Object not found, creating new public_domain object ===")
 		publicURL, err = client.NewObject(ctx, "public_domain", api.Attributes{
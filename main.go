@@ -2,22 +2,35 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/innogames/serveradmin-go-client/adminapi"
+	_ "github.com/innogames/serveradmin-go-client/adminapi/kerberos" // SERVERADMIN_AUTH=kerberos
 )
 
 // adminapi CLI entry point
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "snapshot":
+			os.Exit(runSnapshot(os.Args[2:]))
+		case "diff":
+			os.Exit(runDiff(os.Args[2:]))
+		}
+	}
+
 	var attributes string
 	var orderBy string
 	var onlyOne bool
+	var explain bool
 	flag.StringVar(&attributes, "a", "hostname", "Attributes to fetch")
-	flag.StringVar(&orderBy, "order", "", "Attributes to order by the result")
+	flag.StringVar(&orderBy, "order", "", "Comma-separated attributes to order the result by, \"-\" prefixed for descending")
 	flag.BoolVar(&onlyOne, "one", false, "Make sure exactly one server matches with the query")
+	flag.BoolVar(&explain, "explain", false, "Print how the query is understood instead of running it")
 
 	flag.Parse()
 
@@ -35,13 +48,33 @@ func main() {
 
 	q, err := client.FromQuery(query)
 	if err != nil {
+		var parseErr *adminapi.ParseError
+		if errors.As(err, &parseErr) {
+			fmt.Println(parseErr.Caret())
+			err = parseErr
+		}
 		fmt.Println("Error parsing query:", err)
 		os.Exit(1)
 	}
 
 	attributeList := strings.Split(attributes, ",")
 	q.SetAttributes(attributeList...)
-	q.OrderBy(orderBy)
+	for i, key := range strings.Split(orderBy, ",") {
+		direction := adminapi.Asc
+		if attribute, ok := strings.CutPrefix(key, "-"); ok {
+			key, direction = attribute, adminapi.Desc
+		}
+		if i == 0 {
+			q.OrderBy(key, direction)
+		} else {
+			q.ThenBy(key, direction)
+		}
+	}
+
+	if explain {
+		fmt.Print(q.Explain())
+		return
+	}
 
 	servers, err := q.All(context.Background())
 	if err != nil {
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/innogames/serveradmin-go-client/adminapi"
+)
+
+// runSnapshot implements "serveradmin snapshot [-a attributes] [-o file] <query>",
+// writing a JSON snapshot of all matching objects.
+func runSnapshot(args []string) int {
+	flags := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	attributes := flags.String("a", "hostname", "Attributes to capture")
+	output := flags.String("o", "", "Write the snapshot to this file instead of stdout")
+	_ = flags.Parse(args)
+
+	query := flags.Arg(0)
+	if query == "" {
+		fmt.Println("usage: snapshot [-a attributes] [-o file] <query>")
+		flags.PrintDefaults()
+		return 1
+	}
+
+	client, err := adminapi.NewClientFromEnv()
+	if err != nil {
+		fmt.Println("Error configuring client:", err)
+		return 1
+	}
+
+	q, err := client.FromQuery(query)
+	if err != nil {
+		fmt.Println("Error parsing query:", err)
+		return 1
+	}
+	q.SetAttributes(strings.Split(*attributes, ",")...)
+
+	snapshot, err := q.Snapshot(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	snapshot.Query = query
+
+	out := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		out = file
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(snapshot)
+	if file, ok := out.(*os.File); ok && file != os.Stdout {
+		// a failed close can mean the snapshot was not fully written
+		err = errors.Join(err, file.Close())
+	}
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
+
+// runDiff implements "serveradmin diff [-json] <snapA> <snapB>", printing the
+// changes between two snapshot files.
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "Print the change set as JSON")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		fmt.Println("usage: diff [-json] <snapA> <snapB>")
+		flags.PrintDefaults()
+		return 1
+	}
+
+	older, err := readSnapshot(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	newer, err := readSnapshot(flags.Arg(1))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	changes := adminapi.DiffSnapshots(older, newer)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(changes); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	printChangeSet(os.Stdout, changes)
+	return 0
+}
+
+func readSnapshot(path string) (*adminapi.Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &adminapi.Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// printChangeSet writes a human-readable diff: "+" for created, "-" for
+// deleted and "~" for changed objects, followed by their changed attributes.
+func printChangeSet(w io.Writer, changes *adminapi.ChangeSet) {
+	if changes.Empty() {
+		fmt.Fprintln(w, "no changes")
+		return
+	}
+
+	for _, obj := range changes.Created {
+		fmt.Fprintf(w, "+ %v (object_id %v)\n", obj["hostname"], obj["object_id"])
+	}
+	for _, obj := range changes.Deleted {
+		fmt.Fprintf(w, "- %v (object_id %v)\n", obj["hostname"], obj["object_id"])
+	}
+	for _, obj := range changes.Changed {
+		fmt.Fprintf(w, "~ %s (object_id %d)\n", obj.Hostname, obj.ObjectID)
+		keys := make([]string, 0, len(obj.Attributes))
+		for key := range obj.Attributes {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			change := obj.Attributes[key]
+			fmt.Fprintf(w, "    %s: %s -> %s\n", key, formatValue(change.Old), formatValue(change.New))
+		}
+	}
+}
+
+func formatValue(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}